@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"nookverse/internal/config"
+	"nookverse/internal/database"
+	"nookverse/internal/services"
+	"nookverse/internal/storage"
+)
+
+// 在存储后端之间迁移媒体文件，例如：go run ./cmd/migrate-media -from local -to s3
+func main() {
+	from := flag.String("from", storage.BackendLocal, "source storage backend (local, s3)")
+	to := flag.String("to", storage.BackendS3, "target storage backend (local, s3)")
+	flag.Parse()
+
+	if *from == *to {
+		log.Fatalf("Source and target storage backends must differ")
+	}
+
+	// 加载配置
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	source, err := storage.NewBackend(*from, cfg.Upload)
+	if err != nil {
+		log.Fatalf("Failed to initialize source storage: %v", err)
+	}
+	target, err := storage.NewBackend(*to, cfg.Upload)
+	if err != nil {
+		log.Fatalf("Failed to initialize target storage: %v", err)
+	}
+
+	// 连接数据库
+	db, err := database.NewConnection(database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.Name,
+		SSLMode:  "disable",
+		TimeZone: "Asia/Shanghai",
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	result, err := services.MigrateMedia(context.Background(), db, source, target)
+	if err != nil {
+		log.Fatalf("Failed to migrate media: %v", err)
+	}
+
+	log.Printf("Media migrated from %s to %s: %d records, %d objects copied, %d skipped, %d missing",
+		*from, *to, result.Records, result.Copied, result.Skipped, result.Missing)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"nookverse/internal/config"
+	"nookverse/internal/database"
+	"nookverse/internal/services"
+)
+
+// 根据物品当前内容重建全文检索索引（search_index），用于 AutoMigrate 建表的数据库补建索引或调整索引字段后更新已有物品
+func main() {
+	// 加载配置
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 连接数据库
+	db, err := database.NewConnection(database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.Name,
+		SSLMode:  "disable",
+		TimeZone: "Asia/Shanghai",
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	rows, err := services.RebuildSearchIndex(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Failed to rebuild search index: %v", err)
+	}
+
+	log.Printf("Search index rebuilt: %d rows written", rows)
+}
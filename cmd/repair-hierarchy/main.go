@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"nookverse/internal/config"
+	"nookverse/internal/database"
+	"nookverse/internal/services"
+)
+
+// 根据 items.container_id 重建物品层级闭包表（item_hierarchy）
+func main() {
+	// 加载配置
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 连接数据库
+	db, err := database.NewConnection(database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.Name,
+		SSLMode:  "disable",
+		TimeZone: "Asia/Shanghai",
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	rows, err := services.RebuildItemHierarchy(context.Background(), db)
+	if err != nil {
+		log.Fatalf("Failed to rebuild item hierarchy: %v", err)
+	}
+
+	log.Printf("Item hierarchy rebuilt: %d rows written", rows)
+}
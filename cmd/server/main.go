@@ -10,10 +10,13 @@ import (
 	"syscall"
 	"time"
 
+	"nookverse/internal/auth"
+	"nookverse/internal/cache"
 	"nookverse/internal/config"
 	"nookverse/internal/database"
 	"nookverse/internal/routers"
 	"nookverse/internal/services"
+	"nookverse/internal/storage"
 )
 
 func main() {
@@ -50,11 +53,57 @@ func main() {
 	}
 
 	// 初始化服务
-	itemService := services.NewItemService(db)
-	houseService := services.NewHouseService(db)
+	tokenManager := auth.NewTokenManager(cfg.JWT.Secret, time.Duration(cfg.JWT.Expire)*time.Hour)
+	userService := services.NewUserService(db, tokenManager, time.Duration(cfg.JWT.RefreshExpire)*time.Hour)
+	notifiers, err := services.NewNotifierRegistry(db, cfg.Notify)
+	if err != nil {
+		log.Fatalf("Failed to initialize notifiers: %v", err)
+	}
+	// 房屋、房间和统计信息的读缓存，Redis 未连接或不可用时直接查询数据库
+	readCache := services.NewReadCache(db, cache.New(redisClient, time.Duration(cfg.Redis.CacheTTL)*time.Second))
+	itemService := services.NewCachedItemService(services.NewItemService(db, notifiers), readCache)
+	houseService := services.NewCachedHouseService(services.NewHouseService(db), readCache)
+	permissionService := services.NewPermissionService(db)
+	familyService := services.NewFamilyService(db)
+	categoryService := services.NewCategoryService(db)
+	notificationService := services.NewNotificationService(db, notifiers)
+	policyService := services.NewReminderPolicyService(db, notifiers)
+	reminderService := services.NewReminderService(db, notifiers)
+	calendarService := services.NewCalendarService(db)
+	operationLogService := services.NewOperationLogService(db)
+	mediaStorage, err := storage.New(cfg.Upload)
+	if err != nil {
+		log.Fatalf("Failed to initialize media storage: %v", err)
+	}
+	mediaService := services.NewMediaService(db, cfg.Upload, mediaStorage)
+	trashService := services.NewTrashService(db, mediaStorage, time.Duration(cfg.Trash.RetentionDays)*24*time.Hour)
+
+	// 启动后台任务，服务关闭时停止
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	mediaService.StartThumbnailWorker(workerCtx)
+
+	reminderScheduler := services.NewReminderScheduler(db, notifiers)
+	reminderScheduler.Start(workerCtx)
+	trashService.StartPurger(workerCtx)
 
 	// 初始化路由
-	router := routers.SetupRoutes(itemService, houseService)
+	router := routers.SetupRoutes(routers.Services{
+		Item:           itemService,
+		House:          houseService,
+		User:           userService,
+		Permission:     permissionService,
+		Family:         familyService,
+		Category:       categoryService,
+		Media:          mediaService,
+		Notification:   notificationService,
+		ReminderPolicy: policyService,
+		Reminder:       reminderService,
+		Calendar:       calendarService,
+		OperationLog:   operationLogService,
+		Trash:          trashService,
+		Cache:          readCache,
+	})
 
 	// 创建HTTP服务器
 	server := &http.Server{
@@ -79,6 +128,8 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopWorkers()
+	reminderScheduler.Wait()
 
 	// 关闭Redis连接
 	if redisClient != nil {
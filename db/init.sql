@@ -15,6 +15,7 @@ CREATE TABLE IF NOT EXISTS houses (
     floor_count INTEGER DEFAULT 1, -- 楼层数
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP, -- 移入回收站的时间，为空表示未删除
     metadata JSONB DEFAULT '{}'
 );
 
@@ -29,7 +30,8 @@ CREATE TABLE IF NOT EXISTS rooms (
     description TEXT,
     position_data JSONB DEFAULT '{}', -- 3D坐标和边界信息
     created_at TIMESTAMP DEFAULT NOW(),
-    updated_at TIMESTAMP DEFAULT NOW()
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP -- 移入回收站的时间，为空表示未删除
 );
 
 -- 3. 物品类别表
@@ -41,6 +43,7 @@ CREATE TABLE IF NOT EXISTS categories (
     color VARCHAR(20) DEFAULT '#666666',
     sort_order INTEGER DEFAULT 0, -- 排序
     is_system BOOLEAN DEFAULT FALSE, -- 是否系统分类
+    created_by UUID, -- 创建者，系统分类为空
     created_at TIMESTAMP DEFAULT NOW()
 );
 
@@ -72,7 +75,8 @@ CREATE TABLE IF NOT EXISTS items (
     labels TEXT[], -- 标签数组
     
     created_at TIMESTAMP DEFAULT NOW(),
-    updated_at TIMESTAMP DEFAULT NOW()
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP -- 移入回收站的时间，为空表示未删除
 );
 
 -- 5. 媒体文件表
@@ -80,7 +84,9 @@ CREATE TABLE IF NOT EXISTS media_files (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     item_id UUID REFERENCES items(id) ON DELETE CASCADE,
     file_url TEXT NOT NULL,
+    storage_key VARCHAR(255), -- 按内容哈希生成的存储键
     thumbnail_url TEXT,
+    thumbnail_key VARCHAR(255), -- 缩略图存储键
     file_type VARCHAR(20) NOT NULL, -- image, video, document
     file_size BIGINT, -- 文件大小（字节）
     mime_type VARCHAR(100),
@@ -96,12 +102,29 @@ CREATE TABLE IF NOT EXISTS reminders (
     reminder_type VARCHAR(20) NOT NULL, -- expire, maintenance, warranty, custom
     trigger_time TIMESTAMP NOT NULL,
     message TEXT NOT NULL,
-    status VARCHAR(20) DEFAULT 'pending', -- pending, sent, completed, cancelled
+    status VARCHAR(20) DEFAULT 'pending', -- pending, sent, completed, cancelled, failed
     notify_channels TEXT[], -- notification channels: app, email, sms, voice
+    attempts INTEGER DEFAULT 0, -- 发送失败次数
+    next_attempt_at TIMESTAMP, -- 下次尝试发送时间（重试退避或领取租约）
+    last_error TEXT,
+    sent_at TIMESTAMP,
+    policy_id UUID, -- 由家庭提醒策略自动生成时对应的策略
+    recurrence VARCHAR(200), -- 重复规则（RRULE），如 FREQ=MONTHLY;INTERVAL=3
+    recurrence_start TIMESTAMP, -- 重复规则的首次发生时间（DTSTART）
+    occurrence_at TIMESTAMP, -- 重复提醒当前这一次的计划时间
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
 
+-- 提醒发送记录表
+CREATE TABLE IF NOT EXISTS reminder_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reminder_id UUID NOT NULL REFERENCES reminders(id) ON DELETE CASCADE,
+    channel VARCHAR(20) NOT NULL,
+    sent_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (reminder_id, channel)
+);
+
 -- 7. 用户表
 CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -117,6 +140,52 @@ CREATE TABLE IF NOT EXISTS users (
     updated_at TIMESTAMP DEFAULT NOW()
 );
 
+-- 7.1 刷新令牌表
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256(令牌)
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    replaced_by UUID, -- 轮换后的新令牌
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 7.2 站内通知表（app渠道收件箱）
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reminder_id UUID REFERENCES reminders(id) ON DELETE CASCADE,
+    item_id UUID REFERENCES items(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    message TEXT NOT NULL,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 7.3 提醒完成记录表（物品保养历史）
+CREATE TABLE IF NOT EXISTS reminder_completions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    reminder_id UUID REFERENCES reminders(id) ON DELETE SET NULL,
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    reminder_type VARCHAR(20) NOT NULL,
+    message TEXT,
+    occurrence_at TIMESTAMP NOT NULL, -- 完成的是哪一次计划时间
+    completed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    note TEXT
+);
+
+-- 7.4 日历订阅表（只保存订阅令牌哈希）
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID UNIQUE NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL, -- SHA-256(令牌)
+    last_accessed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
 -- 8. 家庭表
 CREATE TABLE IF NOT EXISTS families (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -124,10 +193,32 @@ CREATE TABLE IF NOT EXISTS families (
     description TEXT,
     owner_id UUID REFERENCES users(id) ON DELETE CASCADE,
     invite_code VARCHAR(20) UNIQUE,
+    invite_role VARCHAR(20) DEFAULT 'member', -- 通过邀请码加入时的默认角色
+    invite_expires_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW()
 );
 
+-- 8.1 家庭房屋关联表（数据隔离以家庭为单位）
+CREATE TABLE IF NOT EXISTS family_houses (
+    family_id UUID REFERENCES families(id) ON DELETE CASCADE,
+    house_id UUID REFERENCES houses(id) ON DELETE CASCADE,
+    
+    PRIMARY KEY (family_id, house_id)
+);
+
+-- 8.2 家庭提醒策略表（按物品日期自动生成提醒）
+CREATE TABLE IF NOT EXISTS reminder_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    family_id UUID NOT NULL REFERENCES families(id) ON DELETE CASCADE,
+    reminder_type VARCHAR(20) NOT NULL, -- expire, warranty
+    days_before INTEGER NOT NULL, -- 提前天数
+    notify_channels TEXT[],
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (family_id, reminder_type, days_before)
+);
+
 -- 9. 家庭成员表
 CREATE TABLE IF NOT EXISTS family_members (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -135,7 +226,7 @@ CREATE TABLE IF NOT EXISTS family_members (
     user_id UUID REFERENCES users(id) ON DELETE CASCADE,
     role VARCHAR(20) DEFAULT 'member', -- owner, admin, member, viewer
     joined_at TIMESTAMP DEFAULT NOW(),
-    status INTEGER DEFAULT 1, -- 1:正常 2:禁用
+    status INTEGER DEFAULT 1, -- 1:正常 2:禁用 3:待审核
     
     UNIQUE(family_id, user_id)
 );
@@ -157,7 +248,12 @@ CREATE TABLE IF NOT EXISTS operation_logs (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id UUID REFERENCES users(id) ON DELETE SET NULL,
     item_id UUID REFERENCES items(id) ON DELETE SET NULL,
-    operation_type VARCHAR(50) NOT NULL, -- create, update, delete, move, borrow
+    entity_type VARCHAR(20), -- item, room, house, category, reminder
+    entity_id UUID, -- 不设外键，被删除对象的日志仍然保留
+    house_id UUID, -- 操作发生时所在的房屋，用于家庭动态
+    family_id UUID, -- 操作发生时房屋所属的家庭，房屋删除后仍按此归属
+    owner_id UUID, -- 操作发生时物品为私有时的所有者，物品删除后仍只对其可见
+    operation_type VARCHAR(50) NOT NULL, -- create, update, delete, move, complete, snooze, cancel
     description TEXT,
     old_value JSONB,
     new_value JSONB,
@@ -176,6 +272,19 @@ CREATE TABLE IF NOT EXISTS item_hierarchy (
     PRIMARY KEY (ancestor_id, descendant_id)
 );
 
+-- 12.1 物品版本表（每次修改后的完整快照，用于查看历史状态和恢复）
+CREATE TABLE IF NOT EXISTS item_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id UUID NOT NULL REFERENCES items(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL, -- 同一物品内从1递增
+    operation VARCHAR(20) NOT NULL, -- create, update, move, restore
+    snapshot JSONB NOT NULL, -- 物品各列的值（键为列名）
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+
+    UNIQUE (item_id, version)
+);
+
 -- 13. 搜索索引表（用于全文搜索）
 CREATE TABLE IF NOT EXISTS search_index (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -186,8 +295,10 @@ CREATE TABLE IF NOT EXISTS search_index (
 );
 
 -- 创建索引
+CREATE INDEX IF NOT EXISTS idx_houses_deleted_at ON houses(deleted_at);
 CREATE INDEX IF NOT EXISTS idx_rooms_house ON rooms(house_id);
 CREATE INDEX IF NOT EXISTS idx_rooms_type ON rooms(room_type);
+CREATE INDEX IF NOT EXISTS idx_rooms_deleted_at ON rooms(deleted_at);
 CREATE INDEX IF NOT EXISTS idx_categories_parent ON categories(parent_id);
 CREATE INDEX IF NOT EXISTS idx_items_room ON items(room_id);
 CREATE INDEX IF NOT EXISTS idx_items_container ON items(container_id);
@@ -195,15 +306,25 @@ CREATE INDEX IF NOT EXISTS idx_items_category ON items(category_id);
 CREATE INDEX IF NOT EXISTS idx_items_expire ON items(expire_date);
 CREATE INDEX IF NOT EXISTS idx_items_status ON items(status);
 CREATE INDEX IF NOT EXISTS idx_items_labels ON items USING GIN(labels);
+CREATE INDEX IF NOT EXISTS idx_items_deleted_at ON items(deleted_at);
 CREATE INDEX IF NOT EXISTS idx_media_item ON media_files(item_id);
 CREATE INDEX IF NOT EXISTS idx_media_type ON media_files(file_type);
+CREATE INDEX IF NOT EXISTS idx_media_storage_key ON media_files(storage_key);
 CREATE INDEX IF NOT EXISTS idx_reminders_item ON reminders(item_id);
 CREATE INDEX IF NOT EXISTS idx_reminders_type ON reminders(reminder_type);
 CREATE INDEX IF NOT EXISTS idx_reminders_status ON reminders(status);
 CREATE INDEX IF NOT EXISTS idx_reminders_trigger ON reminders(trigger_time);
+CREATE INDEX IF NOT EXISTS idx_reminders_policy ON reminders(policy_id);
+CREATE INDEX IF NOT EXISTS idx_reminder_completions_item ON reminder_completions(item_id, completed_at);
+CREATE INDEX IF NOT EXISTS idx_reminder_completions_reminder ON reminder_completions(reminder_id);
+CREATE INDEX IF NOT EXISTS idx_reminders_due ON reminders(status, trigger_time) WHERE status = 'pending';
 CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
 CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
 CREATE INDEX IF NOT EXISTS idx_families_owner ON families(owner_id);
+CREATE INDEX IF NOT EXISTS idx_family_houses_house ON family_houses(house_id);
 CREATE INDEX IF NOT EXISTS idx_family_members_family ON family_members(family_id);
 CREATE INDEX IF NOT EXISTS idx_family_members_user ON family_members(user_id);
 CREATE INDEX IF NOT EXISTS idx_item_permissions_item ON item_permissions(item_id);
@@ -212,6 +333,10 @@ CREATE INDEX IF NOT EXISTS idx_logs_user ON operation_logs(user_id);
 CREATE INDEX IF NOT EXISTS idx_logs_item ON operation_logs(item_id);
 CREATE INDEX IF NOT EXISTS idx_logs_operation ON operation_logs(operation_type);
 CREATE INDEX IF NOT EXISTS idx_logs_created ON operation_logs(created_at);
+CREATE INDEX IF NOT EXISTS idx_logs_entity ON operation_logs(entity_type, entity_id);
+CREATE INDEX IF NOT EXISTS idx_logs_house ON operation_logs(house_id);
+CREATE INDEX IF NOT EXISTS idx_logs_family ON operation_logs(family_id);
+CREATE INDEX IF NOT EXISTS idx_item_versions_created ON item_versions(created_at);
 CREATE INDEX IF NOT EXISTS idx_hierarchy_ancestor ON item_hierarchy(ancestor_id);
 CREATE INDEX IF NOT EXISTS idx_hierarchy_descendant ON item_hierarchy(descendant_id);
 CREATE INDEX IF NOT EXISTS idx_search_item ON search_index(item_id);
@@ -265,8 +390,11 @@ BEGIN
     VALUES (
         NEW.id,
         setweight(to_tsvector('simple', COALESCE(NEW.name, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.brand, '') || ' ' || COALESCE(NEW.model, '')), 'B') ||
         setweight(to_tsvector('simple', COALESCE(NEW.description, '')), 'B') ||
-        setweight(to_tsvector('simple', array_to_string(COALESCE(NEW.labels, '{}'), ' ')), 'C')
+        setweight(to_tsvector('simple', array_to_string(COALESCE(NEW.labels, '{}'), ' ')), 'C') ||
+        setweight(to_tsvector('simple', COALESCE(NEW.custom_position, '')), 'C') ||
+        setweight(jsonb_to_tsvector('simple', COALESCE(NEW.attributes, '{}'), '["string", "numeric"]'), 'D')
     );
     RETURN NEW;
 END;
@@ -317,6 +445,14 @@ SELECT '默认家庭', '系统默认家庭', id FROM users WHERE username = 'adm
 INSERT INTO houses (name, address, description) VALUES
 ('示例住宅', '北京市朝阳区示例街道123号', '这是一个示例住宅');
 
+-- 示例房屋归属默认家庭，管理员为家庭所有者
+INSERT INTO family_houses (family_id, house_id)
+SELECT f.id, h.id FROM families f CROSS JOIN houses h
+WHERE f.name = '默认家庭' AND h.name = '示例住宅';
+
+INSERT INTO family_members (family_id, user_id, role)
+SELECT f.id, f.owner_id, 'owner' FROM families f WHERE f.name = '默认家庭';
+
 -- 示例房间
 INSERT INTO rooms (house_id, name, room_type, floor_number, description) 
 SELECT h.id, r.name, r.room_type, r.floor_number, r.description
@@ -348,6 +484,10 @@ SELECT '牛奶', '纯牛奶',
        (SELECT r.id FROM rooms r JOIN houses h ON r.house_id = h.id WHERE h.name = '示例住宅' AND r.name = '厨房'),
        2, 12.50, '2024-03-01', '伊利';
 
+-- 示例物品的闭包记录（每个物品指向自身）
+INSERT INTO item_hierarchy (ancestor_id, descendant_id, depth)
+SELECT id, id, 0 FROM items;
+
 -- 示例提醒
 INSERT INTO reminders (item_id, reminder_type, trigger_time, message, notify_channels)
 SELECT i.id, 'expire', '2024-02-28 09:00:00', '牛奶即将过期，请及时处理', ARRAY['app', 'email']
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 令牌相关错误
+var (
+	ErrInvalidToken = errors.New("无效的令牌")
+	ErrExpiredToken = errors.New("令牌已过期")
+)
+
+// Claims 访问令牌载荷
+type Claims struct {
+	UserID   string `json:"uid"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// TokenManager 访问令牌签发与校验
+type TokenManager struct {
+	secret []byte
+	expire time.Duration
+	issuer string
+}
+
+// NewTokenManager 创建令牌管理器
+func NewTokenManager(secret string, expire time.Duration) *TokenManager {
+	return &TokenManager{
+		secret: []byte(secret),
+		expire: expire,
+		issuer: "nookverse",
+	}
+}
+
+// Expire 访问令牌有效期
+func (m *TokenManager) Expire() time.Duration {
+	return m.expire
+}
+
+// GenerateAccessToken 签发访问令牌，返回令牌和过期时间
+func (m *TokenManager) GenerateAccessToken(userID, username string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(m.expire)
+
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.issuer,
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// ParseAccessToken 校验并解析访问令牌
+func (m *TokenManager) ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (any, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(m.issuer),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if !token.Valid || claims.UserID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
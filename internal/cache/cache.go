@@ -0,0 +1,286 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	keyPrefix  = "nookverse:cache:"
+	epochTag   = "*" // 全局标签，失效操作未能写入 Redis 时递增，丢弃之前的全部缓存
+	opTimeout  = 100 * time.Millisecond
+	downPeriod = 30 * time.Second // Redis 出错后暂停使用缓存的时长
+	lockTTL    = 5 * time.Second  // 重建缓存的锁的有效期，持锁实例异常退出时到期释放
+	lockPoll   = 25 * time.Millisecond
+	lockPolls  = 20
+	minTagTTL  = 7 * 24 * time.Hour
+)
+
+// Cache 基于 Redis 的读缓存。缓存键包含其依赖的标签（如家庭）的版本号，标签数据发生写入时
+// 递增版本号，旧版本的缓存不再被读取并随过期时间淘汰。
+//
+// 同一缓存键未命中时，进程内只有一个请求读取数据源，其余请求等待其结果；多个实例之间通过
+// Redis 锁协调，未取得锁的实例短暂等待缓存写入。client 为空、ttl 不大于0或 Redis 不可用时
+// 直接读取数据源。
+type Cache struct {
+	client *redis.Client
+	ttl    time.Duration
+
+	mu       sync.Mutex
+	inflight map[string]*call
+
+	downUntil atomic.Int64 // Redis 出错后暂停使用缓存直到该时间（UnixNano）
+	stale     atomic.Bool  // 有失效操作未能写入 Redis，恢复后需递增全局标签
+}
+
+// call 进程内正在进行的缓存重建，等待者共享其结果
+type call struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// New 创建读缓存，client 为空或 ttl 不大于0时不使用缓存
+func New(client *redis.Client, ttl time.Duration) *Cache {
+	if ttl <= 0 {
+		client = nil
+	}
+	return &Cache{client: client, ttl: ttl, inflight: make(map[string]*call)}
+}
+
+// Enabled 是否在使用缓存：已连接 Redis 且未处于出错后的暂停期
+func (c *Cache) Enabled() bool {
+	return c != nil && c.available()
+}
+
+// Fetch 读取缓存，未命中时调用 load 并写入缓存。key 区分同一组标签下的不同查询，tags 为结果
+// 依赖的数据，其中任一标签失效后重新读取。load 的结果需能以JSON往返，返回错误时不缓存。
+func Fetch[T any](ctx context.Context, c *Cache, key string, tags []string, load func() (T, error)) (T, error) {
+	if !c.Enabled() {
+		return load()
+	}
+
+	fullKey, err := c.versionedKey(ctx, key, tags)
+	if err != nil {
+		c.fail(ctx, err)
+		return load()
+	}
+
+	var value T
+	getCtx, cancel := context.WithTimeout(ctx, opTimeout)
+	data, err := c.client.Get(getCtx, fullKey).Bytes()
+	cancel()
+	if err == nil && json.Unmarshal(data, &value) == nil {
+		return value, nil
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.fail(ctx, err)
+		return load()
+	}
+
+	// 未命中：进程内合并同一键的重建，等待者各自解码一份结果，不与其他请求共享同一对象
+	data, shared, err := c.do(ctx, fullKey, func() ([]byte, error) {
+		var data []byte
+		value, data, err = fill(ctx, c, fullKey, load)
+		return data, err
+	})
+	if !shared {
+		return value, err
+	}
+	if err != nil {
+		if errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			// 发起重建的请求被取消，当前请求自行读取
+			return load()
+		}
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return load()
+	}
+	return value, nil
+}
+
+// Invalidate 递增标签的版本号，使依赖这些标签的缓存失效。Redis 不可用时记下待失效，
+// 恢复后丢弃全部缓存
+func (c *Cache) Invalidate(ctx context.Context, tags ...string) {
+	if c == nil || c.client == nil || len(tags) == 0 {
+		return
+	}
+	if !c.available() {
+		c.stale.Store(true)
+		return
+	}
+
+	opCtx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	tagTTL := max(minTagTTL, 2*c.ttl)
+	_, err := c.client.TxPipelined(opCtx, func(pipe redis.Pipeliner) error {
+		for _, tag := range tags {
+			pipe.Incr(opCtx, tagKey(tag))
+			pipe.Expire(opCtx, tagKey(tag), tagTTL)
+		}
+		return nil
+	})
+	if err != nil {
+		c.stale.Store(true)
+		c.fail(ctx, err)
+	}
+}
+
+// fill 重建缓存：取得 Redis 锁的实例读取数据源并写入缓存，其余实例等待缓存写入，
+// 等待超时后自行读取
+func fill[T any](ctx context.Context, c *Cache, fullKey string, load func() (T, error)) (T, []byte, error) {
+	lockKey := fullKey + ":lock"
+	lockCtx, cancel := context.WithTimeout(ctx, opTimeout)
+	locked, err := c.client.SetNX(lockCtx, lockKey, 1, lockTTL).Result()
+	cancel()
+	if err != nil {
+		c.fail(ctx, err)
+		return encode(load())
+	}
+
+	if !locked {
+		for i := 0; i < lockPolls; i++ {
+			select {
+			case <-ctx.Done():
+				var zero T
+				return zero, nil, ctx.Err()
+			case <-time.After(lockPoll):
+			}
+
+			getCtx, cancel := context.WithTimeout(ctx, opTimeout)
+			data, err := c.client.Get(getCtx, fullKey).Bytes()
+			cancel()
+			if err == nil {
+				var value T
+				if json.Unmarshal(data, &value) == nil {
+					return value, data, nil
+				}
+				break
+			}
+			if !errors.Is(err, redis.Nil) {
+				c.fail(ctx, err)
+				break
+			}
+		}
+		return encode(load())
+	}
+
+	defer c.client.Del(context.WithoutCancel(ctx), lockKey)
+
+	value, data, err := encode(load())
+	if err != nil {
+		return value, nil, err
+	}
+
+	// 过期时间加入随机偏移，避免同时写入的缓存同时过期
+	ttl := c.ttl + rand.N(c.ttl/10+1)
+	setCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opTimeout)
+	defer cancel()
+	if err := c.client.Set(setCtx, fullKey, data, ttl).Err(); err != nil {
+		c.fail(ctx, err)
+	}
+	return value, data, nil
+}
+
+// encode 编码数据源的结果供等待者解码
+func encode[T any](value T, err error) (T, []byte, error) {
+	if err != nil {
+		return value, nil, err
+	}
+	data, err := json.Marshal(value)
+	return value, data, err
+}
+
+// do 进程内合并同一键的重建：第一个调用者执行 fn，其余调用者等待并共享其编码后的结果
+func (c *Cache) do(ctx context.Context, key string, fn func() ([]byte, error)) (data []byte, shared bool, err error) {
+	c.mu.Lock()
+	if cl, ok := c.inflight[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-cl.done:
+			return cl.data, true, cl.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	cl := &call{done: make(chan struct{})}
+	c.inflight[key] = cl
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.inflight, key)
+		c.mu.Unlock()
+		close(cl.done)
+	}()
+
+	cl.data, cl.err = fn()
+	return cl.data, false, cl.err
+}
+
+// versionedKey 读取标签的版本号，生成包含版本号的缓存键
+func (c *Cache) versionedKey(ctx context.Context, key string, tags []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	if c.stale.Load() {
+		if err := c.client.Incr(ctx, tagKey(epochTag)).Err(); err != nil {
+			return "", err
+		}
+		c.stale.Store(false)
+	}
+
+	tags = append([]string{epochTag}, tags...)
+	sort.Strings(tags[1:])
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = tagKey(tag)
+	}
+	versions, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, tag := range tags {
+		version, _ := versions[i].(string)
+		b.WriteString(tag + "=" + version + ";")
+	}
+	sum := sha1.Sum([]byte(b.String()))
+	return keyPrefix + "data:" + key + ":" + hex.EncodeToString(sum[:8]), nil
+}
+
+// available Redis 可用且未处于出错后的暂停期
+func (c *Cache) available() bool {
+	return c.client != nil && time.Now().UnixNano() >= c.downUntil.Load()
+}
+
+// fail 记录 Redis 错误并暂停使用缓存，请求本身被取消导致的错误不计入
+func (c *Cache) fail(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	until := time.Now().Add(downPeriod).UnixNano()
+	if old := c.downUntil.Swap(until); old < time.Now().UnixNano() {
+		log.Printf("Warning: redis cache unavailable, reading from database for %s: %v", downPeriod, err)
+	}
+}
+
+// tagKey 标签版本号的键
+func tagKey(tag string) string {
+	return keyPrefix + "tag:" + tag
+}
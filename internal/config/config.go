@@ -13,6 +13,8 @@ type Config struct {
 	JWT      JWTConfig      `json:"jwt"`
 	Redis    RedisConfig    `json:"redis"`
 	Upload   UploadConfig   `json:"upload"`
+	Notify   NotifyConfig   `json:"notify"`
+	Trash    TrashConfig    `json:"trash"`
 }
 
 // ServerConfig 服务器配置
@@ -31,8 +33,9 @@ type DatabaseConfig struct {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret string `json:"secret"`
-	Expire int    `json:"expire"` // 过期时间（小时）
+	Secret        string `json:"secret"`
+	Expire        int    `json:"expire"`         // 访问令牌过期时间（小时）
+	RefreshExpire int    `json:"refresh_expire"` // 刷新令牌过期时间（小时）
 }
 
 // RedisConfig Redis配置
@@ -41,6 +44,7 @@ type RedisConfig struct {
 	Port     int    `json:"port"`
 	Password string `json:"password"`
 	DB       int    `json:"db"`
+	CacheTTL int    `json:"cache_ttl"` // 读缓存有效期（秒），0 时不使用读缓存
 }
 
 // UploadConfig 上传配置
@@ -48,6 +52,46 @@ type UploadConfig struct {
 	Path         string   `json:"path"`
 	MaxSize      int64    `json:"max_size"`
 	AllowedTypes []string `json:"allowed_types"`
+	Storage      string   `json:"storage"` // 存储后端：local, s3
+	S3           S3Config `json:"s3"`
+}
+
+// S3Config S3兼容对象存储配置
+type S3Config struct {
+	Endpoint      string `json:"endpoint"` // 如 https://s3.amazonaws.com 或 http://localhost:9000
+	Region        string `json:"region"`
+	Bucket        string `json:"bucket"`
+	AccessKey     string `json:"access_key"`
+	SecretKey     string `json:"secret_key"`
+	PathStyle     bool   `json:"path_style"`     // 使用路径风格地址（MinIO等自建服务）
+	PresignExpire int    `json:"presign_expire"` // 预签名下载地址有效期（分钟）
+}
+
+// NotifyConfig 提醒通知渠道配置
+type NotifyConfig struct {
+	SMTP     SMTPConfig               `json:"smtp"`     // 配置 host 后启用 email 渠道
+	Webhooks map[string]WebhookConfig `json:"webhooks"` // 渠道名到webhook的映射，如 webhook、sms、voice（对接短信/语音网关）
+}
+
+// SMTPConfig 邮件发送配置
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	From     string `json:"from"`
+}
+
+// WebhookConfig webhook通知配置
+type WebhookConfig struct {
+	URL     string `json:"url"`
+	Secret  string `json:"secret"`  // 请求体HMAC-SHA256签名密钥
+	Timeout int    `json:"timeout"` // 请求超时时间（秒）
+}
+
+// TrashConfig 回收站配置
+type TrashConfig struct {
+	RetentionDays int `json:"retention_days"` // 删除的对象在回收站中保留的天数，到期后彻底删除
 }
 
 // LoadConfig 加载配置
@@ -65,14 +109,16 @@ func LoadConfig() (*Config, error) {
 			Name:     "nookverse",
 		},
 		JWT: JWTConfig{
-			Secret: "your-jwt-secret-key-here-change-in-production",
-			Expire: 24,
+			Secret:        "your-jwt-secret-key-here-change-in-production",
+			Expire:        24,
+			RefreshExpire: 720, // 30天
 		},
 		Redis: RedisConfig{
 			Host:     "localhost",
 			Port:     6379,
 			Password: "",
 			DB:       0,
+			CacheTTL: 300,
 		},
 		Upload: UploadConfig{
 			Path:    "./uploads",
@@ -83,6 +129,19 @@ func LoadConfig() (*Config, error) {
 				"image/gif",
 				"video/mp4",
 			},
+			Storage: "local",
+			S3: S3Config{
+				Region:        "us-east-1",
+				PresignExpire: 15,
+			},
+		},
+		Notify: NotifyConfig{
+			SMTP: SMTPConfig{
+				Port: 587,
+			},
+		},
+		Trash: TrashConfig{
+			RetentionDays: 30,
 		},
 	}
 
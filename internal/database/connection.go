@@ -95,12 +95,20 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Item{},
 		&models.MediaFile{},
 		&models.Reminder{},
+		&models.ReminderDelivery{},
+		&models.ReminderPolicy{},
+		&models.ReminderCompletion{},
 		&models.User{},
+		&models.RefreshToken{},
+		&models.Notification{},
+		&models.CalendarFeed{},
 		&models.Family{},
 		&models.FamilyMember{},
 		&models.ItemPermission{},
 		&models.OperationLog{},
 		&models.ItemHierarchy{},
+		&models.ItemVersion{},
+		&models.SearchIndex{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %w", err)
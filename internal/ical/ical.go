@@ -0,0 +1,149 @@
+// Package ical 生成 iCalendar（RFC 5545）日历，用于日历应用订阅提醒
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets 内容行折行前的最大字节数（不含CRLF）
+const maxLineOctets = 75
+
+const (
+	utcLayout  = "20060102T150405Z"
+	dateLayout = "20060102"
+)
+
+// Calendar 日历
+type Calendar struct {
+	ProdID      string
+	Name        string        // X-WR-CALNAME，订阅时显示的日历名称
+	RefreshHint time.Duration // 建议的刷新间隔，0表示不指定
+	Events      []Event
+}
+
+// Event 日历事件
+type Event struct {
+	UID          string // 同一事件在多次生成的日历中保持不变，日历应用据此更新而不是重复添加
+	Summary      string
+	Description  string
+	Categories   []string
+	Start        time.Time
+	AllDay       bool          // 全天事件只使用 Start 的日期
+	Duration     time.Duration // 非全天事件的持续时间，0表示不指定
+	RRule        string        // 重复规则，不含 "RRULE:" 前缀
+	LastModified time.Time
+	Alarm        bool // 在事件开始时提醒
+}
+
+// Write 以CRLF换行写出日历
+func (c *Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeLine(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", escapeText(c.ProdID))
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		line("X-WR-CALNAME", escapeText(c.Name))
+	}
+	if c.RefreshHint > 0 {
+		duration := formatDuration(c.RefreshHint)
+		line("REFRESH-INTERVAL;VALUE=DURATION", duration)
+		line("X-PUBLISHED-TTL", duration)
+	}
+
+	for _, event := range c.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", escapeText(event.UID))
+		line("DTSTAMP", event.LastModified.UTC().Format(utcLayout))
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE", event.Start.Format(dateLayout))
+			line("DTEND;VALUE=DATE", event.Start.AddDate(0, 0, 1).Format(dateLayout))
+			line("TRANSP", "TRANSPARENT")
+		} else {
+			line("DTSTART", event.Start.UTC().Format(utcLayout))
+			if event.Duration > 0 {
+				line("DURATION", formatDuration(event.Duration))
+			}
+		}
+		if event.RRule != "" {
+			line("RRULE", event.RRule)
+		}
+		line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION", escapeText(event.Description))
+		}
+		if len(event.Categories) > 0 {
+			categories := make([]string, 0, len(event.Categories))
+			for _, category := range event.Categories {
+				categories = append(categories, escapeText(category))
+			}
+			line("CATEGORIES", strings.Join(categories, ","))
+		}
+		line("LAST-MODIFIED", event.LastModified.UTC().Format(utcLayout))
+		if event.Alarm {
+			line("BEGIN", "VALARM")
+			line("ACTION", "DISPLAY")
+			line("DESCRIPTION", escapeText(event.Summary))
+			line("TRIGGER", "PT0S")
+			line("END", "VALARM")
+		}
+		line("END", "VEVENT")
+	}
+
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escapeText 转义TEXT值中的反斜杠、分号、逗号和换行
+func escapeText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+	return replacer.Replace(value)
+}
+
+// writeLine 写出内容行，超过75字节时折行，续行以空格开头；不会在多字节字符中间断开
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // 续行开头的空格占一个字节
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+// formatDuration 格式化为 DURATION 值，如 PT15M、P1D
+func formatDuration(d time.Duration) string {
+	if d%(24*time.Hour) == 0 {
+		return "P" + strconv.Itoa(int(d/(24*time.Hour))) + "D"
+	}
+	var b strings.Builder
+	b.WriteString("PT")
+	if h := d / time.Hour; h > 0 {
+		b.WriteString(strconv.Itoa(int(h)) + "H")
+		d -= h * time.Hour
+	}
+	if m := d / time.Minute; m > 0 {
+		b.WriteString(strconv.Itoa(int(m)) + "M")
+		d -= m * time.Minute
+	}
+	if s := d / time.Second; s > 0 || b.Len() == 2 {
+		b.WriteString(strconv.Itoa(int(s)) + "S")
+	}
+	return b.String()
+}
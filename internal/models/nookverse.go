@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // House 房屋模型
@@ -15,6 +17,7 @@ type House struct {
 	Metadata    map[string]any `json:"metadata" gorm:"type:jsonb"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // 移入回收站的时间
 
 	Rooms []Room `json:"rooms" gorm:"foreignKey:HouseID"`
 }
@@ -31,6 +34,7 @@ type Room struct {
 	PositionData map[string]any `json:"position_data" gorm:"type:jsonb"` // 3D坐标和边界信息
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // 移入回收站的时间
 
 	Items []Item `json:"items" gorm:"foreignKey:RoomID"`
 }
@@ -44,6 +48,7 @@ type Category struct {
 	Color    string    `json:"color" gorm:"size:20;default:'#666666'"`
 	SortOrder int       `json:"sort_order" gorm:"default:0"`
 	IsSystem bool      `json:"is_system" gorm:"default:false"`
+	CreatedBy *string  `json:"created_by" gorm:"type:uuid;index"` // 创建者，系统分类为空
 	CreatedAt time.Time `json:"created_at"`
 
 	Children  []Category `json:"children" gorm:"foreignKey:ParentID"`
@@ -79,14 +84,15 @@ type Item struct {
 
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"` // 移入回收站的时间
 
 	// 关联关系
 	Category       *Category      `json:"category" gorm:"foreignKey:CategoryID"`
 	Room           *Room          `json:"room" gorm:"foreignKey:RoomID"`
 	Container      *Item          `json:"container" gorm:"foreignKey:ContainerID"`
-	ContainedItems []Item         `json:"contained_items" gorm:"foreignKey:ContainerID"`
-	MediaFiles     []MediaFile    `json:"media_files" gorm:"foreignKey:ItemID"`
-	Reminders      []Reminder     `json:"reminders" gorm:"foreignKey:ItemID"`
+	ContainedItems []Item         `json:"contained_items" gorm:"foreignKey:ContainerID;constraint:OnDelete:SET NULL"`
+	MediaFiles     []MediaFile    `json:"media_files" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	Reminders      []Reminder     `json:"reminders" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
 }
 
 // MediaFile 媒体文件模型
@@ -94,7 +100,9 @@ type MediaFile struct {
 	ID          string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	ItemID      string    `json:"item_id" gorm:"type:uuid;not null;index"`
 	FileURL     string    `json:"file_url" gorm:"type:text;not null"`
+	StorageKey  string    `json:"-" gorm:"size:255;index"`          // 按内容哈希生成的存储键
 	ThumbnailURL string   `json:"thumbnail_url" gorm:"type:text"`
+	ThumbnailKey string   `json:"-" gorm:"size:255"`                 // 缩略图存储键
 	FileType    string    `json:"file_type" gorm:"size:20;not null"` // image, video, document
 	FileSize    *int64    `json:"file_size"`                          // 文件大小（字节）
 	MimeType    *string   `json:"mime_type" gorm:"size:100"`
@@ -112,14 +120,60 @@ type Reminder struct {
 	ReminderType   string    `json:"reminder_type" gorm:"size:20;not null"` // expire, maintenance, warranty, custom
 	TriggerTime    time.Time `json:"trigger_time" gorm:"not null;index"`
 	Message        string    `json:"message" gorm:"type:text;not null"`
-	Status         string    `json:"status" gorm:"size:20;default:'pending';index"` // pending, sent, completed, cancelled
+	Status         string    `json:"status" gorm:"size:20;default:'pending';index"` // pending, sent, completed, cancelled, failed
 	NotifyChannels []string  `json:"notify_channels" gorm:"type:text[]"`            // app, email, sms, voice
+	Attempts       int        `json:"attempts" gorm:"default:0"`                   // 发送失败次数
+	NextAttemptAt  *time.Time `json:"next_attempt_at" gorm:"index"`                // 下次尝试发送时间（重试退避或领取租约）
+	LastError      *string    `json:"last_error" gorm:"type:text"`
+	SentAt         *time.Time `json:"sent_at"`
+	PolicyID       *string    `json:"policy_id" gorm:"type:uuid;index"` // 由家庭提醒策略自动生成时对应的策略
+	Recurrence      *string    `json:"recurrence" gorm:"size:200"` // 重复规则（RRULE），如 FREQ=MONTHLY;INTERVAL=3
+	RecurrenceStart *time.Time `json:"recurrence_start"`           // 重复规则的首次发生时间（DTSTART）
+	OccurrenceAt    *time.Time `json:"occurrence_at"`              // 重复提醒当前这一次的计划时间，稍后提醒不改变该时间
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 
 	Item *Item `json:"item" gorm:"foreignKey:ItemID"`
 }
 
+// ReminderCompletion 提醒完成记录，重复提醒每完成一次记录一条，构成物品的保养历史
+type ReminderCompletion struct {
+	ID           string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ReminderID   *string   `json:"reminder_id" gorm:"type:uuid;index"`
+	ItemID       string    `json:"item_id" gorm:"type:uuid;not null;index"`
+	UserID       string    `json:"user_id" gorm:"type:uuid;not null"`
+	ReminderType string    `json:"reminder_type" gorm:"size:20;not null"`
+	Message      string    `json:"message" gorm:"type:text"`
+	OccurrenceAt time.Time `json:"occurrence_at" gorm:"not null"` // 完成的是哪一次计划时间
+	CompletedAt  time.Time `json:"completed_at" gorm:"not null"`
+	Note         *string   `json:"note" gorm:"type:text"`
+
+	Reminder *Reminder `json:"-" gorm:"foreignKey:ReminderID;constraint:OnDelete:SET NULL"`
+	Item     *Item     `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	User     *User     `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// ReminderPolicy 家庭提醒策略，按物品的过期日期或保修到期日自动生成提前若干天的提醒
+type ReminderPolicy struct {
+	ID             string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	FamilyID       string    `json:"family_id" gorm:"type:uuid;not null;uniqueIndex:idx_reminder_policies_rule"`
+	ReminderType   string    `json:"reminder_type" gorm:"size:20;not null;uniqueIndex:idx_reminder_policies_rule"` // expire, warranty
+	DaysBefore     int       `json:"days_before" gorm:"not null;uniqueIndex:idx_reminder_policies_rule"`
+	NotifyChannels []string  `json:"notify_channels" gorm:"type:text[]"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ReminderDelivery 提醒在各通知渠道的发送记录，重试时跳过已发送成功的渠道
+type ReminderDelivery struct {
+	ID         string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ReminderID string    `json:"reminder_id" gorm:"type:uuid;not null;uniqueIndex:idx_reminder_deliveries_channel"`
+	Channel    string    `json:"channel" gorm:"size:20;not null;uniqueIndex:idx_reminder_deliveries_channel"`
+	SentAt     time.Time `json:"sent_at"`
+
+	Reminder *Reminder `json:"-" gorm:"foreignKey:ReminderID;constraint:OnDelete:CASCADE"`
+}
+
 // User 用户模型
 type User struct {
 	ID           string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -138,29 +192,45 @@ type User struct {
 	Items    []ItemPermission `json:"items" gorm:"foreignKey:UserID"`
 }
 
+// RefreshToken 刷新令牌模型（只保存令牌哈希）
+type RefreshToken struct {
+	ID         string     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	TokenHash  string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *string    `json:"replaced_by" gorm:"type:uuid"` // 轮换后的新令牌ID
+	UserAgent  *string    `json:"user_agent" gorm:"type:text"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User *User `json:"user" gorm:"foreignKey:UserID"`
+}
+
 // Family 家庭模型
 type Family struct {
-	ID          string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	Name        string    `json:"name" gorm:"size:100;not null"`
-	Description *string   `json:"description" gorm:"type:text"`
-	OwnerID     string    `json:"owner_id" gorm:"type:uuid;not null;index"`
-	InviteCode  *string   `json:"invite_code" gorm:"size:20;uniqueIndex"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              string     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Name            string     `json:"name" gorm:"size:100;not null"`
+	Description     *string    `json:"description" gorm:"type:text"`
+	OwnerID         string     `json:"owner_id" gorm:"type:uuid;not null;index"`
+	InviteCode      *string    `json:"invite_code" gorm:"size:20;uniqueIndex"`
+	InviteRole      string     `json:"invite_role" gorm:"size:20;default:'member'"` // 通过邀请码加入时的默认角色
+	InviteExpiresAt *time.Time `json:"invite_expires_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 
 	Owner    User           `json:"owner" gorm:"foreignKey:OwnerID"`
 	Members  []FamilyMember `json:"members" gorm:"foreignKey:FamilyID"`
-	Houses   []House        `json:"houses" gorm:"many2many:family_houses"`
+	Houses   []House        `json:"houses" gorm:"many2many:family_houses;constraint:OnDelete:CASCADE"`
 }
 
 // FamilyMember 家庭成员模型
 type FamilyMember struct {
 	ID        string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	FamilyID  string    `json:"family_id" gorm:"type:uuid;not null;index"`
-	UserID    string    `json:"user_id" gorm:"type:uuid;not null;index"`
+	FamilyID  string    `json:"family_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_family_members_family_user"`
+	UserID    string    `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_family_members_family_user"`
 	Role      string    `json:"role" gorm:"size:20;default:'member'"` // owner, admin, member, viewer
 	JoinedAt  time.Time `json:"joined_at"`
-	Status    int       `json:"status" gorm:"default:1"` // 1:正常 2:禁用
+	Status    int       `json:"status" gorm:"default:1"` // 1:正常 2:禁用 3:待审核
 
 	Family Family `json:"family" gorm:"foreignKey:FamilyID"`
 	User   User   `json:"user" gorm:"foreignKey:UserID"`
@@ -169,14 +239,38 @@ type FamilyMember struct {
 // ItemPermission 物品权限模型
 type ItemPermission struct {
 	ID             string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	ItemID         string    `json:"item_id" gorm:"type:uuid;not null;index"`
-	UserID         string    `json:"user_id" gorm:"type:uuid;not null;index"`
+	ItemID         string    `json:"item_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_item_permissions_item_user"`
+	UserID         string    `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_item_permissions_item_user"`
 	PermissionLevel string   `json:"permission_level" gorm:"size:20;default:'view'"` // owner, edit, view
 	GrantedBy      *string   `json:"granted_by" gorm:"type:uuid"`
 	CreatedAt      time.Time `json:"created_at"`
 
-	Item User `json:"item" gorm:"foreignKey:ItemID"`
-	User User `json:"user" gorm:"foreignKey:UserID"`
+	Item *Item `json:"item" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	User *User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// Notification 站内通知，app渠道的提醒投递到成员收件箱
+type Notification struct {
+	ID         string     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID     string     `json:"user_id" gorm:"type:uuid;not null;index"`
+	ReminderID *string    `json:"reminder_id" gorm:"type:uuid;index"`
+	ItemID     *string    `json:"item_id" gorm:"type:uuid"`
+	Title      string     `json:"title" gorm:"size:200;not null"`
+	Message    string     `json:"message" gorm:"type:text;not null"`
+	ReadAt     *time.Time `json:"read_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	Reminder *Reminder `json:"-" gorm:"foreignKey:ReminderID;constraint:OnDelete:CASCADE"`
+	Item     *Item     `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+}
+
+// CalendarFeed 用户的日历订阅，日历应用无法携带认证头，以URL中的订阅令牌认证；只保存令牌哈希
+type CalendarFeed struct {
+	ID             string     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	UserID         string     `json:"user_id" gorm:"type:uuid;uniqueIndex;not null"`
+	TokenHash      string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 // OperationLog 操作日志模型
@@ -184,6 +278,11 @@ type OperationLog struct {
 	ID          string         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
 	UserID      *string        `json:"user_id" gorm:"type:uuid;index"`
 	ItemID      *string        `json:"item_id" gorm:"type:uuid;index"`
+	EntityType  string         `json:"entity_type" gorm:"size:20;index:idx_logs_entity"` // item, room, house, category, reminder
+	EntityID    *string        `json:"entity_id" gorm:"type:uuid;index:idx_logs_entity"`
+	HouseID     *string        `json:"house_id" gorm:"type:uuid;index"` // 操作发生时所在的房屋，用于家庭动态
+	FamilyID    *string        `json:"family_id" gorm:"type:uuid;index"` // 操作发生时房屋所属的家庭，房屋删除后仍按此归属
+	OwnerID     *string        `json:"-" gorm:"type:uuid"`               // 操作发生时物品为私有时的所有者，物品删除后仍只对其可见
 	OperationType string       `json:"operation_type" gorm:"size:50;not null;index"`
 	Description *string        `json:"description" gorm:"type:text"`
 	OldValue    map[string]any `json:"old_value" gorm:"type:jsonb"`
@@ -193,7 +292,21 @@ type OperationLog struct {
 	CreatedAt   time.Time      `json:"created_at" gorm:"index"`
 
 	User *User `json:"user" gorm:"foreignKey:UserID"`
-	Item *Item `json:"item" gorm:"foreignKey:ItemID"`
+	Item *Item `json:"item" gorm:"foreignKey:ItemID;constraint:OnDelete:SET NULL"`
+}
+
+// ItemVersion 物品版本快照，物品每次创建、修改、移动或恢复后记录一份完整数据
+type ItemVersion struct {
+	ID        string         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ItemID    string         `json:"item_id" gorm:"type:uuid;not null;uniqueIndex:idx_item_versions_item_version"`
+	Version   int            `json:"version" gorm:"not null;uniqueIndex:idx_item_versions_item_version"` // 同一物品内从1递增
+	Operation string         `json:"operation" gorm:"size:20;not null"`                                 // baseline（首次修改前补记）, create, update, move, restore
+	Snapshot  map[string]any `json:"snapshot" gorm:"type:jsonb;not null"`                               // 物品各列的值（键为列名），含 attributes、labels、position
+	UserID    *string        `json:"user_id" gorm:"type:uuid"`
+	CreatedAt time.Time      `json:"created_at" gorm:"index"`
+
+	Item *Item `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
+	User *User `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL"`
 }
 
 // ItemHierarchy 物品层级关系模型（闭包表）
@@ -203,8 +316,19 @@ type ItemHierarchy struct {
 	Depth       int       `json:"depth" gorm:"not null"`
 	CreatedAt   time.Time `json:"created_at"`
 
-	Ancestor  Item `json:"ancestor" gorm:"foreignKey:AncestorID"`
-	Descendant Item `json:"descendant" gorm:"foreignKey:DescendantID"`
+	Ancestor  Item `json:"ancestor" gorm:"foreignKey:AncestorID;constraint:OnDelete:CASCADE"`
+	Descendant Item `json:"descendant" gorm:"foreignKey:DescendantID;constraint:OnDelete:CASCADE"`
+}
+
+// SearchIndex 物品全文检索索引，每个物品一条，内容为名称、品牌、型号、描述等字段加权后的 tsvector
+type SearchIndex struct {
+	ID                string    `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	ItemID            string    `json:"item_id" gorm:"type:uuid;index:idx_search_item"`
+	SearchableContent string    `json:"-" gorm:"type:tsvector;index:idx_search_content,type:gin"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+
+	Item *Item `json:"-" gorm:"foreignKey:ItemID;constraint:OnDelete:CASCADE"`
 }
 
 // TableName 指定表名
@@ -214,9 +338,16 @@ func (Category) TableName() string { return "categories" }
 func (Item) TableName() string { return "items" }
 func (MediaFile) TableName() string { return "media_files" }
 func (Reminder) TableName() string { return "reminders" }
+func (ReminderDelivery) TableName() string { return "reminder_deliveries" }
+func (ReminderPolicy) TableName() string { return "reminder_policies" }
+func (ReminderCompletion) TableName() string { return "reminder_completions" }
 func (User) TableName() string { return "users" }
+func (RefreshToken) TableName() string { return "refresh_tokens" }
+func (Notification) TableName() string { return "notifications" }
+func (CalendarFeed) TableName() string { return "calendar_feeds" }
 func (Family) TableName() string { return "families" }
 func (FamilyMember) TableName() string { return "family_members" }
 func (ItemPermission) TableName() string { return "item_permissions" }
 func (OperationLog) TableName() string { return "operation_logs" }
-func (ItemHierarchy) TableName() string { return "item_hierarchy" }
\ No newline at end of file
+func (ItemHierarchy) TableName() string { return "item_hierarchy" }
+func (SearchIndex) TableName() string { return "search_index" }
\ No newline at end of file
@@ -0,0 +1,307 @@
+// Package recurrence 实现 iCalendar RRULE（RFC 5545）的常用子集，用于周期性提醒
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency 重复频率
+type Frequency string
+
+// 支持的重复频率
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods 查找下一次发生时间时最多检查的周期数，避免永远不会发生的规则（如2月31日）陷入死循环
+const maxPeriods = 10000
+
+// 解析错误
+var (
+	ErrInvalidRule = errors.New("重复规则无效")
+)
+
+// weekdayCodes 星期代码，下标为 time.Weekday
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule 重复规则，支持 FREQ、INTERVAL、COUNT、UNTIL、BYDAY（不含序数）、BYMONTHDAY 和 BYMONTH
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int        // 总发生次数，0表示不限
+	Until      *time.Time // 最后一次发生时间的上限（含）
+	ByDay      []time.Weekday
+	ByMonthDay []int // 负数表示从月末倒数，-1为最后一天
+	ByMonth    []time.Month
+}
+
+// Parse 解析RRULE文本，如 "FREQ=MONTHLY;INTERVAL=3"，可带 "RRULE:" 前缀
+func Parse(text string) (*Rule, error) {
+	text = strings.TrimPrefix(strings.TrimSpace(text), "RRULE:")
+	if text == "" {
+		return nil, fmt.Errorf("%w: 规则为空", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(text, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		key = strings.ToUpper(strings.TrimSpace(key))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s 重复", ErrInvalidRule, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("不支持的频率 %s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parseInt(value, 1, 1000)
+		case "COUNT":
+			rule.Count, err = parseInt(value, 1, 1000)
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value)
+			rule.Until = &until
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday := slices.Index(weekdayCodes[:], code)
+				if weekday < 0 {
+					err = fmt.Errorf("BYDAY 不支持 %s", code)
+					break
+				}
+				rule.ByDay = append(rule.ByDay, time.Weekday(weekday))
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				var n int
+				if n, err = parseInt(day, -31, 31); err != nil {
+					break
+				}
+				if n == 0 {
+					err = errors.New("BYMONTHDAY 不能为0")
+					break
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "BYMONTH":
+			for _, month := range strings.Split(value, ",") {
+				var n int
+				if n, err = parseInt(month, 1, 12); err != nil {
+					break
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(n))
+			}
+		case "WKST":
+			if value != "MO" {
+				err = errors.New("WKST 仅支持 MO")
+			}
+		default:
+			err = fmt.Errorf("不支持的规则项 %s", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: 缺少 FREQ", ErrInvalidRule)
+	}
+	if rule.Count > 0 && rule.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT 和 UNTIL 不能同时使用", ErrInvalidRule)
+	}
+	if rule.Freq == Yearly && len(rule.ByDay) > 0 && len(rule.ByMonth) == 0 {
+		return nil, fmt.Errorf("%w: YEARLY 使用 BYDAY 时需指定 BYMONTH", ErrInvalidRule)
+	}
+
+	return rule, nil
+}
+
+// String 规范化的RRULE文本（不含 "RRULE:" 前缀）
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			codes = append(codes, weekdayCodes[weekday])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, 0, len(r.ByMonth))
+		for _, month := range r.ByMonth {
+			months = append(months, int(month))
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	return strings.Join(parts, ";")
+}
+
+// After 以 start 为首次发生时间（DTSTART），返回严格晚于 after 的下一次发生时间；规则已结束时返回false
+func (r *Rule) After(start, after time.Time) (time.Time, bool) {
+	count := 0
+	for period := 0; period < maxPeriods; period++ {
+		for _, occurrence := range r.candidates(start, period*r.Interval) {
+			if occurrence.Before(start) {
+				continue
+			}
+			if r.Until != nil && occurrence.After(*r.Until) {
+				return time.Time{}, false
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return time.Time{}, false
+			}
+			if occurrence.After(after) {
+				return occurrence, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// candidates 第 offset 个频率单位内按时间排序的候选时间，时刻与 start 相同
+func (r *Rule) candidates(start time.Time, offset int) []time.Time {
+	hour, minute, second := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, start.Location())
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		day := start.AddDate(0, 0, offset)
+		days = []time.Time{at(day.Year(), day.Month(), day.Day())}
+	case Weekly:
+		// 以周一为一周的开始
+		monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+offset*7)
+		weekdays := r.ByDay
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{start.Weekday()}
+		}
+		for _, weekday := range weekdays {
+			day := monday.AddDate(0, 0, (int(weekday)+6)%7)
+			days = append(days, at(day.Year(), day.Month(), day.Day()))
+		}
+	case Monthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		days = r.monthDays(start, month.Year(), month.Month(), at)
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{start.Month()}
+		}
+		for _, month := range months {
+			days = append(days, r.monthDays(start, start.Year()+offset, month, at)...)
+		}
+	}
+
+	filtered := days[:0]
+	for _, day := range days {
+		if r.matches(day) {
+			filtered = append(filtered, day)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Before(filtered[j]) })
+	return slices.CompactFunc(filtered, time.Time.Equal)
+}
+
+// monthDays 某月内的候选日期：BYMONTHDAY、BYDAY 指定的日期，均未指定时为 start 的日；不存在的日期（如2月30日）跳过
+func (r *Rule) monthDays(start time.Time, year int, month time.Month, at func(int, time.Month, int) time.Time) []time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	var days []time.Time
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, day := range r.ByMonthDay {
+			if day < 0 {
+				day = last + day + 1
+			}
+			if day >= 1 && day <= last {
+				days = append(days, at(year, month, day))
+			}
+		}
+	case len(r.ByDay) > 0:
+		for day := 1; day <= last; day++ {
+			days = append(days, at(year, month, day))
+		}
+	default:
+		if start.Day() <= last {
+			days = append(days, at(year, month, start.Day()))
+		}
+	}
+	return days
+}
+
+// matches 按 BYMONTH、BYDAY 过滤候选日期，DAILY 和 WEEKLY 还按 BYMONTHDAY 过滤
+func (r *Rule) matches(day time.Time) bool {
+	if len(r.ByMonth) > 0 && !slices.Contains(r.ByMonth, day.Month()) {
+		return false
+	}
+	if len(r.ByDay) > 0 && r.Freq != Weekly && !slices.Contains(r.ByDay, day.Weekday()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && (r.Freq == Daily || r.Freq == Weekly) {
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		return slices.Contains(r.ByMonthDay, day.Day()) || slices.Contains(r.ByMonthDay, day.Day()-last-1)
+	}
+	return true
+}
+
+func parseInt(value string, min, max int) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s 超出范围 %d..%d", value, min, max)
+	}
+	return n, nil
+}
+
+// parseUntil 解析 UNTIL，支持 UTC 时间（20260101T000000Z）和日期（20260101，当天结束前均有效）
+func parseUntil(value string) (time.Time, error) {
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("UNTIL 格式错误 %s", value)
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, ",")
+}
@@ -1,15 +1,48 @@
 package routers
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"nookverse/internal/auth"
 	"nookverse/internal/services"
 	"nookverse/pkg/api/v1/handlers"
 )
 
+// publicRoutes 无需认证即可访问的路由白名单
+var publicRoutes = map[string]bool{
+	"/health":                 true,
+	"/api/v1/users/register":  true,
+	"/api/v1/users/login":     true,
+	"/api/v1/users/refresh":   true,
+	"/api/v1/users/logout":    true,
+	"/api/v1/calendar/:token": true, // 以订阅令牌认证
+}
+
+// Services 路由依赖的服务，测试中不涉及的服务可以留空
+type Services struct {
+	Item           services.ItemService
+	House          services.HouseService
+	User           services.UserService
+	Permission     services.PermissionService
+	Family         services.FamilyService
+	Category       services.CategoryService
+	Media          services.MediaService
+	Notification   services.NotificationService
+	ReminderPolicy services.ReminderPolicyService
+	Reminder       services.ReminderService
+	Calendar       services.CalendarService
+	OperationLog   services.OperationLogService
+	Trash          services.TrashService
+	Cache          *services.ReadCache // 为空时不使用读缓存
+}
+
 // SetupRoutes 设置路由
-func SetupRoutes(itemService services.ItemService, houseService services.HouseService) *gin.Engine {
+func SetupRoutes(svc Services) *gin.Engine {
 	// 创建gin引擎
 	r := gin.Default()
 
@@ -21,11 +54,94 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 		})
 	})
 
-	// API v1 路由组
+	// API v1 路由组（除白名单外均需认证）
 	v1 := r.Group("/api/v1")
+	v1.Use(AuthMiddleware(svc.User), ReadCacheMiddleware(svc.Cache))
 	{
+		// 用户认证路由
+		userHandler := handlers.NewUserHandler(svc.User)
+		users := v1.Group("/users")
+		{
+			users.POST("/register", userHandler.Register)
+			users.POST("/login", userHandler.Login)
+			users.POST("/refresh", userHandler.RefreshToken)
+			users.POST("/logout", userHandler.Logout)
+		}
+
+		// 当前用户信息
+		calendarHandler := handlers.NewCalendarHandler(svc.Calendar)
+		profile := v1.Group("/profile")
+		{
+			profile.GET("", userHandler.GetProfile)
+			profile.GET("/calendar", calendarHandler.GetFeed)
+			profile.POST("/calendar", calendarHandler.ResetFeed)
+			profile.DELETE("/calendar", calendarHandler.RevokeFeed)
+		}
+
+		// 日历订阅（iCalendar），供手机日历等客户端订阅
+		v1.GET("/calendar/:token", calendarHandler.RenderFeed)
+
+		// 站内通知路由
+		notificationHandler := handlers.NewNotificationHandler(svc.Notification)
+		notifications := v1.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.GET("/channels", notificationHandler.ListChannels)
+			notifications.POST("/read-all", notificationHandler.MarkAllNotificationsRead)
+			notifications.POST("/:notificationId/read", notificationHandler.MarkNotificationRead)
+		}
+
+		// 操作日志（物品历史和家庭动态）
+		operationLogHandler := handlers.NewOperationLogHandler(svc.OperationLog, svc.Permission)
+
+		// 家庭管理路由
+		familyHandler := handlers.NewFamilyHandler(svc.Family, svc.Permission)
+		families := v1.Group("/families")
+		{
+			families.POST("", familyHandler.CreateFamily)
+			families.GET("", familyHandler.ListFamilies)
+			families.POST("/join", familyHandler.JoinFamily)
+
+			// 单个家庭操作
+			families.GET("/:familyId", familyHandler.GetFamily)
+			families.POST("/:familyId/invite-code", familyHandler.RotateInviteCode)
+			families.POST("/:familyId/transfer", familyHandler.TransferOwnership)
+
+			// 成员管理
+			families.GET("/:familyId/members", familyHandler.ListMembers)
+			families.POST("/:familyId/members/:userId/approve", familyHandler.ApproveMember)
+			families.POST("/:familyId/members/:userId/reject", familyHandler.RejectMember)
+			families.DELETE("/:familyId/members/:userId", familyHandler.RemoveMember)
+
+			// 自动提醒策略
+			policyHandler := handlers.NewReminderPolicyHandler(svc.ReminderPolicy, svc.Permission)
+			families.GET("/:familyId/reminder-policies", policyHandler.ListPolicies)
+			families.GET("/:familyId/activity", operationLogHandler.ListFamilyActivity)
+			families.PUT("/:familyId/reminder-policies", policyHandler.ReplacePolicies)
+		}
+
+		// 分类管理路由
+		itemHandler := handlers.NewItemHandler(svc.Item, svc.Permission)
+		categoryHandler := handlers.NewCategoryHandler(svc.Category, svc.Permission)
+		categories := v1.Group("/categories")
+		{
+			categories.POST("", categoryHandler.CreateCategory)
+			categories.GET("", categoryHandler.ListCategories)
+			categories.GET("/tree", categoryHandler.GetCategoryTree)
+			categories.PUT("/order", categoryHandler.ReorderCategories)
+
+			// 单个分类操作
+			categories.GET("/:categoryId", categoryHandler.GetCategory)
+			categories.PUT("/:categoryId", categoryHandler.UpdateCategory)
+			categories.DELETE("/:categoryId", categoryHandler.DeleteCategory)
+			categories.POST("/:categoryId/merge", categoryHandler.MergeCategory)
+			categories.GET("/:categoryId/items", itemHandler.GetItemsByCategory)
+		}
+
 		// 物品管理路由
-		itemHandler := handlers.NewItemHandler(itemService)
+		permissionHandler := handlers.NewPermissionHandler(svc.Permission)
+		mediaHandler := handlers.NewMediaHandler(svc.Media, svc.Permission)
+		reminderHandler := handlers.NewReminderHandler(svc.Reminder, svc.Permission)
 		items := v1.Group("/items")
 		{
 			items.POST("", itemHandler.CreateItem)
@@ -33,7 +149,32 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 			items.GET("/search", itemHandler.SearchItems)
 			// 物品层级管理
 			items.POST("/:itemId/move", itemHandler.MoveItem)
+			items.GET("/:itemId/tree", itemHandler.GetItemTree)
 			items.POST("/:itemId/reminders", itemHandler.CreateReminder)
+			items.GET("/:itemId/reminders/completions", reminderHandler.ListCompletions)
+			items.GET("/:itemId/history", operationLogHandler.ListItemHistory)
+
+			// 物品版本历史
+			items.GET("/:itemId/versions", itemHandler.ListItemVersions)
+			items.GET("/:itemId/versions/:version", itemHandler.GetItemVersion)
+			items.GET("/:itemId/versions/:version/diff", itemHandler.DiffItemVersions)
+			items.POST("/:itemId/versions/:version/restore", itemHandler.RestoreItemVersion)
+			items.GET("/:itemId/as-of", itemHandler.GetItemAsOf)
+
+			// 物品授权管理
+			items.POST("/:itemId/permissions", permissionHandler.GrantItemPermission)
+			items.GET("/:itemId/permissions", permissionHandler.ListItemPermissions)
+			items.DELETE("/:itemId/permissions/:userId", permissionHandler.RevokeItemPermission)
+			items.GET("/:itemId/permissions/:userId/effective", permissionHandler.GetEffectivePermission)
+
+			// 物品媒体文件
+			items.POST("/:itemId/media", mediaHandler.UploadMedia)
+			items.GET("/:itemId/media", mediaHandler.ListMedia)
+			items.PUT("/:itemId/media/order", mediaHandler.ReorderMedia)
+			items.GET("/:itemId/media/:mediaId", mediaHandler.GetMediaContent)
+			items.DELETE("/:itemId/media/:mediaId", mediaHandler.DeleteMedia)
+			items.GET("/:itemId/media/:mediaId/thumbnail", mediaHandler.GetThumbnail)
+			items.POST("/:itemId/media/:mediaId/thumbnail", mediaHandler.RegenerateThumbnail)
 			
 			// 单个物品操作
 			items.GET("/:itemId", itemHandler.GetItem)
@@ -50,6 +191,18 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 			items.GET("/statistics", itemHandler.GetItemStatistics)
 		}
 
+		// 提醒管理路由
+		reminders := v1.Group("/reminders")
+		{
+			reminders.GET("", reminderHandler.ListReminders)
+			reminders.GET("/overdue", reminderHandler.ListOverdueReminders)
+			reminders.GET("/:reminderId", reminderHandler.GetReminder)
+			reminders.PUT("/:reminderId", reminderHandler.UpdateReminder)
+			reminders.POST("/:reminderId/cancel", reminderHandler.CancelReminder)
+			reminders.POST("/:reminderId/complete", reminderHandler.CompleteReminder)
+			reminders.POST("/:reminderId/snooze", reminderHandler.SnoozeReminder)
+		}
+
 		// 房间相关路由
 		rooms := v1.Group("/rooms")
 		{
@@ -57,7 +210,7 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 		}
 
 		// 房屋管理路由
-		houseHandler := handlers.NewHouseHandler(houseService)
+		houseHandler := handlers.NewHouseHandler(svc.House, svc.Permission)
 		houses := v1.Group("/houses")
 		{
 			houses.POST("", houseHandler.CreateHouse)
@@ -77,6 +230,16 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 			houses.GET("/statistics", houseHandler.GetHouseStatistics)
 		}
 
+		// 回收站：删除的物品、房间和房屋在保留期内可以恢复
+		trashHandler := handlers.NewTrashHandler(svc.Trash)
+		trash := v1.Group("/trash")
+		{
+			trash.GET("", trashHandler.ListTrash)
+			trash.POST("/items/:itemId/restore", trashHandler.RestoreItem)
+			trash.POST("/rooms/:roomId/restore", trashHandler.RestoreRoom)
+			trash.POST("/houses/:houseId/restore", trashHandler.RestoreHouse)
+		}
+
 		// 房间独立操作路由
 		independentRooms := v1.Group("/rooms")
 		{
@@ -84,40 +247,22 @@ func SetupRoutes(itemService services.ItemService, houseService services.HouseSe
 			independentRooms.PUT("/:roomId", houseHandler.UpdateRoom)
 			independentRooms.DELETE("/:roomId", houseHandler.DeleteRoom)
 		}
-
-		// TODO: 用户管理路由（待实现）
-		// userHandler := handlers.NewUserHandler()
-		// users := v1.Group("/users")
-		// {
-		// 	users.POST("/register", userHandler.Register)
-		// 	users.POST("/login", userHandler.Login)
-		// 	users.GET("/:id", userHandler.GetUserByID)
-		// 	users.PUT("/:id", userHandler.UpdateUser)
-		// 	users.DELETE("/:id", userHandler.DeleteUser)
-		// }
-
-		// TODO: 需要认证的路由组（待实现）
-		// auth := v1.Group("/")
-		// auth.Use(AuthMiddleware()) // 添加认证中间件
-		// {
-		// 	profile := auth.Group("/profile")
-		// 	{
-		// 		profile.GET("", userHandler.GetProfile)
-		// 		profile.PUT("", userHandler.UpdateProfile)
-		// 	}
-		// }
 	}
 
 	return r
 }
 
-// AuthMiddleware 认证中间件示例
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware 认证中间件，校验Bearer访问令牌并将用户信息写入上下文
+func AuthMiddleware(userService services.UserService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 这里实现JWT认证逻辑
+		if publicRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+
 		// 从Authorization头中获取token
-		token := c.GetHeader("Authorization")
-		if token == "" {
+		header := c.GetHeader("Authorization")
+		if header == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authorization header required",
 			})
@@ -125,15 +270,80 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// 验证token逻辑...
-		// 如果验证失败：
-		// c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
-		// c.Abort()
-		// return
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"error": "Authorization header format must be Bearer {token}",
+			})
+			c.Abort()
+			return
+		}
+
+		claims, err := userService.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			message := "Invalid token"
+			if errors.Is(err, auth.ErrExpiredToken) {
+				message = "Token expired"
+			} else if errors.Is(err, services.ErrUserDisabled) {
+				message = "User disabled"
+			}
+			c.JSON(http.StatusUnauthorized, gin.H{"error": message})
+			c.Abort()
+			return
+		}
+
+		// 设置用户信息到上下文，服务层据此限定家庭数据范围
+		c.Set("user_id", claims.UserID)
+		c.Set("username", claims.Username)
+		ctx := services.WithUserID(c.Request.Context(), claims.UserID)
+		ctx = services.WithClientMeta(ctx, services.ClientMeta{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()})
+		c.Request = c.Request.WithContext(ctx)
 
-		// 如果验证成功，设置用户信息到上下文
-		// c.Set("user_id", userID)
-		
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// ReadCacheMiddleware 读缓存中间件：写入请求不读取缓存，并在返回响应前使当前用户所在家庭的缓存失效，
+// 客户端收到写入结果后再读取时不会读到旧数据
+func ReadCacheMiddleware(readCache *services.ReadCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if readCache == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		ctx := services.WithoutReadCache(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+		writer := &invalidatingWriter{ResponseWriter: c.Writer, invalidate: func() {
+			if userID, ok := services.UserIDFromContext(ctx); ok {
+				readCache.InvalidateUser(context.WithoutCancel(ctx), userID)
+			}
+		}}
+		c.Writer = writer
+
+		c.Next()
+		writer.once.Do(writer.invalidate)
+	}
+}
+
+// invalidatingWriter 在第一次写出响应前使缓存失效
+type invalidatingWriter struct {
+	gin.ResponseWriter
+	once       sync.Once
+	invalidate func()
+}
+
+func (w *invalidatingWriter) WriteHeaderNow() {
+	w.once.Do(w.invalidate)
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *invalidatingWriter) Write(data []byte) (int, error) {
+	w.once.Do(w.invalidate)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *invalidatingWriter) WriteString(s string) (int, error) {
+	w.once.Do(w.invalidate)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/ical"
+	"nookverse/internal/models"
+)
+
+// 日历订阅限制
+const (
+	calendarRefreshHint      = time.Hour           // 建议日历应用每小时刷新一次
+	calendarPastWindow       = 30 * 24 * time.Hour // 过期和保修到期事件保留最近30天
+	calendarReminderDuration = 15 * time.Minute
+	maxCalendarEvents        = 2000 // 每类事件的上限
+)
+
+// warrantyExpirySQL 物品保修到期日期，未设置购买日期或保修期时为NULL
+const warrantyExpirySQL = "(CASE WHEN warranty_period > 0 THEN purchase_date + warranty_period * INTERVAL '1 month' END)"
+
+// ErrCalendarFeedNotFound 订阅令牌无效或已被重置
+var ErrCalendarFeedNotFound = notFoundError("日历订阅不存在")
+
+// CalendarService 日历订阅服务接口
+type CalendarService interface {
+	GetFeed(ctx context.Context) (*models.CalendarFeed, error)
+	// ResetFeedToken 生成新的订阅令牌，旧令牌立即失效；明文令牌只在此时返回
+	ResetFeedToken(ctx context.Context) (string, *models.CalendarFeed, error)
+	RevokeFeed(ctx context.Context) error
+	// RenderFeed 以订阅令牌生成用户的日历，无需登录
+	RenderFeed(ctx context.Context, token string) (*ical.Calendar, error)
+}
+
+type calendarService struct {
+	db *gorm.DB
+}
+
+// NewCalendarService 创建日历订阅服务实例
+func NewCalendarService(db *gorm.DB) CalendarService {
+	return &calendarService{db: db}
+}
+
+// GetFeed 获取当前用户的日历订阅
+func (s *calendarService) GetFeed(ctx context.Context) (*models.CalendarFeed, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var feed models.CalendarFeed
+	if err := s.db.WithContext(ctx).First(&feed, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// ResetFeedToken 创建订阅或替换已有订阅的令牌
+func (s *calendarService) ResetFeedToken(ctx context.Context) (string, *models.CalendarFeed, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := generateToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var feed models.CalendarFeed
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		feed = models.CalendarFeed{UserID: userID, TokenHash: hashToken(token)}
+		return tx.Create(&feed).Error
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &feed, nil
+}
+
+// RevokeFeed 删除当前用户的日历订阅
+func (s *calendarService) RevokeFeed(ctx context.Context) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.CalendarFeed{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrCalendarFeedNotFound
+	}
+	return nil
+}
+
+// RenderFeed 查询订阅用户可见物品的待发送提醒、过期日期和保修到期日期
+func (s *calendarService) RenderFeed(ctx context.Context, token string) (*ical.Calendar, error) {
+	if token == "" {
+		return nil, ErrCalendarFeedNotFound
+	}
+
+	var feed models.CalendarFeed
+	err := s.db.WithContext(ctx).
+		Where("token_hash = ?", hashToken(token)).
+		Where("user_id IN (SELECT id FROM users WHERE status = 1)").
+		First(&feed).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarFeedNotFound
+		}
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", feed.UserID).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.db.WithContext(ctx).Model(&feed).Update("last_accessed_at", now)
+
+	var reminders []models.Reminder
+	err = s.db.WithContext(ctx).
+		Scopes(scopeReminders(user.ID)).
+		Where("reminders.status = ?", ReminderStatusPending).
+		Preload("Item").
+		Order("reminders.trigger_time").
+		Limit(maxCalendarEvents).
+		Find(&reminders).Error
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-calendarPastWindow)
+	var items []models.Item
+	err = s.db.WithContext(ctx).
+		Scopes(scopeItems(user.ID)).
+		Where("status IS DISTINCT FROM ?", itemStatusDiscarded).
+		Where("(expire_date >= @cutoff OR "+warrantyExpirySQL+" >= @cutoff)", sql.Named("cutoff", cutoff)).
+		// 按最近的事件日期排序，超出上限时舍弃最远的事件
+		Clauses(clause.OrderBy{Expression: clause.NamedExpr{
+			SQL:  "LEAST(CASE WHEN expire_date >= @cutoff THEN expire_date END, CASE WHEN " + warrantyExpirySQL + " >= @cutoff THEN " + warrantyExpirySQL + " END), id",
+			Vars: []any{sql.Named("cutoff", cutoff)},
+		}}).
+		Limit(maxCalendarEvents).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildCalendar(&user, reminders, items, cutoff), nil
+}
+
+// BuildCalendar 生成日历：每个提醒为一个定时事件（重复提醒附带RRULE），物品的过期日期和保修到期日期为全天事件
+// 事件UID由提醒ID或物品ID确定，提醒时间或物品日期变化后日历应用会更新原事件而不是重复添加；早于 since 的日期不再列出
+func BuildCalendar(user *models.User, reminders []models.Reminder, items []models.Item, since time.Time) *ical.Calendar {
+	calendar := &ical.Calendar{
+		ProdID:      "-//Nookverse//Reminders//ZH",
+		Name:        "Nookverse · " + user.Username,
+		RefreshHint: calendarRefreshHint,
+		Events:      make([]ical.Event, 0, len(reminders)+len(items)),
+	}
+
+	for i := range reminders {
+		reminder := &reminders[i]
+		event := ical.Event{
+			UID:          fmt.Sprintf("reminder-%s@nookverse", reminder.ID),
+			Summary:      reminderTitle(reminder),
+			Description:  reminder.Message,
+			Categories:   []string{reminder.ReminderType},
+			Start:        reminder.TriggerTime,
+			Duration:     calendarReminderDuration,
+			LastModified: reminder.UpdatedAt,
+			Alarm:        true,
+		}
+		if reminder.Recurrence != nil && reminder.RecurrenceStart != nil {
+			event.Start = *reminder.RecurrenceStart
+			event.RRule = *reminder.Recurrence
+		}
+		calendar.Events = append(calendar.Events, event)
+	}
+
+	for i := range items {
+		item := &items[i]
+		if item.ExpireDate != nil && !item.ExpireDate.Before(since) {
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:          fmt.Sprintf("item-%s-expire@nookverse", item.ID),
+				Summary:      item.Name + "过期",
+				Description:  fmt.Sprintf("%s将于%s过期", item.Name, item.ExpireDate.Format("2006-01-02")),
+				Categories:   []string{ReminderTypeExpire},
+				Start:        *item.ExpireDate,
+				AllDay:       true,
+				LastModified: item.UpdatedAt,
+			})
+		}
+		if item.PurchaseDate != nil && item.WarrantyPeriod != nil && *item.WarrantyPeriod > 0 {
+			due := item.PurchaseDate.AddDate(0, *item.WarrantyPeriod, 0)
+			if due.Before(since) {
+				continue
+			}
+			calendar.Events = append(calendar.Events, ical.Event{
+				UID:          fmt.Sprintf("item-%s-warranty@nookverse", item.ID),
+				Summary:      item.Name + "保修到期",
+				Description:  fmt.Sprintf("%s的保修将于%s到期", item.Name, due.Format("2006-01-02")),
+				Categories:   []string{ReminderTypeWarranty},
+				Start:        due,
+				AllDay:       true,
+				LastModified: item.UpdatedAt,
+			})
+		}
+	}
+
+	return calendar
+}
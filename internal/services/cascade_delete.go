@@ -0,0 +1,313 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+// 级联删除错误
+var (
+	// ErrNotEmpty 未指定级联删除或移动目标时，房屋、房间或容器不为空
+	ErrNotEmpty = errors.New("包含其他内容，不能直接删除")
+	// ErrMoveTargetInScope 移动内容的目标位置本身会被删除
+	ErrMoveTargetInScope = errors.New("目标位置在将被删除的范围内")
+	// ErrContentsForbidden 内容中有当前用户看不到或无权删除、移动的物品
+	ErrContentsForbidden = fmt.Errorf("%w: 包含无权删除或移动的物品", ErrForbidden)
+)
+
+// 不为空时拒绝删除的错误，可通过 errors.Is(err, ErrNotEmpty) 识别
+var (
+	errHouseNotEmpty     = notEmptyError("该房屋包含房间，不能直接删除")
+	errRoomNotEmpty      = notEmptyError("该房间包含物品，不能直接删除")
+	errContainerNotEmpty = notEmptyError("该物品包含其他物品，不能直接删除")
+)
+
+// notEmptyError 可通过 errors.Is(err, ErrNotEmpty) 识别的错误
+type notEmptyError string
+
+func (e notEmptyError) Error() string { return string(e) }
+
+func (e notEmptyError) Is(target error) bool { return target == ErrNotEmpty }
+
+// DeleteOptions 删除房屋、房间或容器时如何处理其内容
+type DeleteOptions struct {
+	Cascade bool        // 内容随之删除：房屋内的房间和物品、房间内的物品、容器内的物品
+	MoveTo  *MoveTarget // 删除前先将物品移到目标房间或容器，房屋内的房间仍随房屋删除
+	DryRun  bool        // 只统计将被删除的内容，不做修改
+}
+
+// DeleteSummary 删除涉及的内容，房间和物品数包括被删除的房间或容器本身
+type DeleteSummary struct {
+	DryRun    bool  `json:"dry_run"`
+	Rooms     int64 `json:"rooms"`
+	Items     int64 `json:"items"`
+	Media     int64 `json:"media"`
+	Reminders int64 `json:"reminders"`
+	Moved     int64 `json:"moved"` // 移到目标位置的物品数，容器内的物品随容器移动，不单独计数
+}
+
+// deletePlan 一次删除的对象：房屋及其房间、单个房间或容器，以及其中的全部物品
+type deletePlan struct {
+	userID    string
+	house     *models.House
+	rooms     []models.Room
+	container *models.Item
+	contents  []models.Item
+	notEmpty  error // 未指定级联或移动且内容不为空时返回的错误
+}
+
+// loadHousePlan 加载房屋及其房间和物品
+func loadHousePlan(tx *gorm.DB, userID string, house *models.House) (*deletePlan, error) {
+	plan := &deletePlan{userID: userID, house: house, notEmpty: errHouseNotEmpty}
+	if err := tx.Where("house_id = ?", house.ID).Find(&plan.rooms).Error; err != nil {
+		return nil, err
+	}
+	return plan, plan.loadRoomContents(tx)
+}
+
+// loadRoomPlan 加载房间及其物品
+func loadRoomPlan(tx *gorm.DB, userID string, room *models.Room) (*deletePlan, error) {
+	plan := &deletePlan{userID: userID, rooms: []models.Room{*room}, notEmpty: errRoomNotEmpty}
+	return plan, plan.loadRoomContents(tx)
+}
+
+// loadContainerPlan 加载物品及其全部后代
+func loadContainerPlan(tx *gorm.DB, userID string, item *models.Item) (*deletePlan, error) {
+	plan := &deletePlan{userID: userID, container: item, notEmpty: errContainerNotEmpty}
+	err := tx.Where("id IN (SELECT descendant_id FROM item_hierarchy WHERE ancestor_id = ? AND depth > 0)", item.ID).
+		Find(&plan.contents).Error
+	return plan, err
+}
+
+// loadRoomContents 加载房间内的全部物品
+func (p *deletePlan) loadRoomContents(tx *gorm.DB) error {
+	if len(p.rooms) == 0 {
+		return nil
+	}
+	roomIDs := make([]string, 0, len(p.rooms))
+	for _, room := range p.rooms {
+		roomIDs = append(roomIDs, room.ID)
+	}
+	return tx.Where("room_id IN ?", roomIDs).Find(&p.contents).Error
+}
+
+// execute 按选项处理内容并将对象移入回收站，同一次删除的对象使用相同的删除时间，恢复时一并恢复
+func (p *deletePlan) execute(tx *gorm.DB, opts DeleteOptions) (*DeleteSummary, error) {
+	hasContents := len(p.contents) > 0 || (p.house != nil && len(p.rooms) > 0)
+	if hasContents && !opts.Cascade && opts.MoveTo == nil {
+		return nil, p.notEmpty
+	}
+
+	if err := p.checkContents(tx); err != nil {
+		return nil, err
+	}
+
+	deleted := p.contents
+	var moved []models.Item
+	var targetRoomID *string
+	if opts.MoveTo != nil {
+		var err error
+		if targetRoomID, err = p.resolveTarget(tx, *opts.MoveTo); err != nil {
+			return nil, err
+		}
+		deleted, moved = nil, p.topLevelContents()
+	}
+	if p.container != nil {
+		deleted = append(deleted, *p.container)
+	}
+
+	summary, err := p.summarize(tx, deleted)
+	if err != nil {
+		return nil, err
+	}
+	summary.Moved = int64(len(moved))
+	if opts.DryRun {
+		summary.DryRun = true
+		return summary, nil
+	}
+
+	for i := range moved {
+		if err := moveItemTo(tx, &moved[i], *opts.MoveTo, targetRoomID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	if err := trashItems(tx, deleted, now); err != nil {
+		return nil, err
+	}
+	if err := trashRooms(tx, p.rooms, now); err != nil {
+		return nil, err
+	}
+	if p.house != nil {
+		if err := trashHouse(tx, p.house, now); err != nil {
+			return nil, err
+		}
+	}
+	return summary, nil
+}
+
+// checkContents 内容中的物品都必须对当前用户可见，且没有被单独授权为只读
+func (p *deletePlan) checkContents(tx *gorm.DB) error {
+	if len(p.contents) == 0 {
+		return nil
+	}
+
+	var count int64
+	err := tx.Model(&models.Item{}).
+		Where("id IN ?", contentIDs(p.contents)).
+		Where("items.id NOT IN ("+accessibleItemsSQL+`)
+			OR EXISTS (SELECT 1 FROM item_permissions ip WHERE ip.item_id = items.id AND ip.user_id = @uid AND ip.permission_level = 'view')`,
+			sql.Named("uid", p.userID)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrContentsForbidden
+	}
+	return nil
+}
+
+// resolveTarget 校验移动目标可访问且不会随之删除，返回目标所在的房间
+func (p *deletePlan) resolveTarget(tx *gorm.DB, target MoveTarget) (*string, error) {
+	roomID, err := resolveMoveTarget(tx, p.userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, room := range p.rooms {
+		if room.ID == *roomID {
+			return nil, ErrMoveTargetInScope
+		}
+	}
+	if target.ContainerID != nil {
+		if p.container != nil && p.container.ID == *target.ContainerID {
+			return nil, ErrMoveTargetInScope
+		}
+		for _, item := range p.contents {
+			if item.ID == *target.ContainerID {
+				return nil, ErrMoveTargetInScope
+			}
+		}
+	}
+	return roomID, nil
+}
+
+// topLevelContents 内容中最外层的物品，移动时其中的物品随之移动
+func (p *deletePlan) topLevelContents() []models.Item {
+	inScope := make(map[string]bool, len(p.contents))
+	for _, item := range p.contents {
+		inScope[item.ID] = true
+	}
+
+	var top []models.Item
+	for _, item := range p.contents {
+		if item.ContainerID == nil || !inScope[*item.ContainerID] {
+			top = append(top, item)
+		}
+	}
+	return top
+}
+
+// summarize 统计将被删除的房间、物品以及物品的媒体文件和提醒
+func (p *deletePlan) summarize(tx *gorm.DB, deleted []models.Item) (*DeleteSummary, error) {
+	summary := &DeleteSummary{Rooms: int64(len(p.rooms)), Items: int64(len(deleted))}
+	if len(deleted) == 0 {
+		return summary, nil
+	}
+
+	ids := contentIDs(deleted)
+	if err := tx.Model(&models.MediaFile{}).Where("item_id IN ?", ids).Count(&summary.Media).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Reminder{}).Where("item_id IN ?", ids).Count(&summary.Reminders).Error; err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// trashItems 将物品移入回收站，逐个记录删除日志并移出闭包表
+func trashItems(tx *gorm.DB, items []models.Item, deletedAt time.Time) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	// 物品从回收站彻底删除后日志的 item_id 置空，entity_id 仍指向该物品
+	for i := range items {
+		err := recordOperation(tx, operationEntry{
+			EntityType:  EntityItem,
+			EntityID:    items[i].ID,
+			ItemID:      &items[i].ID,
+			HouseID:     roomHouseID(tx, items[i].RoomID),
+			Operation:   OperationDelete,
+			Description: "删除物品: " + items[i].Name,
+			OldValue:    auditSnapshot(&items[i]),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	ids := contentIDs(items)
+	if err := deleteItemHierarchy(tx, ids...); err != nil {
+		return err
+	}
+	return tx.Model(&models.Item{}).Where("id IN ?", ids).Update("deleted_at", deletedAt).Error
+}
+
+// trashRooms 将房间移入回收站并逐个记录删除日志
+func trashRooms(tx *gorm.DB, rooms []models.Room, deletedAt time.Time) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(rooms))
+	for i := range rooms {
+		err := recordOperation(tx, operationEntry{
+			EntityType:  EntityRoom,
+			EntityID:    rooms[i].ID,
+			HouseID:     &rooms[i].HouseID,
+			Operation:   OperationDelete,
+			Description: "删除房间: " + rooms[i].Name,
+			OldValue:    auditSnapshot(&rooms[i]),
+		})
+		if err != nil {
+			return err
+		}
+		ids = append(ids, rooms[i].ID)
+	}
+
+	return tx.Model(&models.Room{}).Where("id IN ?", ids).Update("deleted_at", deletedAt).Error
+}
+
+// trashHouse 将房屋移入回收站并记录删除日志
+func trashHouse(tx *gorm.DB, house *models.House, deletedAt time.Time) error {
+	err := recordOperation(tx, operationEntry{
+		EntityType:  EntityHouse,
+		EntityID:    house.ID,
+		HouseID:     &house.ID,
+		Operation:   OperationDelete,
+		Description: "删除房屋: " + house.Name,
+		OldValue:    auditSnapshot(house),
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.House{}).Where("id = ?", house.ID).Update("deleted_at", deletedAt).Error
+}
+
+// contentIDs 物品ID列表
+func contentIDs(items []models.Item) []string {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
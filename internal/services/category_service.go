@@ -0,0 +1,483 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+)
+
+// 分类相关错误
+var (
+	ErrCategoryNotFound = notFoundError("分类不存在")
+	ErrSystemCategory   = errors.New("系统分类为只读，不能修改、删除或合并")
+	ErrCategoryCycle    = errors.New("不能将分类移动到自身或其子分类下")
+	ErrCategoryHasChild = errors.New("该分类包含子分类，请先删除或移动子分类")
+	ErrCategoryInUse    = errors.New("该分类仍被当前用户无权访问的物品使用，不能删除或合并")
+)
+
+// CategoryService 分类服务接口
+type CategoryService interface {
+	// 分类基础CRUD操作
+	CreateCategory(ctx context.Context, category *models.Category) error
+	GetCategoryByID(ctx context.Context, id string) (*models.Category, error)
+	UpdateCategory(ctx context.Context, category *models.Category) error
+	DeleteCategory(ctx context.Context, id string) error
+	ListCategories(ctx context.Context) ([]models.Category, error)
+
+	// 层级与排序
+	GetCategoryTree(ctx context.Context) ([]models.Category, error)
+	ReorderCategories(ctx context.Context, orders []CategoryOrder) error
+	MergeCategory(ctx context.Context, sourceID, targetID string) (int64, error)
+}
+
+// CategoryOrder 分类排序
+type CategoryOrder struct {
+	ID        string
+	SortOrder int
+}
+
+type categoryService struct {
+	db *gorm.DB
+}
+
+// NewCategoryService 创建分类服务实例
+func NewCategoryService(db *gorm.DB) CategoryService {
+	return &categoryService{db: db}
+}
+
+// CreateCategory 创建自定义分类
+func (s *categoryService) CreateCategory(ctx context.Context, category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("分类名称不能为空")
+	}
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if category.ParentID != nil {
+		if _, err := s.findCategory(ctx, *category.ParentID); err != nil {
+			return notFoundError("父分类不存在")
+		}
+	}
+
+	// 系统分类只能通过初始化脚本创建
+	category.IsSystem = false
+	category.CreatedBy = &userID
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(category).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityCategory,
+			EntityID:    category.ID,
+			Operation:   OperationCreate,
+			Description: "创建分类: " + category.Name,
+			NewValue:    auditSnapshot(category),
+		})
+	})
+}
+
+// GetCategoryByID 根据ID获取分类及其直接子分类
+func (s *categoryService) GetCategoryByID(ctx context.Context, id string) (*models.Category, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var category models.Category
+	err = s.db.WithContext(ctx).
+		Scopes(scopeCategories(userID)).
+		Preload("Children", func(db *gorm.DB) *gorm.DB {
+			return db.Scopes(scopeCategories(userID)).Order("sort_order, name")
+		}).
+		First(&category, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// UpdateCategory 更新自定义分类，修改父分类时校验循环引用
+func (s *categoryService) UpdateCategory(ctx context.Context, category *models.Category) error {
+	category.Name = strings.TrimSpace(category.Name)
+	if category.Name == "" {
+		return errors.New("分类名称不能为空")
+	}
+
+	existing, err := s.editableCategory(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+
+	if !SameID(existing.ParentID, category.ParentID) && category.ParentID != nil {
+		if err := s.checkCategoryCycle(ctx, category.ID, *category.ParentID); err != nil {
+			return err
+		}
+	}
+
+	// 系统标识和创建者不允许通过更新修改
+	category.IsSystem = existing.IsSystem
+	category.CreatedBy = existing.CreatedBy
+	category.CreatedAt = existing.CreatedAt
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(category).Error; err != nil {
+			return err
+		}
+
+		oldValue, newValue := AuditDiff(existing, category)
+		if oldValue == nil {
+			return nil
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityCategory,
+			EntityID:    category.ID,
+			Operation:   OperationUpdate,
+			Description: "更新分类: " + category.Name,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+		})
+	})
+}
+
+// DeleteCategory 删除自定义分类，分类下的物品变为未分类
+func (s *categoryService) DeleteCategory(ctx context.Context, id string) error {
+	category, err := s.editableCategory(ctx, id)
+	if err != nil {
+		return err
+	}
+	userID, _ := currentUserID(ctx) // editableCategory 已确认当前用户为创建者
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Category{}).Where("parent_id = ?", id).Count(&count)
+	if count > 0 {
+		return ErrCategoryHasChild
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryItemsInScope(tx, userID, id); err != nil {
+			return err
+		}
+
+		itemIDs, err := recategorizeItems(tx, userID, id, nil)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&models.Category{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityCategory,
+			EntityID:    category.ID,
+			Operation:   OperationDelete,
+			Description: fmt.Sprintf("删除分类: %s（%d件物品变为未分类）", category.Name, len(itemIDs)),
+			OldValue:    auditSnapshot(category),
+		})
+	})
+}
+
+// ListCategories 获取当前用户可见的全部分类（平铺）
+func (s *categoryService) ListCategories(ctx context.Context) ([]models.Category, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []models.Category
+	err = s.db.WithContext(ctx).
+		Scopes(scopeCategories(userID)).
+		Order("sort_order, name").
+		Find(&categories).Error
+
+	return categories, err
+}
+
+// GetCategoryTree 获取分类树
+func (s *categoryService) GetCategoryTree(ctx context.Context) ([]models.Category, error) {
+	categories, err := s.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildCategoryTree(categories), nil
+}
+
+// ReorderCategories 批量调整分类排序
+func (s *categoryService) ReorderCategories(ctx context.Context, orders []CategoryOrder) error {
+	if len(orders) == 0 {
+		return errors.New("排序列表不能为空")
+	}
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 只能调整自己创建的分类，系统分类的排序对所有用户生效，只读
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, order := range orders {
+			var category models.Category
+			err := tx.Scopes(scopeCategories(userID)).Where("id = ?", order.ID).First(&category).Error
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrCategoryNotFound
+				}
+				return err
+			}
+			if category.IsSystem {
+				return ErrSystemCategory
+			}
+			if category.CreatedBy == nil || *category.CreatedBy != userID {
+				return ErrForbidden
+			}
+			if category.SortOrder == order.SortOrder {
+				continue
+			}
+
+			if err := tx.Model(&category).Update("sort_order", order.SortOrder).Error; err != nil {
+				return err
+			}
+			err = recordOperation(tx, operationEntry{
+				EntityType:  EntityCategory,
+				EntityID:    category.ID,
+				Operation:   OperationUpdate,
+				Description: "调整分类排序: " + category.Name,
+				OldValue:    map[string]any{"sort_order": category.SortOrder},
+				NewValue:    map[string]any{"sort_order": order.SortOrder},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MergeCategory 将源分类合并到目标分类：物品和子分类转移到目标分类后删除源分类，返回转移的物品数
+func (s *categoryService) MergeCategory(ctx context.Context, sourceID, targetID string) (int64, error) {
+	if sourceID == targetID {
+		return 0, errors.New("不能将分类合并到自身")
+	}
+
+	source, err := s.editableCategory(ctx, sourceID)
+	if err != nil {
+		return 0, err
+	}
+	target, err := s.findCategory(ctx, targetID)
+	if err != nil {
+		return 0, notFoundError("目标分类不存在")
+	}
+
+	// 目标分类不能位于源分类的子树中，否则转移子分类后会形成循环
+	if err := s.checkCategoryCycle(ctx, sourceID, targetID); err != nil {
+		return 0, err
+	}
+
+	userID, _ := currentUserID(ctx) // editableCategory 已确认当前用户为创建者
+
+	var moved int64
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkCategoryItemsInScope(tx, userID, sourceID); err != nil {
+			return err
+		}
+
+		itemIDs, err := recategorizeItems(tx, userID, sourceID, &targetID)
+		if err != nil {
+			return err
+		}
+		moved = int64(len(itemIDs))
+
+		err = tx.Model(&models.Category{}).
+			Where("parent_id = ?", sourceID).
+			Update("parent_id", targetID).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&models.Category{}, "id = ?", sourceID).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityCategory,
+			EntityID:    source.ID,
+			Operation:   OperationDelete,
+			Description: fmt.Sprintf("合并分类: %s → %s（转移%d件物品）", source.Name, target.Name, moved),
+			OldValue:    auditSnapshot(source),
+			NewValue:    map[string]any{"merged_into": target.ID, "moved_items": moved},
+		})
+	})
+
+	return moved, err
+}
+
+// findCategory 查找当前用户可见的分类
+func (s *categoryService) findCategory(ctx context.Context, id string) (*models.Category, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var category models.Category
+	if err := s.db.WithContext(ctx).Scopes(scopeCategories(userID)).First(&category, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// editableCategory 查找当前用户可修改的分类：自定义分类仅创建者可以修改，系统分类只读，返回 ErrSystemCategory
+func (s *categoryService) editableCategory(ctx context.Context, id string) (*models.Category, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := s.findCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if category.IsSystem {
+		return nil, ErrSystemCategory
+	}
+	if category.CreatedBy == nil || *category.CreatedBy != userID {
+		return nil, ErrForbidden
+	}
+
+	return category, nil
+}
+
+// checkCategoryItemsInScope 分类被用户无权访问的物品（其他家庭或未授权的私有物品）引用时拒绝删除或合并，
+// 否则这些物品会被静默修改
+func checkCategoryItemsInScope(tx *gorm.DB, userID, categoryID string) error {
+	var count int64
+	err := tx.Model(&models.Item{}).
+		Where("category_id = ?", categoryID).
+		Where("items.id NOT IN ("+accessibleItemsSQL+")", sql.Named("uid", userID)).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCategoryInUse
+	}
+	return nil
+}
+
+// recategorizeItems 将分类下当前用户可访问的物品改为新分类（为空时变为未分类），并为这些物品记录版本，返回修改的物品ID
+func recategorizeItems(tx *gorm.DB, userID, categoryID string, newCategoryID *string) ([]string, error) {
+	var itemIDs []string
+	err := tx.Model(&models.Item{}).
+		Scopes(scopeItems(userID)).
+		Where("category_id = ?", categoryID).
+		Pluck("items.id", &itemIDs).Error
+	if err != nil || len(itemIDs) == 0 {
+		return itemIDs, err
+	}
+
+	if err := recordItemBaseline(tx, itemIDs...); err != nil {
+		return nil, err
+	}
+	err = tx.Model(&models.Item{}).
+		Where("id IN ?", itemIDs).
+		Update("category_id", newCategoryID).Error
+	if err != nil {
+		return nil, err
+	}
+	return itemIDs, recordItemVersions(tx, OperationUpdate, itemIDs...)
+}
+
+// checkCategoryCycle 校验将分类挂到新父分类下不会形成循环
+func (s *categoryService) checkCategoryCycle(ctx context.Context, categoryID, parentID string) error {
+	if _, err := s.findCategory(ctx, parentID); err != nil {
+		return notFoundError("父分类不存在")
+	}
+
+	descendants, err := categoryDescendantIDs(s.db.WithContext(ctx), categoryID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range descendants {
+		if id == parentID {
+			return ErrCategoryCycle
+		}
+	}
+
+	return nil
+}
+
+// categoryTreeSQL 分类及其全部后代分类的ID，UNION去重避免脏数据中的循环导致无限递归
+const categoryTreeSQL = `WITH RECURSIVE category_tree AS (
+	SELECT id FROM categories WHERE id = @category_id
+	UNION
+	SELECT c.id FROM categories c JOIN category_tree t ON c.parent_id = t.id
+) SELECT id FROM category_tree`
+
+// categoryDescendantIDs 获取分类及其全部后代分类的ID
+func categoryDescendantIDs(db *gorm.DB, categoryID string) ([]string, error) {
+	var ids []string
+	err := db.Raw(categoryTreeSQL, sql.Named("category_id", categoryID)).Scan(&ids).Error
+
+	return ids, err
+}
+
+// whereCategory 按分类过滤物品，includeDescendants为true时包含全部后代分类
+func whereCategory(db *gorm.DB, categoryID string, includeDescendants bool) *gorm.DB {
+	if !includeDescendants {
+		return db.Where("items.category_id = ?", categoryID)
+	}
+	return db.Where("items.category_id IN ("+categoryTreeSQL+")", sql.Named("category_id", categoryID))
+}
+
+// BuildCategoryTree 将平铺的分类列表组装为树，父分类不存在的分类作为根节点
+func BuildCategoryTree(categories []models.Category) []models.Category {
+	exists := make(map[string]bool, len(categories))
+	children := make(map[string][]models.Category)
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && exists[*category.ParentID] && *category.ParentID != category.ID {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	visited := make(map[string]bool)
+	var attach func(nodes []models.Category) []models.Category
+	attach = func(nodes []models.Category) []models.Category {
+		var result []models.Category
+		for _, node := range nodes {
+			if visited[node.ID] {
+				continue
+			}
+			visited[node.ID] = true
+			node.Children = attach(children[node.ID])
+			result = append(result, node)
+		}
+		return result
+	}
+
+	return attach(roots)
+}
@@ -0,0 +1,24 @@
+package services
+
+import "errors"
+
+// 通用错误
+var (
+	ErrUnauthenticated = errors.New("未授权访问")
+	ErrNotFound        = errors.New("资源不存在")
+)
+
+// 资源不存在错误，跨家庭访问时同样返回，避免泄露资源是否存在
+var (
+	ErrHouseNotFound  = notFoundError("房屋不存在")
+	ErrRoomNotFound   = notFoundError("房间不存在")
+	ErrItemNotFound   = notFoundError("物品不存在")
+	ErrFamilyNotFound = notFoundError("家庭不存在")
+)
+
+// notFoundError 可通过 errors.Is(err, ErrNotFound) 识别的资源不存在错误
+type notFoundError string
+
+func (e notFoundError) Error() string { return string(e) }
+
+func (e notFoundError) Is(target error) bool { return target == ErrNotFound }
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+)
+
+// 家庭成员状态
+const (
+	MemberStatusActive   = 1 // 正常
+	MemberStatusDisabled = 2 // 禁用
+	MemberStatusPending  = 3 // 待审核
+)
+
+// 家庭邀请相关错误
+var (
+	ErrInvalidInviteCode = errors.New("邀请码无效或已过期")
+	ErrAlreadyMember     = errors.New("已是该家庭成员或已提交加入申请")
+	ErrMemberDisabled    = errors.New("该用户在此家庭中已被禁用")
+	ErrCannotRemoveOwner = errors.New("不能移除家庭所有者，请先转让所有权")
+	ErrMemberNotFound    = notFoundError("家庭成员不存在")
+)
+
+// inviteCodeAlphabet 邀请码字符集（去除易混淆的 0/O、1/I）
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// inviteCodeLength 邀请码长度
+const inviteCodeLength = 8
+
+// invitableRoles 可通过邀请码授予的角色
+var invitableRoles = map[string]bool{
+	RoleAdmin:  true,
+	RoleMember: true,
+	RoleViewer: true,
+}
+
+// FamilyService 家庭服务接口
+type FamilyService interface {
+	// 家庭管理
+	CreateFamily(ctx context.Context, family *models.Family) error
+	GetFamilyByID(ctx context.Context, id string) (*models.Family, error)
+	ListFamilies(ctx context.Context) ([]models.Family, error)
+
+	// 邀请码
+	RotateInviteCode(ctx context.Context, familyID, role string, ttl time.Duration) (*models.Family, error)
+	JoinFamily(ctx context.Context, inviteCode string) (*models.FamilyMember, error)
+
+	// 成员管理
+	ListMembers(ctx context.Context, familyID string) ([]models.FamilyMember, error)
+	ApproveMember(ctx context.Context, familyID, userID string) (*models.FamilyMember, error)
+	RejectMember(ctx context.Context, familyID, userID string) error
+	RemoveMember(ctx context.Context, familyID, userID string) error
+	TransferOwnership(ctx context.Context, familyID, newOwnerID string) (*models.Family, error)
+}
+
+type familyService struct {
+	db *gorm.DB
+}
+
+// NewFamilyService 创建家庭服务实例
+func NewFamilyService(db *gorm.DB) FamilyService {
+	return &familyService{db: db}
+}
+
+// CreateFamily 创建家庭，当前用户成为家庭所有者
+func (s *familyService) CreateFamily(ctx context.Context, family *models.Family) error {
+	family.Name = strings.TrimSpace(family.Name)
+	if family.Name == "" {
+		return errors.New("家庭名称不能为空")
+	}
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	family.OwnerID = userID
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(family).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Create(&models.FamilyMember{
+			FamilyID: family.ID,
+			UserID:   userID,
+			Role:     RoleOwner,
+			JoinedAt: time.Now(),
+			Status:   MemberStatusActive,
+		}).Error
+	})
+}
+
+// GetFamilyByID 获取当前用户所在的家庭
+func (s *familyService) GetFamilyByID(ctx context.Context, id string) (*models.Family, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var family models.Family
+	err = s.db.WithContext(ctx).
+		Scopes(scopeFamilies(userID)).
+		First(&family, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFamilyNotFound
+		}
+		return nil, err
+	}
+
+	return &family, nil
+}
+
+// ListFamilies 获取当前用户所在的全部家庭
+func (s *familyService) ListFamilies(ctx context.Context) ([]models.Family, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var families []models.Family
+	err = s.db.WithContext(ctx).
+		Scopes(scopeFamilies(userID)).
+		Order("created_at").
+		Find(&families).Error
+
+	return families, err
+}
+
+// RotateInviteCode 生成新的邀请码，旧邀请码立即失效
+func (s *familyService) RotateInviteCode(ctx context.Context, familyID, role string, ttl time.Duration) (*models.Family, error) {
+	if role == "" {
+		role = RoleMember
+	}
+	if !invitableRoles[role] {
+		return nil, errors.New("邀请码只能授予admin、member或viewer角色")
+	}
+	if ttl <= 0 {
+		return nil, errors.New("邀请码有效期必须大于0")
+	}
+
+	family, err := s.GetFamilyByID(ctx, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(ttl)
+
+	err = s.db.WithContext(ctx).Model(&models.Family{}).
+		Where("id = ?", family.ID).
+		Updates(map[string]any{
+			"invite_code":       code,
+			"invite_role":       role,
+			"invite_expires_at": expiresAt,
+			"updated_at":        time.Now(),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	family.InviteCode = &code
+	family.InviteRole = role
+	family.InviteExpiresAt = &expiresAt
+
+	return family, nil
+}
+
+// JoinFamily 使用邀请码申请加入家庭，需管理员审核后生效
+func (s *familyService) JoinFamily(ctx context.Context, inviteCode string) (*models.FamilyMember, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+	if inviteCode == "" {
+		return nil, ErrInvalidInviteCode
+	}
+
+	var family models.Family
+	err = s.db.WithContext(ctx).
+		Where("invite_code = ? AND invite_expires_at > ?", inviteCode, time.Now()).
+		First(&family).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInviteCode
+		}
+		return nil, err
+	}
+
+	var existing models.FamilyMember
+	err = s.db.WithContext(ctx).
+		Where("family_id = ? AND user_id = ?", family.ID, userID).
+		First(&existing).Error
+	if err == nil {
+		if existing.Status == MemberStatusDisabled {
+			return nil, ErrMemberDisabled
+		}
+		return nil, ErrAlreadyMember
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	member := &models.FamilyMember{
+		FamilyID: family.ID,
+		UserID:   userID,
+		Role:     family.InviteRole,
+		JoinedAt: time.Now(),
+		Status:   MemberStatusPending,
+	}
+	if err := s.db.WithContext(ctx).Omit(clause.Associations).Create(member).Error; err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// ListMembers 获取家庭成员列表（包含待审核的申请）
+func (s *familyService) ListMembers(ctx context.Context, familyID string) ([]models.FamilyMember, error) {
+	if _, err := s.GetFamilyByID(ctx, familyID); err != nil {
+		return nil, err
+	}
+
+	var members []models.FamilyMember
+	err := s.db.WithContext(ctx).
+		Where("family_id = ?", familyID).
+		Preload("User").
+		Order("joined_at").
+		Find(&members).Error
+
+	return members, err
+}
+
+// ApproveMember 通过待审核的加入申请
+func (s *familyService) ApproveMember(ctx context.Context, familyID, userID string) (*models.FamilyMember, error) {
+	member, err := s.findMember(ctx, familyID, userID, MemberStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	member.Status = MemberStatusActive
+	member.JoinedAt = time.Now()
+	err = s.db.WithContext(ctx).Model(member).
+		Updates(map[string]any{"status": member.Status, "joined_at": member.JoinedAt}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// RejectMember 拒绝待审核的加入申请
+func (s *familyService) RejectMember(ctx context.Context, familyID, userID string) error {
+	member, err := s.findMember(ctx, familyID, userID, MemberStatusPending)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Delete(member).Error
+}
+
+// RemoveMember 移除家庭成员，家庭所有者需先转让所有权
+func (s *familyService) RemoveMember(ctx context.Context, familyID, userID string) error {
+	member, err := s.findMember(ctx, familyID, userID, MemberStatusActive, MemberStatusDisabled)
+	if err != nil {
+		return err
+	}
+
+	if member.Role == RoleOwner {
+		return ErrCannotRemoveOwner
+	}
+
+	return s.db.WithContext(ctx).Delete(member).Error
+}
+
+// TransferOwnership 将家庭所有权转让给其他正常成员，原所有者降为管理员
+func (s *familyService) TransferOwnership(ctx context.Context, familyID, newOwnerID string) (*models.Family, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetFamilyByID(ctx, familyID); err != nil {
+		return nil, err
+	}
+
+	var family models.Family
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁定家庭记录，避免并发转让
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&family, "id = ?", familyID).Error; err != nil {
+			return err
+		}
+
+		if family.OwnerID != userID {
+			return ErrForbidden
+		}
+		if newOwnerID == userID {
+			return errors.New("不能将所有权转让给自己")
+		}
+
+		var target models.FamilyMember
+		err := tx.Where("family_id = ? AND user_id = ? AND status = ?", familyID, newOwnerID, MemberStatusActive).
+			First(&target).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrMemberNotFound
+			}
+			return err
+		}
+
+		family.OwnerID = newOwnerID
+		family.UpdatedAt = time.Now()
+		err = tx.Model(&family).
+			Updates(map[string]any{"owner_id": family.OwnerID, "updated_at": family.UpdatedAt}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&target).Update("role", RoleOwner).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.FamilyMember{}).
+			Where("family_id = ? AND user_id = ?", familyID, userID).
+			Update("role", RoleAdmin).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &family, nil
+}
+
+// findMember 查找指定状态的家庭成员，调用者需为该家庭成员
+func (s *familyService) findMember(ctx context.Context, familyID, userID string, statuses ...int) (*models.FamilyMember, error) {
+	if _, err := s.GetFamilyByID(ctx, familyID); err != nil {
+		return nil, err
+	}
+
+	var member models.FamilyMember
+	err := s.db.WithContext(ctx).
+		Where("family_id = ? AND user_id = ? AND status IN ?", familyID, userID, statuses).
+		First(&member).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &member, nil
+}
+
+// generateInviteCode 生成随机邀请码
+func generateInviteCode() (string, error) {
+	buf := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := make([]byte, inviteCodeLength)
+	for i, b := range buf {
+		code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(code), nil
+}
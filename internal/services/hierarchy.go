@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+// maxHierarchyDepth 重建闭包表时的最大嵌套深度，防止脏数据中的循环引用导致无限递归
+const maxHierarchyDepth = 64
+
+// ErrHierarchyCycle 容器关系形成循环引用
+var ErrHierarchyCycle = errors.New("不能形成循环引用")
+
+// 闭包表（item_hierarchy）维护规则：
+// 每个物品都有一条指向自身、depth为0的记录；
+// 物品位于容器中时，容器的每个祖先都有一条指向该物品的记录，depth为层级距离。
+
+// insertItemHierarchy 为新建物品写入闭包记录
+func insertItemHierarchy(tx *gorm.DB, itemID string, containerID *string) error {
+	err := tx.Exec(`INSERT INTO item_hierarchy (ancestor_id, descendant_id, depth) VALUES (?, ?, 0)`,
+		itemID, itemID).Error
+	if err != nil {
+		return err
+	}
+
+	if containerID == nil {
+		return nil
+	}
+
+	return tx.Exec(`
+		INSERT INTO item_hierarchy (ancestor_id, descendant_id, depth)
+		SELECT ancestor_id, ?, depth + 1 FROM item_hierarchy WHERE descendant_id = ?
+	`, itemID, *containerID).Error
+}
+
+// checkHierarchyCycle 校验将物品放入容器不会形成循环引用
+func checkHierarchyCycle(tx *gorm.DB, itemID, containerID string) error {
+	if itemID == containerID {
+		return errors.New("不能将物品移动到自身")
+	}
+
+	var count int64
+	err := tx.Table("item_hierarchy").
+		Where("ancestor_id = ? AND descendant_id = ?", itemID, containerID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrHierarchyCycle
+	}
+
+	return nil
+}
+
+// moveItemHierarchy 将物品及其子树挂到新容器下，containerID为空表示移出容器
+func moveItemHierarchy(tx *gorm.DB, itemID string, containerID *string) error {
+	// 断开子树与原祖先之间的记录，子树内部记录保持不变
+	err := tx.Exec(`
+		DELETE FROM item_hierarchy
+		WHERE descendant_id IN (SELECT descendant_id FROM item_hierarchy WHERE ancestor_id = ?)
+		AND ancestor_id NOT IN (SELECT descendant_id FROM item_hierarchy WHERE ancestor_id = ?)
+	`, itemID, itemID).Error
+	if err != nil {
+		return err
+	}
+
+	if containerID == nil {
+		return nil
+	}
+
+	// 新容器的每个祖先 × 子树的每个节点
+	return tx.Exec(`
+		INSERT INTO item_hierarchy (ancestor_id, descendant_id, depth)
+		SELECT anc.ancestor_id, sub.descendant_id, anc.depth + sub.depth + 1
+		FROM item_hierarchy anc
+		CROSS JOIN item_hierarchy sub
+		WHERE anc.descendant_id = ? AND sub.ancestor_id = ?
+	`, *containerID, itemID).Error
+}
+
+// cascadeItemRoom 将物品所有后代的房间同步为指定房间，并为房间发生变化的后代记录版本
+func cascadeItemRoom(tx *gorm.DB, itemID string, roomID *string) error {
+	var ids []string
+	err := tx.Unscoped().Model(&models.Item{}).
+		Where("id IN (SELECT descendant_id FROM item_hierarchy WHERE ancestor_id = ? AND depth > 0)", itemID).
+		Where("room_id IS DISTINCT FROM ?", roomID).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	if err := recordItemBaseline(tx, ids...); err != nil {
+		return err
+	}
+	if err := tx.Exec(`UPDATE items SET room_id = ?, updated_at = NOW() WHERE id IN ?`, roomID, ids).Error; err != nil {
+		return err
+	}
+	return recordItemVersions(tx, OperationMove, ids...)
+}
+
+// deleteItemHierarchy 删除物品相关的全部闭包记录
+func deleteItemHierarchy(tx *gorm.DB, itemIDs ...string) error {
+	return tx.Exec(`DELETE FROM item_hierarchy WHERE ancestor_id IN ? OR descendant_id IN ?`,
+		itemIDs, itemIDs).Error
+}
+
+// RebuildItemHierarchy 根据 items.container_id 重建整个闭包表，返回写入的记录数
+func RebuildItemHierarchy(ctx context.Context, db *gorm.DB) (int64, error) {
+	var rows int64
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM item_hierarchy`).Error; err != nil {
+			return err
+		}
+
+		// 存在循环引用的脏数据时同一对节点会出现多条路径，只保留最短的一条
+		result := tx.Exec(`
+			INSERT INTO item_hierarchy (ancestor_id, descendant_id, depth)
+			WITH RECURSIVE tree AS (
+				SELECT id AS ancestor_id, id AS descendant_id, 0 AS depth FROM items WHERE deleted_at IS NULL
+				UNION ALL
+				SELECT t.ancestor_id, i.id, t.depth + 1
+				FROM tree t
+				JOIN items i ON i.container_id = t.descendant_id AND i.deleted_at IS NULL
+				WHERE t.depth < ?
+			)
+			SELECT DISTINCT ON (ancestor_id, descendant_id) ancestor_id, descendant_id, depth
+			FROM tree
+			ORDER BY ancestor_id, descendant_id, depth
+		`, maxHierarchyDepth)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		rows = result.RowsAffected
+		return nil
+	})
+
+	return rows, err
+}
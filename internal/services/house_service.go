@@ -6,15 +6,17 @@ import (
 
 	"nookverse/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // HouseService 房屋服务接口
 type HouseService interface {
 	// 房屋基础CRUD操作
-	CreateHouse(ctx context.Context, house *models.House) error
+	CreateHouse(ctx context.Context, house *models.House, familyID string) error
 	GetHouseByID(ctx context.Context, id string) (*models.House, error)
 	UpdateHouse(ctx context.Context, house *models.House) error
 	DeleteHouse(ctx context.Context, id string) error
+	DeleteHouseWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error)
 	ListHouses(ctx context.Context, filters HouseFilters) ([]models.House, int64, error)
 	
 	// 房间管理
@@ -22,6 +24,7 @@ type HouseService interface {
 	GetRoomByID(ctx context.Context, id string) (*models.Room, error)
 	UpdateRoom(ctx context.Context, room *models.Room) error
 	DeleteRoom(ctx context.Context, id string) error
+	DeleteRoomWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error)
 	GetRoomsByHouse(ctx context.Context, houseID string) ([]models.Room, error)
 	
 	// 统计分析
@@ -33,7 +36,7 @@ type HouseService interface {
 
 // HouseFilters 房屋查询过滤条件
 type HouseFilters struct {
-	UserID     *string
+	UserID     *string // 进一步限定为该用户也能访问的房屋
 	Name       *string
 	Address    *string
 	MinArea    *float64
@@ -63,32 +66,63 @@ func NewHouseService(db *gorm.DB) HouseService {
 	return &houseService{db: db}
 }
 
-// CreateHouse 创建房屋
-func (s *houseService) CreateHouse(ctx context.Context, house *models.House) error {
+// CreateHouse 创建房屋并归属到指定家庭（familyID为空时使用用户唯一的家庭）
+func (s *houseService) CreateHouse(ctx context.Context, house *models.House, familyID string) error {
 	if house.Name == "" {
 		return errors.New("房屋名称不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	familyID, err = resolveFamily(ctx, s.db, userID, familyID)
+	if err != nil {
+		return err
+	}
+
 	// 设置默认值
 	if house.FloorCount <= 0 {
 		house.FloorCount = 1
 	}
 
-	return s.db.WithContext(ctx).Create(house).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(house).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO family_houses (family_id, house_id) VALUES (?, ?)", familyID, house.ID).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityHouse,
+			EntityID:    house.ID,
+			HouseID:     &house.ID,
+			Operation:   OperationCreate,
+			Description: "创建房屋: " + house.Name,
+			NewValue:    auditSnapshot(house),
+		})
+	})
 }
 
 // GetHouseByID 根据ID获取房屋
 func (s *houseService) GetHouseByID(ctx context.Context, id string) (*models.House, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var house models.House
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeHouses(userID)).
 		Preload("Rooms").
-		Preload("Rooms.Items").
+		Preload("Rooms.Items", scopeItems(userID)).
 		Preload("Rooms.Items.Category").
 		First(&house, "id = ?", id).Error
 	
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("房屋不存在")
+			return nil, ErrHouseNotFound
 		}
 		return nil, err
 	}
@@ -102,34 +136,68 @@ func (s *houseService) UpdateHouse(ctx context.Context, house *models.House) err
 		return errors.New("房屋ID不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 检查房屋是否存在
 	var existing models.House
-	if err := s.db.First(&existing, "id = ?", house.ID).Error; err != nil {
-		return errors.New("房屋不存在")
+	if err := s.db.WithContext(ctx).Scopes(scopeHouses(userID)).First(&existing, "id = ?", house.ID).Error; err != nil {
+		return ErrHouseNotFound
 	}
 
-	return s.db.WithContext(ctx).Save(house).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 只保存房屋本身，预加载的关联数据不回写
+		if err := tx.Omit(clause.Associations).Save(house).Error; err != nil {
+			return err
+		}
+
+		oldValue, newValue := AuditDiff(&existing, house)
+		if oldValue == nil {
+			return nil
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityHouse,
+			EntityID:    house.ID,
+			HouseID:     &house.ID,
+			Operation:   OperationUpdate,
+			Description: "更新房屋: " + house.Name,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+		})
+	})
 }
 
-// DeleteHouse 删除房屋
+// DeleteHouse 删除房屋，房屋移入回收站；包含房间时拒绝删除
 func (s *houseService) DeleteHouse(ctx context.Context, id string) error {
-	// 检查是否有房间
-	var count int64
-	s.db.Model(&models.Room{}).Where("house_id = ?", id).Count(&count)
-	if count > 0 {
-		return errors.New("该房屋包含房间，不能直接删除")
-	}
+	_, err := s.DeleteHouseWithOptions(ctx, id, DeleteOptions{})
+	return err
+}
 
-	result := s.db.WithContext(ctx).Delete(&models.House{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+// DeleteHouseWithOptions 删除房屋，按选项将房间和物品一并删除，或先将物品移到其他位置后删除房间
+func (s *houseService) DeleteHouseWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	
-	if result.RowsAffected == 0 {
-		return errors.New("房屋不存在")
+
+	// 检查房屋是否存在
+	var house models.House
+	if err := s.db.WithContext(ctx).Scopes(scopeHouses(userID)).First(&house, "id = ?", id).Error; err != nil {
+		return nil, ErrHouseNotFound
 	}
-	
-	return nil
+
+	var summary *DeleteSummary
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		plan, err := loadHousePlan(tx, userID, &house)
+		if err != nil {
+			return err
+		}
+		summary, err = plan.execute(tx, opts)
+		return err
+	})
+	return summary, err
 }
 
 // ListHouses 列出房屋
@@ -137,11 +205,16 @@ func (s *houseService) ListHouses(ctx context.Context, filters HouseFilters) ([]
 	var houses []models.House
 	var total int64
 
-	query := s.db.WithContext(ctx).Model(&models.House{})
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// 应用过滤条件
+	query := s.db.WithContext(ctx).Model(&models.House{}).Scopes(scopeHouses(userID))
+
+	// 指定用户时只保留当前用户与该用户都能访问的房屋，不能借此扩大访问范围
 	if filters.UserID != nil {
-		// TODO: 根据用户权限过滤
+		query = query.Scopes(scopeHouses(*filters.UserID))
 	}
 	
 	if filters.Name != nil {
@@ -191,7 +264,7 @@ func (s *houseService) ListHouses(ctx context.Context, filters HouseFilters) ([]
 	query = query.Order(orderBy)
 
 	// 预加载房间数据
-	err := query.Preload("Rooms").Find(&houses).Error
+	err = query.Preload("Rooms").Find(&houses).Error
 	
 	return houses, total, err
 }
@@ -206,10 +279,15 @@ func (s *houseService) CreateRoom(ctx context.Context, room *models.Room) error
 		return errors.New("房屋ID不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 验证房屋存在
 	var house models.House
-	if err := s.db.First(&house, "id = ?", room.HouseID).Error; err != nil {
-		return errors.New("指定的房屋不存在")
+	if err := s.db.WithContext(ctx).Scopes(scopeHouses(userID)).First(&house, "id = ?", room.HouseID).Error; err != nil {
+		return ErrHouseNotFound
 	}
 
 	// 设置默认值
@@ -221,21 +299,39 @@ func (s *houseService) CreateRoom(ctx context.Context, room *models.Room) error
 		room.RoomType = "other"
 	}
 
-	return s.db.WithContext(ctx).Create(room).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(room).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityRoom,
+			EntityID:    room.ID,
+			HouseID:     &room.HouseID,
+			Operation:   OperationCreate,
+			Description: "创建房间: " + room.Name,
+			NewValue:    auditSnapshot(room),
+		})
+	})
 }
 
 // GetRoomByID 根据ID获取房间
 func (s *houseService) GetRoomByID(ctx context.Context, id string) (*models.Room, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var room models.Room
-	err := s.db.WithContext(ctx).
-		Preload("Items").
+	err = s.db.WithContext(ctx).
+		Scopes(scopeRooms(userID)).
+		Preload("Items", scopeItems(userID)).
 		Preload("Items.Category").
 		Preload("Items.MediaFiles").
 		First(&room, "id = ?", id).Error
 	
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("房间不存在")
+			return nil, ErrRoomNotFound
 		}
 		return nil, err
 	}
@@ -249,42 +345,91 @@ func (s *houseService) UpdateRoom(ctx context.Context, room *models.Room) error
 		return errors.New("房间ID不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 检查房间是否存在
 	var existing models.Room
-	if err := s.db.First(&existing, "id = ?", room.ID).Error; err != nil {
-		return errors.New("房间不存在")
+	if err := s.db.WithContext(ctx).Scopes(scopeRooms(userID)).First(&existing, "id = ?", room.ID).Error; err != nil {
+		return ErrRoomNotFound
 	}
 
-	return s.db.WithContext(ctx).Save(room).Error
+	// 房间只能在当前用户可访问的房屋之间调整
+	if room.HouseID != existing.HouseID {
+		var count int64
+		s.db.WithContext(ctx).Model(&models.House{}).Scopes(scopeHouses(userID)).Where("id = ?", room.HouseID).Count(&count)
+		if count == 0 {
+			return ErrHouseNotFound
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 只保存房间本身，预加载的关联数据不回写
+		if err := tx.Omit(clause.Associations).Save(room).Error; err != nil {
+			return err
+		}
+
+		oldValue, newValue := AuditDiff(&existing, room)
+		if oldValue == nil {
+			return nil
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityRoom,
+			EntityID:    room.ID,
+			HouseID:     &room.HouseID,
+			Operation:   OperationUpdate,
+			Description: "更新房间: " + room.Name,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+		})
+	})
 }
 
-// DeleteRoom 删除房间
+// DeleteRoom 删除房间，房间移入回收站；包含物品时拒绝删除
 func (s *houseService) DeleteRoom(ctx context.Context, id string) error {
-	// 检查是否有物品
-	var count int64
-	s.db.Model(&models.Item{}).Where("room_id = ?", id).Count(&count)
-	if count > 0 {
-		return errors.New("该房间包含物品，不能直接删除")
-	}
+	_, err := s.DeleteRoomWithOptions(ctx, id, DeleteOptions{})
+	return err
+}
 
-	result := s.db.WithContext(ctx).Delete(&models.Room{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+// DeleteRoomWithOptions 删除房间，按选项将房间内的物品一并删除或先移到其他位置
+func (s *houseService) DeleteRoomWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	
-	if result.RowsAffected == 0 {
-		return errors.New("房间不存在")
+
+	// 检查房间是否存在
+	var room models.Room
+	if err := s.db.WithContext(ctx).Scopes(scopeRooms(userID)).First(&room, "id = ?", id).Error; err != nil {
+		return nil, ErrRoomNotFound
 	}
-	
-	return nil
+
+	var summary *DeleteSummary
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		plan, err := loadRoomPlan(tx, userID, &room)
+		if err != nil {
+			return err
+		}
+		summary, err = plan.execute(tx, opts)
+		return err
+	})
+	return summary, err
 }
 
 // GetRoomsByHouse 获取房屋内房间
 func (s *houseService) GetRoomsByHouse(ctx context.Context, houseID string) ([]models.Room, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var rooms []models.Room
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeRooms(userID)).
 		Where("house_id = ?", houseID).
-		Preload("Items").
+		Preload("Items", scopeItems(userID)).
 		Preload("Items.Category").
 		Order("floor_number, name").
 		Find(&rooms).Error
@@ -294,21 +439,27 @@ func (s *houseService) GetRoomsByHouse(ctx context.Context, houseID string) ([]m
 
 // GetHouseStatistics 获取房屋统计信息
 func (s *houseService) GetHouseStatistics(ctx context.Context) (*HouseStatistics, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	stats := &HouseStatistics{
 		ByFloorCount: make(map[int]int64),
 		ByRoomType:   make(map[string]int64),
 	}
 
 	// 获取房屋总数
-	s.db.WithContext(ctx).Model(&models.House{}).Count(&stats.TotalHouses)
+	s.db.WithContext(ctx).Model(&models.House{}).Scopes(scopeHouses(userID)).Count(&stats.TotalHouses)
 
 	// 获取房间总数
-	s.db.WithContext(ctx).Model(&models.Room{}).Count(&stats.TotalRooms)
+	s.db.WithContext(ctx).Model(&models.Room{}).Scopes(scopeRooms(userID)).Count(&stats.TotalRooms)
 
 	// 计算平均面积
 	var avgArea float64
 	s.db.WithContext(ctx).
 		Model(&models.House{}).
+		Scopes(scopeHouses(userID)).
 		Select("COALESCE(AVG(area), 0)").
 		Scan(&avgArea)
 	stats.AverageArea = avgArea
@@ -316,6 +467,7 @@ func (s *houseService) GetHouseStatistics(ctx context.Context) (*HouseStatistics
 	// 按楼层数统计
 	s.db.WithContext(ctx).
 		Model(&models.House{}).
+		Scopes(scopeHouses(userID)).
 		Select("floor_count, count(*)").
 		Group("floor_count").
 		Scan(&stats.ByFloorCount)
@@ -323,6 +475,7 @@ func (s *houseService) GetHouseStatistics(ctx context.Context) (*HouseStatistics
 	// 按房间类型统计
 	s.db.WithContext(ctx).
 		Model(&models.Room{}).
+		Scopes(scopeRooms(userID)).
 		Select("room_type, count(*)").
 		Group("room_type").
 		Scan(&stats.ByRoomType)
@@ -335,10 +488,15 @@ func (s *houseService) SearchHouses(ctx context.Context, query string, filters H
 	var houses []models.House
 	var total int64
 
-	dbQuery := s.db.WithContext(ctx).Model(&models.House{})
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dbQuery := s.db.WithContext(ctx).Model(&models.House{}).Scopes(scopeHouses(userID))
 
 	// 构建搜索条件
-	searchCondition := s.db.Where(
+	searchCondition := s.db.WithContext(ctx).Scopes(scopeHouses(userID)).Where(
 		s.db.Where("name ILIKE ?", "%"+query+"%").
 			Or("address ILIKE ?", "%"+query+"%").
 			Or("description ILIKE ?", "%"+query+"%"),
@@ -378,7 +536,7 @@ func (s *houseService) SearchHouses(ctx context.Context, query string, filters H
 	searchCondition = searchCondition.Offset(offset).Limit(filters.PageSize)
 
 	// 执行查询
-	err := searchCondition.Preload("Rooms").Find(&houses).Error
+	err = searchCondition.Preload("Rooms").Find(&houses).Error
 	
 	return houses, total, err
 }
\ No newline at end of file
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+
+	"nookverse/internal/models"
+)
+
+// 位置路径节点类型
+const (
+	LocationHouse = "house"
+	LocationRoom  = "room"
+	LocationItem  = "item"
+)
+
+// LocationNode 物品位置路径中的一个节点
+type LocationNode struct {
+	Type string `json:"type"` // house, room, item
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// locationAncestor 闭包表中的祖先记录
+type locationAncestor struct {
+	DescendantID string
+	AncestorID   string
+	Name         string
+	Depth        int
+}
+
+// GetLocationPaths 批量计算物品的完整位置路径：房屋 → 房间 → 容器链 → 物品本身
+// 对当前用户不可见的私有容器不会出现在路径中
+func (s *itemService) GetLocationPaths(ctx context.Context, items []models.Item) (map[string][]LocationNode, error) {
+	paths := make(map[string][]LocationNode, len(items))
+	if len(items) == 0 {
+		return paths, nil
+	}
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	itemIDs := make([]string, 0, len(items))
+	roomIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		itemIDs = append(itemIDs, item.ID)
+		if item.RoomID != nil {
+			roomIDs = append(roomIDs, *item.RoomID)
+		}
+	}
+
+	// 房间及所属房屋
+	var rooms []models.Room
+	if err := s.db.WithContext(ctx).Select("id", "house_id", "name").Where("id IN ?", roomIDs).Find(&rooms).Error; err != nil {
+		return nil, err
+	}
+	roomByID := make(map[string]models.Room, len(rooms))
+	houseIDs := make([]string, 0, len(rooms))
+	for _, room := range rooms {
+		roomByID[room.ID] = room
+		houseIDs = append(houseIDs, room.HouseID)
+	}
+
+	var houses []models.House
+	if err := s.db.WithContext(ctx).Select("id", "name").Where("id IN ?", houseIDs).Find(&houses).Error; err != nil {
+		return nil, err
+	}
+	houseByID := make(map[string]models.House, len(houses))
+	for _, house := range houses {
+		houseByID[house.ID] = house
+	}
+
+	// 容器链，按距离由远及近
+	var ancestors []locationAncestor
+	err = s.db.WithContext(ctx).
+		Table("item_hierarchy ih").
+		Select("ih.descendant_id, ih.ancestor_id, i.name, ih.depth").
+		Joins("JOIN items i ON i.id = ih.ancestor_id").
+		Where("ih.descendant_id IN ? AND ih.depth > 0", itemIDs).
+		Where("i.id IN ("+accessibleItemsSQL+")", sql.Named("uid", userID)).
+		Order("ih.descendant_id, ih.depth DESC").
+		Scan(&ancestors).Error
+	if err != nil {
+		return nil, err
+	}
+	containers := make(map[string][]LocationNode)
+	for _, ancestor := range ancestors {
+		containers[ancestor.DescendantID] = append(containers[ancestor.DescendantID],
+			LocationNode{Type: LocationItem, ID: ancestor.AncestorID, Name: ancestor.Name})
+	}
+
+	for _, item := range items {
+		var path []LocationNode
+		if item.RoomID != nil {
+			if room, ok := roomByID[*item.RoomID]; ok {
+				if house, ok := houseByID[room.HouseID]; ok {
+					path = append(path, LocationNode{Type: LocationHouse, ID: house.ID, Name: house.Name})
+				}
+				path = append(path, LocationNode{Type: LocationRoom, ID: room.ID, Name: room.Name})
+			}
+		}
+		path = append(path, containers[item.ID]...)
+		path = append(path, LocationNode{Type: LocationItem, ID: item.ID, Name: item.Name})
+		paths[item.ID] = path
+	}
+
+	return paths, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"nookverse/internal/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ItemService 物品服务接口
@@ -16,31 +17,42 @@ type ItemService interface {
 	GetItemByID(ctx context.Context, id string) (*models.Item, error)
 	UpdateItem(ctx context.Context, item *models.Item) error
 	DeleteItem(ctx context.Context, id string) error
+	DeleteItemWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error)
 	
 	// 查询操作
 	ListItems(ctx context.Context, filters ItemFilters) ([]models.Item, int64, error)
-	SearchItems(ctx context.Context, query string, filters ItemFilters) ([]models.Item, int64, error)
+	SearchItems(ctx context.Context, query string, filters ItemFilters) ([]ItemSearchHit, int64, error)
 	GetItemsByRoom(ctx context.Context, roomID string) ([]models.Item, error)
-	GetItemsByCategory(ctx context.Context, categoryID string) ([]models.Item, error)
+	GetItemsByCategory(ctx context.Context, categoryID string, includeDescendants bool) ([]models.Item, error)
 	
 	// 层级管理
 	GetItemHierarchy(ctx context.Context, itemID string) ([]models.Item, error)
-	MoveItemToContainer(ctx context.Context, itemID, containerID string) error
+	MoveItem(ctx context.Context, itemID string, target MoveTarget) error
 	GetContainerItems(ctx context.Context, containerID string) ([]models.Item, error)
+	GetItemTree(ctx context.Context, itemID string, depth int) (*ItemTreeNode, error)
+	GetLocationPaths(ctx context.Context, items []models.Item) (map[string][]LocationNode, error)
 	
+	// 版本历史
+	ListItemVersions(ctx context.Context, itemID string, page, pageSize int) ([]models.ItemVersion, int64, error)
+	GetItemVersion(ctx context.Context, itemID string, version int) (*models.ItemVersion, error)
+	GetItemAsOf(ctx context.Context, itemID string, at time.Time) (*models.ItemVersion, error)
+	DiffItemVersions(ctx context.Context, itemID string, from, to int) (*ItemVersionDiff, error)
+	RestoreItemVersion(ctx context.Context, itemID string, version int) (*models.Item, error)
+
 	// 提醒管理
 	CreateReminder(ctx context.Context, reminder *models.Reminder) error
 	GetUpcomingReminders(ctx context.Context, days int) ([]models.Reminder, error)
 	
 	// 统计分析
-	GetItemStatistics(ctx context.Context, userID string) (*ItemStatistics, error)
+	GetItemStatistics(ctx context.Context, userID string, filters ItemFilters) (*ItemStatistics, error)
 }
 
 // ItemFilters 物品查询过滤条件
 type ItemFilters struct {
-	UserID      *string
+	UserID      *string // 进一步限定为该用户也能看到的物品
 	RoomID      *string
 	CategoryID  *string
+	IncludeDescendants bool // 分类过滤时包含全部后代分类
 	Status      *string
 	Labels      []string
 	ExpireDate  *time.Time
@@ -51,6 +63,12 @@ type ItemFilters struct {
 	OrderBy     string
 }
 
+// MoveTarget 物品移动目标，容器和房间必须且只能指定一个
+type MoveTarget struct {
+	ContainerID *string
+	RoomID      *string
+}
+
 // ItemStatistics 物品统计信息
 type ItemStatistics struct {
 	TotalItems     int64            `json:"total_items"`
@@ -61,13 +79,21 @@ type ItemStatistics struct {
 	LowStockItems  int64            `json:"low_stock_items"` // 数量小于等于1的物品
 }
 
+// ItemSearchHit 物品搜索结果
+type ItemSearchHit struct {
+	Item    models.Item
+	Rank    float64 // 相关度，仅按名称子串匹配时为0
+	Snippet string  // 匹配内容片段，已做HTML转义，匹配词以 <mark></mark> 标出
+}
+
 type itemService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	notifiers *NotifierRegistry
 }
 
-// NewItemService 创建物品服务实例
-func NewItemService(db *gorm.DB) ItemService {
-	return &itemService{db: db}
+// NewItemService 创建物品服务实例，notifiers 用于校验提醒的通知渠道
+func NewItemService(db *gorm.DB, notifiers *NotifierRegistry) ItemService {
+	return &itemService{db: db, notifiers: notifiers}
 }
 
 // CreateItem 创建物品
@@ -76,19 +102,18 @@ func (s *itemService) CreateItem(ctx context.Context, item *models.Item) error {
 		return errors.New("物品名称不能为空")
 	}
 
-	// 验证关联关系
-	if item.RoomID != nil {
-		var room models.Room
-		if err := s.db.First(&room, "id = ?", *item.RoomID).Error; err != nil {
-			return errors.New("指定的房间不存在")
-		}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
 	}
 
-	if item.CategoryID != nil {
-		var category models.Category
-		if err := s.db.First(&category, "id = ?", *item.CategoryID).Error; err != nil {
-			return errors.New("指定的分类不存在")
-		}
+	// 验证关联关系，物品必须位于当前用户可访问的房间或容器中
+	if err := s.validateLocation(ctx, userID, item); err != nil {
+		return err
+	}
+
+	if err := s.validateCategory(ctx, userID, item.CategoryID); err != nil {
+		return err
 	}
 
 	// 设置默认值
@@ -99,23 +124,99 @@ func (s *itemService) CreateItem(ctx context.Context, item *models.Item) error {
 		item.Status = "active"
 	}
 
-	return s.db.WithContext(ctx).Create(item).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		if err := insertItemHierarchy(tx, item.ID, item.ContainerID); err != nil {
+			return err
+		}
+		if err := syncPolicyReminders(tx, item.ID); err != nil {
+			return err
+		}
+		if err := syncSearchIndex(tx, item.ID); err != nil {
+			return err
+		}
+		if err := recordItemVersion(tx, item.ID, OperationCreate); err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityItem,
+			EntityID:    item.ID,
+			ItemID:      &item.ID,
+			HouseID:     roomHouseID(tx, item.RoomID),
+			Operation:   OperationCreate,
+			Description: "创建物品: " + item.Name,
+			NewValue:    auditSnapshot(item),
+		})
+	})
+}
+
+// validateLocation 校验物品所在的房间和容器属于当前用户，放入容器时继承容器所在房间
+func (s *itemService) validateLocation(ctx context.Context, userID string, item *models.Item) error {
+	if item.ContainerID != nil {
+		var container models.Item
+		if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&container, "id = ?", *item.ContainerID).Error; err != nil {
+			return notFoundError("指定的容器不存在")
+		}
+		item.RoomID = container.RoomID
+	}
+
+	if item.RoomID == nil {
+		return errors.New("物品必须位于房间或容器中")
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Room{}).Scopes(scopeRooms(userID)).Where("id = ?", *item.RoomID).Count(&count)
+	if count == 0 {
+		return notFoundError("指定的房间不存在")
+	}
+
+	return nil
+}
+
+// validateCategory 校验物品的分类对当前用户可见
+func (s *itemService) validateCategory(ctx context.Context, userID string, categoryID *string) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Category{}).Scopes(scopeCategories(userID)).Where("id = ?", *categoryID).Count(&count)
+	if count == 0 {
+		return notFoundError("指定的分类不存在")
+	}
+	return nil
+}
+
+// SameID 判断两个可选ID是否相同
+func SameID(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
 
 // GetItemByID 根据ID获取物品
 func (s *itemService) GetItemByID(ctx context.Context, id string) (*models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var item models.Item
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeItems(userID)).
 		Preload("Category").
 		Preload("Room").
 		Preload("Container").
-		Preload("MediaFiles").
+		Preload("MediaFiles", orderMediaFiles).
 		Preload("Reminders").
 		First(&item, "id = ?", id).Error
 	
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("物品不存在")
+			return nil, ErrItemNotFound
 		}
 		return nil, err
 	}
@@ -125,38 +226,128 @@ func (s *itemService) GetItemByID(ctx context.Context, id string) (*models.Item,
 
 // UpdateItem 更新物品
 func (s *itemService) UpdateItem(ctx context.Context, item *models.Item) error {
+	return s.updateItem(ctx, item, OperationUpdate, "更新物品: "+item.Name)
+}
+
+// updateItem 保存物品的全部字段，有变化时记录操作日志和新版本
+func (s *itemService) updateItem(ctx context.Context, item *models.Item, operation, description string) error {
 	if item.ID == "" {
 		return errors.New("物品ID不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 检查物品是否存在
 	var existing models.Item
-	if err := s.db.First(&existing, "id = ?", item.ID).Error; err != nil {
-		return errors.New("物品不存在")
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&existing, "id = ?", item.ID).Error; err != nil {
+		return ErrItemNotFound
+	}
+
+	if err := s.validateLocation(ctx, userID, item); err != nil {
+		return err
 	}
 
-	return s.db.WithContext(ctx).Save(item).Error
+	if !SameID(existing.CategoryID, item.CategoryID) {
+		if err := s.validateCategory(ctx, userID, item.CategoryID); err != nil {
+			return err
+		}
+	}
+
+	containerChanged := !SameID(existing.ContainerID, item.ContainerID)
+	roomChanged := !SameID(existing.RoomID, item.RoomID)
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if containerChanged && item.ContainerID != nil {
+			if err := checkHierarchyCycle(tx, item.ID, *item.ContainerID); err != nil {
+				return err
+			}
+		}
+
+		// 有字段变化时先为没有版本记录的物品补记修改前的数据
+		oldValue, newValue := AuditDiff(&existing, item)
+		if oldValue != nil {
+			if err := recordItemBaseline(tx, item.ID); err != nil {
+				return err
+			}
+		}
+
+		// 只保存物品本身，预加载的关联数据不回写
+		if err := tx.Omit(clause.Associations).Save(item).Error; err != nil {
+			return err
+		}
+
+		if containerChanged {
+			if err := moveItemHierarchy(tx, item.ID, item.ContainerID); err != nil {
+				return err
+			}
+		}
+
+		// 容器内的物品始终与容器位于同一房间，移动到其他家庭的房屋后按新家庭的策略重建自动提醒
+		if roomChanged {
+			if err := cascadeItemRoom(tx, item.ID, item.RoomID); err != nil {
+				return err
+			}
+			if err := syncSubtreeReminders(tx, item.ID); err != nil {
+				return err
+			}
+		} else if err := syncPolicyReminders(tx, item.ID); err != nil {
+			return err
+		}
+
+		if oldValue == nil {
+			return nil
+		}
+		if err := syncSearchIndex(tx, item.ID); err != nil {
+			return err
+		}
+		if err := recordItemVersion(tx, item.ID, operation); err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityItem,
+			EntityID:    item.ID,
+			ItemID:      &item.ID,
+			HouseID:     roomHouseID(tx, item.RoomID),
+			Operation:   operation,
+			Description: description,
+			OldValue:    oldValue,
+			NewValue:    newValue,
+		})
+	})
 }
 
-// DeleteItem 删除物品
+// DeleteItem 删除物品，物品移入回收站；包含其他物品时拒绝删除
 func (s *itemService) DeleteItem(ctx context.Context, id string) error {
-	// 检查是否有子物品
-	var count int64
-	s.db.Model(&models.Item{}).Where("container_id = ?", id).Count(&count)
-	if count > 0 {
-		return errors.New("该物品包含其他物品，不能直接删除")
-	}
+	_, err := s.DeleteItemWithOptions(ctx, id, DeleteOptions{})
+	return err
+}
 
-	result := s.db.WithContext(ctx).Delete(&models.Item{}, "id = ?", id)
-	if result.Error != nil {
-		return result.Error
+// DeleteItemWithOptions 删除物品，按选项将容器内的物品一并删除或先移到其他位置
+func (s *itemService) DeleteItemWithOptions(ctx context.Context, id string, opts DeleteOptions) (*DeleteSummary, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
 	}
-	
-	if result.RowsAffected == 0 {
-		return errors.New("物品不存在")
+
+	// 检查物品是否存在
+	var existing models.Item
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&existing, "id = ?", id).Error; err != nil {
+		return nil, ErrItemNotFound
 	}
-	
-	return nil
+
+	var summary *DeleteSummary
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		plan, err := loadContainerPlan(tx, userID, &existing)
+		if err != nil {
+			return err
+		}
+		summary, err = plan.execute(tx, opts)
+		return err
+	})
+	return summary, err
 }
 
 // ListItems 列出物品
@@ -164,11 +355,16 @@ func (s *itemService) ListItems(ctx context.Context, filters ItemFilters) ([]mod
 	var items []models.Item
 	var total int64
 
-	query := s.db.WithContext(ctx).Model(&models.Item{})
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Item{}).Scopes(scopeItems(userID))
 
-	// 应用过滤条件
+	// 指定用户时只保留当前用户与该用户都能看到的物品，不能借此扩大访问范围
 	if filters.UserID != nil {
-		// TODO: 根据用户权限过滤
+		query = query.Scopes(scopeItems(*filters.UserID))
 	}
 	
 	if filters.RoomID != nil {
@@ -176,7 +372,7 @@ func (s *itemService) ListItems(ctx context.Context, filters ItemFilters) ([]mod
 	}
 	
 	if filters.CategoryID != nil {
-		query = query.Where("category_id = ?", *filters.CategoryID)
+		query = whereCategory(query, *filters.CategoryID, filters.IncludeDescendants)
 	}
 	
 	if filters.Status != nil {
@@ -224,7 +420,7 @@ func (s *itemService) ListItems(ctx context.Context, filters ItemFilters) ([]mod
 	query = query.Order(orderBy)
 
 	// 预加载关联数据
-	err := query.
+	err = query.
 		Preload("Category").
 		Preload("Room").
 		Preload("Container").
@@ -233,36 +429,38 @@ func (s *itemService) ListItems(ctx context.Context, filters ItemFilters) ([]mod
 	return items, total, err
 }
 
-// SearchItems 搜索物品
-func (s *itemService) SearchItems(ctx context.Context, query string, filters ItemFilters) ([]models.Item, int64, error) {
-	var items []models.Item
+// SearchItems 全文搜索物品，按相关度排序。关键词使用 websearch 语法：空格分隔的词都需匹配，
+// 支持 "短语"、or 和 -排除；'simple' 分词不切分连续的中文，名称另按子串匹配
+func (s *itemService) SearchItems(ctx context.Context, query string, filters ItemFilters) ([]ItemSearchHit, int64, error) {
 	var total int64
 
-	dbQuery := s.db.WithContext(ctx).Model(&models.Item{})
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// 构建搜索条件
-	searchCondition := s.db.Where(
-		s.db.Where("name ILIKE ?", "%"+query+"%").
-			Or("description ILIKE ?", "%"+query+"%").
-			Or("brand ILIKE ?", "%"+query+"%").
-			Or("model ILIKE ?", "%"+query+"%"),
-	)
+	// 构建搜索条件，尚未建立索引的物品仍可按名称匹配
+	searchQuery := func() *gorm.DB {
+		q := s.db.WithContext(ctx).Model(&models.Item{}).
+			Scopes(scopeItems(userID)).
+			Joins("LEFT JOIN search_index ON search_index.item_id = items.id").
+			Where("search_index.searchable_content @@ websearch_to_tsquery('simple', ?) OR items.name ILIKE ?", query, "%"+query+"%")
 
-	// 应用过滤条件
-	if filters.RoomID != nil {
-		searchCondition = searchCondition.Where("room_id = ?", *filters.RoomID)
-	}
-	
-	if filters.CategoryID != nil {
-		searchCondition = searchCondition.Where("category_id = ?", *filters.CategoryID)
-	}
-	
-	if filters.Status != nil {
-		searchCondition = searchCondition.Where("status = ?", *filters.Status)
+		// 应用过滤条件
+		if filters.RoomID != nil {
+			q = q.Where("items.room_id = ?", *filters.RoomID)
+		}
+		if filters.CategoryID != nil {
+			q = whereCategory(q, *filters.CategoryID, filters.IncludeDescendants)
+		}
+		if filters.Status != nil {
+			q = q.Where("items.status = ?", *filters.Status)
+		}
+		return q
 	}
 
 	// 获取总数
-	if err := dbQuery.Where(searchCondition).Count(&total).Error; err != nil {
+	if err := searchQuery().Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -273,27 +471,61 @@ func (s *itemService) SearchItems(ctx context.Context, query string, filters Ite
 	if filters.PageSize <= 0 {
 		filters.PageSize = 20
 	}
-	
 	offset := (filters.Page - 1) * filters.PageSize
-	searchCondition = searchCondition.Offset(offset).Limit(filters.PageSize)
 
-	// 执行查询
-	err := searchCondition.
+	// 按相关度取当前页的物品及其高亮片段
+	var rows []searchMatch
+	err = searchQuery().
+		Select("items.id, COALESCE(ts_rank(search_index.searchable_content, websearch_to_tsquery('simple', ?)), 0) AS rank, "+
+			"ts_headline('simple', "+searchTextSQL+", websearch_to_tsquery('simple', ?), "+snippetOptionsSQL+") AS snippet", query, query).
+		Order("rank DESC, items.name").
+		Offset(offset).Limit(filters.PageSize).
+		Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, total, err
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var items []models.Item
+	err = s.db.WithContext(ctx).
+		Where("id IN ?", ids).
 		Preload("Category").
 		Preload("Room").
 		Preload("Container").
 		Find(&items).Error
-	
-	return items, total, err
+	if err != nil {
+		return nil, 0, err
+	}
+
+	byID := make(map[string]models.Item, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	hits := make([]ItemSearchHit, 0, len(rows))
+	for _, row := range rows {
+		if item, ok := byID[row.ID]; ok {
+			hits = append(hits, ItemSearchHit{Item: item, Rank: row.Rank, Snippet: highlightSnippet(row.Snippet)})
+		}
+	}
+	return hits, total, nil
 }
 
 // GetItemsByRoom 获取房间内物品
 func (s *itemService) GetItemsByRoom(ctx context.Context, roomID string) ([]models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []models.Item
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeItems(userID)).
 		Where("room_id = ? AND container_id IS NULL", roomID).
 		Preload("Category").
-		Preload("MediaFiles").
+		Preload("MediaFiles", orderMediaFiles).
 		Order("name").
 		Find(&items).Error
 	
@@ -301,10 +533,14 @@ func (s *itemService) GetItemsByRoom(ctx context.Context, roomID string) ([]mode
 }
 
 // GetItemsByCategory 获取分类下物品
-func (s *itemService) GetItemsByCategory(ctx context.Context, categoryID string) ([]models.Item, error) {
+func (s *itemService) GetItemsByCategory(ctx context.Context, categoryID string, includeDescendants bool) ([]models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []models.Item
-	err := s.db.WithContext(ctx).
-		Where("category_id = ?", categoryID).
+	err = whereCategory(s.db.WithContext(ctx).Scopes(scopeItems(userID)), categoryID, includeDescendants).
 		Preload("Room").
 		Preload("Category").
 		Order("name").
@@ -315,14 +551,25 @@ func (s *itemService) GetItemsByCategory(ctx context.Context, categoryID string)
 
 // GetItemHierarchy 获取物品层级关系
 func (s *itemService) GetItemHierarchy(ctx context.Context, itemID string) ([]models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 祖先与物品属于同一家庭，只需校验物品本身
+	var item models.Item
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&item, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
 	var ancestors []models.Item
 	
 	// 查询祖先节点
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
 		Raw(`
 			SELECT i.* FROM items i
 			INNER JOIN item_hierarchy ih ON i.id = ih.ancestor_id
-			WHERE ih.descendant_id = ?
+			WHERE ih.descendant_id = ? AND ih.depth > 0
 			ORDER BY ih.depth DESC
 		`, itemID).
 		Scan(&ancestors).Error
@@ -330,42 +577,113 @@ func (s *itemService) GetItemHierarchy(ctx context.Context, itemID string) ([]mo
 	return ancestors, err
 }
 
-// MoveItemToContainer 移动物品到容器
-func (s *itemService) MoveItemToContainer(ctx context.Context, itemID, containerID string) error {
-	// 验证容器存在且不是自己
-	if itemID == containerID {
-		return errors.New("不能将物品移动到自身")
+// MoveItem 移动物品到目标容器或房间，物品及其全部后代的房间同步为目标所在房间
+func (s *itemService) MoveItem(ctx context.Context, itemID string, target MoveTarget) error {
+	if (target.ContainerID == nil) == (target.RoomID == nil) {
+		return errors.New("必须且只能指定目标容器或目标房间之一")
+	}
+
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var item models.Item
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&item, "id = ?", itemID).Error; err != nil {
+		return ErrItemNotFound
+	}
+
+	roomID, err := resolveMoveTarget(s.db.WithContext(ctx), userID, target)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return moveItemTo(tx, &item, target, roomID)
+	})
+}
+
+// resolveMoveTarget 校验目标位于当前用户可访问的房屋内，返回物品移动后所在的房间
+func resolveMoveTarget(db *gorm.DB, userID string, target MoveTarget) (*string, error) {
+	if target.ContainerID != nil {
+		var container models.Item
+		if err := db.Scopes(scopeItems(userID)).First(&container, "id = ?", *target.ContainerID).Error; err != nil {
+			return nil, notFoundError("目标容器不存在")
+		}
+		if container.RoomID == nil {
+			return nil, errors.New("目标容器不在任何房间中")
+		}
+		return container.RoomID, nil
 	}
 
-	var container models.Item
-	if err := s.db.First(&container, "id = ?", containerID).Error; err != nil {
-		return errors.New("目标容器不存在")
+	var room models.Room
+	if err := db.Scopes(scopeRooms(userID)).First(&room, "id = ?", *target.RoomID).Error; err != nil {
+		return nil, notFoundError("目标房间不存在")
 	}
+	return &room.ID, nil
+}
 
+// moveItemTo 在事务中将物品移到目标位置，同步闭包表、后代房间和自动提醒，并记录版本和日志
+func moveItemTo(tx *gorm.DB, item *models.Item, target MoveTarget, roomID *string) error {
 	// 检查是否会形成循环引用
-	var count int64
-	s.db.Model(&models.ItemHierarchy{}).
-		Where("ancestor_id = ? AND descendant_id = ?", itemID, containerID).
-		Count(&count)
-	
-	if count > 0 {
-		return errors.New("不能形成循环引用")
+	if target.ContainerID != nil {
+		if err := checkHierarchyCycle(tx, item.ID, *target.ContainerID); err != nil {
+			return err
+		}
+	}
+
+	if err := recordItemBaseline(tx, item.ID); err != nil {
+		return err
+	}
+
+	// 更新物品位置并同步闭包表和后代房间
+	err := tx.Model(&models.Item{}).
+		Where("id = ?", item.ID).
+		Updates(map[string]any{"container_id": target.ContainerID, "room_id": roomID}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := moveItemHierarchy(tx, item.ID, target.ContainerID); err != nil {
+		return err
+	}
+
+	if err := cascadeItemRoom(tx, item.ID, roomID); err != nil {
+		return err
+	}
+
+	if err := syncSubtreeReminders(tx, item.ID); err != nil {
+		return err
 	}
 
-	// 更新物品的容器ID
-	return s.db.WithContext(ctx).
-		Model(&models.Item{}).
-		Where("id = ?", itemID).
-		Update("container_id", containerID).Error
+	if err := recordItemVersion(tx, item.ID, OperationMove); err != nil {
+		return err
+	}
+	return recordOperation(tx, operationEntry{
+		EntityType:  EntityItem,
+		EntityID:    item.ID,
+		ItemID:      &item.ID,
+		HouseID:     roomHouseID(tx, roomID),
+		Operation:   OperationMove,
+		Description: "移动物品: " + item.Name,
+		OldValue:    map[string]any{"room_id": item.RoomID, "container_id": item.ContainerID},
+		NewValue:    map[string]any{"room_id": roomID, "container_id": target.ContainerID},
+	})
 }
 
 // GetContainerItems 获取容器内物品
 func (s *itemService) GetContainerItems(ctx context.Context, containerID string) ([]models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var items []models.Item
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeItems(userID)).
 		Where("container_id = ?", containerID).
 		Preload("Category").
-		Preload("MediaFiles").
+		Preload("MediaFiles", orderMediaFiles).
 		Order("name").
 		Find(&items).Error
 	
@@ -378,10 +696,15 @@ func (s *itemService) CreateReminder(ctx context.Context, reminder *models.Remin
 		return errors.New("物品ID不能为空")
 	}
 
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
 	// 验证物品存在
 	var item models.Item
-	if err := s.db.First(&item, "id = ?", reminder.ItemID).Error; err != nil {
-		return errors.New("物品不存在")
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&item, "id = ?", reminder.ItemID).Error; err != nil {
+		return ErrItemNotFound
 	}
 
 	// 验证提醒时间
@@ -389,22 +712,59 @@ func (s *itemService) CreateReminder(ctx context.Context, reminder *models.Remin
 		return errors.New("提醒时间不能早于当前时间")
 	}
 
+	// 重复规则以首次提醒时间为起点，保存规范化后的规则文本
+	if reminder.Recurrence != nil {
+		normalized, err := parseRecurrence(reminder.ReminderType, *reminder.Recurrence)
+		if err != nil {
+			return err
+		}
+		start := reminder.TriggerTime
+		reminder.Recurrence = &normalized
+		reminder.RecurrenceStart = &start
+		reminder.OccurrenceAt = &start
+	}
+
+	// 拒绝未注册的通知渠道，避免提醒保存后永远无法发送
+	reminder.NotifyChannels = normalizeNotifyChannels(reminder.NotifyChannels)
+	if err := s.notifiers.Validate(reminder.NotifyChannels); err != nil {
+		return err
+	}
+
 	if reminder.Status == "" {
-		reminder.Status = "pending"
+		reminder.Status = ReminderStatusPending
 	}
 
-	return s.db.WithContext(ctx).Create(reminder).Error
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(reminder).Error; err != nil {
+			return err
+		}
+		return recordOperation(tx, operationEntry{
+			EntityType:  EntityReminder,
+			EntityID:    reminder.ID,
+			ItemID:      &reminder.ItemID,
+			HouseID:     roomHouseID(tx, item.RoomID),
+			Operation:   OperationCreate,
+			Description: "创建提醒: " + item.Name,
+			NewValue:    auditSnapshot(reminder),
+		})
+	})
 }
 
 // GetUpcomingReminders 获取即将到来的提醒
 func (s *itemService) GetUpcomingReminders(ctx context.Context, days int) ([]models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	var reminders []models.Reminder
 	
 	endTime := time.Now().AddDate(0, 0, days)
 	
-	err := s.db.WithContext(ctx).
+	err = s.db.WithContext(ctx).
+		Scopes(scopeReminders(userID)).
 		Where("status = ? AND trigger_time BETWEEN ? AND ?", 
-			"pending", time.Now(), endTime).
+			ReminderStatusPending, time.Now(), endTime).
 		Preload("Item").
 		Order("trigger_time").
 		Find(&reminders).Error
@@ -413,25 +773,32 @@ func (s *itemService) GetUpcomingReminders(ctx context.Context, days int) ([]mod
 }
 
 // GetItemStatistics 获取物品统计信息
-func (s *itemService) GetItemStatistics(ctx context.Context, userID string) (*ItemStatistics, error) {
+func (s *itemService) GetItemStatistics(ctx context.Context, userID string, filters ItemFilters) (*ItemStatistics, error) {
 	stats := &ItemStatistics{
 		ByStatus:   make(map[string]int64),
 		ByCategory: make(map[string]int64),
 	}
 
+	// 统计范围：用户可访问的物品，可按分类（含后代分类）过滤
+	base := func() *gorm.DB {
+		query := s.db.WithContext(ctx).Model(&models.Item{}).Scopes(scopeItems(userID))
+		if filters.CategoryID != nil {
+			query = whereCategory(query, *filters.CategoryID, filters.IncludeDescendants)
+		}
+		return query
+	}
+
 	// 获取总数
-	s.db.WithContext(ctx).Model(&models.Item{}).Count(&stats.TotalItems)
+	base().Count(&stats.TotalItems)
 
 	// 按状态统计
-	s.db.WithContext(ctx).
-		Model(&models.Item{}).
+	base().
 		Select("status, count(*)").
 		Group("status").
 		Scan(&stats.ByStatus)
 
 	// 按分类统计
-	s.db.WithContext(ctx).
-		Model(&models.Item{}).
+	base().
 		Select("c.name, count(*)").
 		Joins("LEFT JOIN categories c ON items.category_id = c.id").
 		Group("c.name").
@@ -439,22 +806,19 @@ func (s *itemService) GetItemStatistics(ctx context.Context, userID string) (*It
 
 	// 计算总价值
 	var totalValue float64
-	s.db.WithContext(ctx).
-		Model(&models.Item{}).
+	base().
 		Select("COALESCE(SUM(price * quantity), 0)").
 		Scan(&totalValue)
 	stats.TotalValue = totalValue
 
 	// 统计即将过期的物品（30天内）
 	expireTime := time.Now().AddDate(0, 0, 30)
-	s.db.WithContext(ctx).
-		Model(&models.Item{}).
+	base().
 		Where("expire_date IS NOT NULL AND expire_date <= ?", expireTime).
 		Count(&stats.ExpiringSoon)
 
 	// 统计低库存物品
-	s.db.WithContext(ctx).
-		Model(&models.Item{}).
+	base().
 		Where("quantity <= 1").
 		Count(&stats.LowStockItems)
 
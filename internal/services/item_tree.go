@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// ItemTreeNode 容器子树节点，汇总值包含节点自身及其全部可见后代
+type ItemTreeNode struct {
+	Item               models.Item     `json:"item"`
+	Depth              int             `json:"depth"`
+	Children           []*ItemTreeNode `json:"children,omitempty"`
+	Truncated          bool            `json:"truncated"` // 超出深度限制，子节点未展开
+	DescendantCount    int             `json:"descendant_count"`
+	TotalQuantity      int             `json:"total_quantity"`
+	TotalValue         float64         `json:"total_value"` // 价格 × 数量 之和
+	EarliestExpireDate *time.Time      `json:"earliest_expire_date,omitempty"`
+}
+
+// GetItemTree 获取物品的完整子树，depth限制展开层级（0表示不限制），汇总值始终按完整子树计算
+func (s *itemService) GetItemTree(ctx context.Context, itemID string, depth int) (*ItemTreeNode, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var root models.Item
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&root, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	var items []models.Item
+	err = s.db.WithContext(ctx).
+		Scopes(scopeItems(userID)).
+		Joins("JOIN item_hierarchy ih ON ih.descendant_id = items.id").
+		Where("ih.ancestor_id = ? AND ih.depth > 0", itemID).
+		Preload("Category").
+		Order("ih.depth, items.name").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildItemTree(root, items, depth), nil
+}
+
+// BuildItemTree 根据 container_id 将后代物品组装为树，父节点不可见的物品不会出现在树中
+func BuildItemTree(root models.Item, descendants []models.Item, depth int) *ItemTreeNode {
+	children := make(map[string][]models.Item)
+	for _, item := range descendants {
+		if item.ContainerID != nil {
+			children[*item.ContainerID] = append(children[*item.ContainerID], item)
+		}
+	}
+
+	visited := map[string]bool{root.ID: true}
+	return buildItemTreeNode(root, 0, depth, children, visited)
+}
+
+// buildItemTreeNode 递归构建节点并计算汇总值
+func buildItemTreeNode(item models.Item, level, depth int, children map[string][]models.Item, visited map[string]bool) *ItemTreeNode {
+	node := &ItemTreeNode{
+		Item:               item,
+		Depth:              level,
+		TotalQuantity:      item.Quantity,
+		EarliestExpireDate: item.ExpireDate,
+	}
+	if item.Price != nil {
+		node.TotalValue = *item.Price * float64(item.Quantity)
+	}
+
+	for _, child := range children[item.ID] {
+		// 防御脏数据中的循环引用
+		if visited[child.ID] {
+			continue
+		}
+		visited[child.ID] = true
+
+		childNode := buildItemTreeNode(child, level+1, depth, children, visited)
+		node.DescendantCount += childNode.DescendantCount + 1
+		node.TotalQuantity += childNode.TotalQuantity
+		node.TotalValue += childNode.TotalValue
+		if childNode.EarliestExpireDate != nil &&
+			(node.EarliestExpireDate == nil || childNode.EarliestExpireDate.Before(*node.EarliestExpireDate)) {
+			node.EarliestExpireDate = childNode.EarliestExpireDate
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	if depth > 0 && level >= depth && len(node.Children) > 0 {
+		node.Children = nil
+		node.Truncated = true
+	}
+
+	return node
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+)
+
+// ErrItemVersionNotFound 物品版本不存在，或指定时间点物品还没有版本记录
+var ErrItemVersionNotFound = notFoundError("物品版本不存在")
+
+// ItemVersionDiff 两个物品版本之间发生变化的列，键为列名
+type ItemVersionDiff struct {
+	ItemID   string         `json:"item_id"`
+	From     int            `json:"from"`
+	To       int            `json:"to"`
+	OldValue map[string]any `json:"old_value"`
+	NewValue map[string]any `json:"new_value"`
+}
+
+// ItemVersionBaseline 物品首次修改前补记的原始数据的版本操作类型
+const ItemVersionBaseline = "baseline"
+
+// recordItemBaseline 为还没有版本记录的物品补记当前数据作为第一个版本，时间取物品最后修改的时间。
+// 需在修改物品之前调用，版本记录上线前创建的物品由此保留首次修改前的数据
+func recordItemBaseline(tx *gorm.DB, itemIDs ...string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	// 先锁定物品再查询版本，并发的首次修改等待前者提交后不再重复补记
+	var items []models.Item
+	err := tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", itemIDs).
+		Find(&items).Error
+	if err != nil {
+		return err
+	}
+
+	var versioned []string
+	err = tx.Model(&models.ItemVersion{}).
+		Where("item_id IN ?", itemIDs).
+		Distinct().
+		Pluck("item_id", &versioned).Error
+	if err != nil {
+		return err
+	}
+
+	for i := range items {
+		item := &items[i]
+		if slices.Contains(versioned, item.ID) {
+			continue
+		}
+		err := tx.Omit("Item", "User").Create(&models.ItemVersion{
+			ItemID:    item.ID,
+			Version:   1,
+			Operation: ItemVersionBaseline,
+			Snapshot:  auditFields(item),
+			CreatedAt: item.UpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordItemVersions 为多个物品分别记录版本
+func recordItemVersions(tx *gorm.DB, operation string, itemIDs ...string) error {
+	for _, itemID := range itemIDs {
+		if err := recordItemVersion(tx, itemID, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordItemVersion 在事务中记录物品当前数据的快照，版本号在同一物品内递增
+// 调用前事务已修改物品记录并持有行锁，并发修改同一物品时版本号不会冲突
+func recordItemVersion(tx *gorm.DB, itemID, operation string) error {
+	userID, err := currentUserID(tx.Statement.Context)
+	if err != nil {
+		return err
+	}
+
+	// 随容器移动的后代可能在回收站中
+	var item models.Item
+	if err := tx.Unscoped().First(&item, "id = ?", itemID).Error; err != nil {
+		return err
+	}
+
+	var latest int
+	if err := tx.Model(&models.ItemVersion{}).Where("item_id = ?", itemID).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+		return err
+	}
+
+	return tx.Omit("Item", "User").Create(&models.ItemVersion{
+		ItemID:    itemID,
+		Version:   latest + 1,
+		Operation: operation,
+		Snapshot:  auditFields(&item),
+		UserID:    &userID,
+	}).Error
+}
+
+// ItemFromVersion 由版本快照还原物品数据，不含关联数据和时间戳
+func ItemFromVersion(version *models.ItemVersion) (*models.Item, error) {
+	// 快照的键为列名，与物品的JSON字段名一致
+	data, err := json.Marshal(version.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.Item
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, fmt.Errorf("物品版本数据无法解析: %w", err)
+	}
+	item.ID = version.ItemID
+	return &item, nil
+}
+
+// ListItemVersions 获取物品的版本列表，最新的在前
+func (s *itemService) ListItemVersions(ctx context.Context, itemID string, page, pageSize int) ([]models.ItemVersion, int64, error) {
+	if err := s.findVisibleItem(ctx, itemID); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.ItemVersion{}).Where("item_id = ?", itemID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var versions []models.ItemVersion
+	err := query.
+		Preload("User").
+		Order("version DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&versions).Error
+
+	return versions, total, err
+}
+
+// GetItemVersion 获取物品的指定版本
+func (s *itemService) GetItemVersion(ctx context.Context, itemID string, version int) (*models.ItemVersion, error) {
+	if err := s.findVisibleItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	return s.findItemVersion(s.db.WithContext(ctx).Where("version = ?", version), itemID)
+}
+
+// GetItemAsOf 获取物品在指定时间点的版本，即该时间之前最后一次修改后的数据
+func (s *itemService) GetItemAsOf(ctx context.Context, itemID string, at time.Time) (*models.ItemVersion, error) {
+	if err := s.findVisibleItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	return s.findItemVersion(s.db.WithContext(ctx).Where("created_at <= ?", at).Order("version DESC"), itemID)
+}
+
+// DiffItemVersions 比较物品的两个版本，to 为0时与最新版本比较
+func (s *itemService) DiffItemVersions(ctx context.Context, itemID string, from, to int) (*ItemVersionDiff, error) {
+	older, err := s.GetItemVersion(ctx, itemID, from)
+	if err != nil {
+		return nil, err
+	}
+
+	var newer *models.ItemVersion
+	if to == 0 {
+		newer, err = s.findItemVersion(s.db.WithContext(ctx).Order("version DESC"), itemID)
+	} else {
+		newer, err = s.findItemVersion(s.db.WithContext(ctx).Where("version = ?", to), itemID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	before, err := ItemFromVersion(older)
+	if err != nil {
+		return nil, err
+	}
+	after, err := ItemFromVersion(newer)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ItemVersionDiff{ItemID: itemID, From: older.Version, To: newer.Version}
+	diff.OldValue, diff.NewValue = AuditDiff(before, after)
+	return diff, nil
+}
+
+// RestoreItemVersion 将物品恢复为指定版本的数据，按修改物品的规则重新校验房间、容器和分类，并记录为新版本
+func (s *itemService) RestoreItemVersion(ctx context.Context, itemID string, version int) (*models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing models.Item
+	if err := s.db.WithContext(ctx).Scopes(scopeItems(userID)).First(&existing, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	target, err := s.findItemVersion(s.db.WithContext(ctx).Where("version = ?", version), itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := ItemFromVersion(target)
+	if err != nil {
+		return nil, err
+	}
+	item.CreatedAt = existing.CreatedAt
+
+	description := fmt.Sprintf("恢复物品: %s（版本%d）", item.Name, target.Version)
+	if err := s.updateItem(ctx, item, OperationRestore, description); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// findVisibleItem 校验物品对当前用户可见
+func (s *itemService) findVisibleItem(ctx context.Context, itemID string) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Item{}).Scopes(scopeItems(userID)).Where("items.id = ?", itemID).Count(&count)
+	if count == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// findItemVersion 按查询条件查找物品的一个版本
+func (s *itemService) findItemVersion(query *gorm.DB, itemID string) (*models.ItemVersion, error) {
+	var version models.ItemVersion
+	if err := query.Preload("User").First(&version, "item_id = ?", itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrItemVersionNotFound
+		}
+		return nil, err
+	}
+	return &version, nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+	"nookverse/internal/storage"
+)
+
+// mediaMigrationBatchSize 迁移时每批处理的媒体记录数
+const mediaMigrationBatchSize = 100
+
+// MediaMigrationResult 媒体文件迁移结果
+type MediaMigrationResult struct {
+	Records int64 // 处理的媒体记录数
+	Copied  int64 // 复制的对象数
+	Skipped int64 // 目标后端已存在而跳过的对象数
+	Missing int64 // 源后端中不存在的对象数
+}
+
+// MigrateMedia 将全部媒体文件从源存储后端复制到目标后端，并将 file_url、thumbnail_url 改写为API访问地址
+// 早期没有存储键的记录以 file_url 的相对路径作为存储键；已存在的对象不会重复复制，可多次执行；
+// 源后端中缺失对象的记录保持不变，补齐对象后重新执行即可
+func MigrateMedia(ctx context.Context, db *gorm.DB, from, to storage.Storage) (MediaMigrationResult, error) {
+	var result MediaMigrationResult
+	var batch []models.MediaFile
+
+	err := db.WithContext(ctx).Order("id").FindInBatches(&batch, mediaMigrationBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			media := &batch[i]
+			result.Records++
+
+			storageKey := media.StorageKey
+			if storageKey == "" {
+				storageKey = strings.TrimLeft(media.FileURL, "/")
+			}
+
+			contentType := ""
+			if media.MimeType != nil {
+				contentType = *media.MimeType
+			}
+			available, err := copyMediaObject(ctx, from, to, storageKey, contentType, &result)
+			if err != nil {
+				return err
+			}
+			if !available {
+				// 源对象缺失时保留原记录，避免改写为无法访问的地址
+				continue
+			}
+
+			updates := map[string]any{
+				"storage_key": storageKey,
+				"file_url":    mediaURL(media.ItemID, media.ID),
+			}
+			if media.ThumbnailKey != "" {
+				available, err := copyMediaObject(ctx, from, to, media.ThumbnailKey, "image/jpeg", &result)
+				if err != nil {
+					return err
+				}
+				if available {
+					updates["thumbnail_url"] = mediaURL(media.ItemID, media.ID) + "/thumbnail"
+				}
+			}
+
+			if err := db.WithContext(ctx).Model(&models.MediaFile{}).Where("id = ?", media.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+
+	return result, err
+}
+
+// copyMediaObject 在两个存储后端之间复制一个对象，返回目标后端中是否已有该对象，源对象缺失时返回 false
+func copyMediaObject(ctx context.Context, from, to storage.Storage, key, contentType string, result *MediaMigrationResult) (bool, error) {
+	exists, err := to.Exists(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		result.Skipped++
+		return true, nil
+	}
+
+	object, err := from.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			result.Missing++
+			return false, nil
+		}
+		return false, err
+	}
+	defer object.Close()
+
+	size, err := object.Seek(0, io.SeekEnd)
+	if err != nil {
+		return false, err
+	}
+	if _, err := object.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	if err := to.Put(ctx, key, object, size, contentType); err != nil {
+		return false, err
+	}
+	result.Copied++
+	return true, nil
+}
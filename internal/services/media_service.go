@@ -0,0 +1,452 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+	"nookverse/internal/storage"
+)
+
+// 媒体文件类型
+const (
+	MediaTypeImage    = "image"
+	MediaTypeVideo    = "video"
+	MediaTypeDocument = "document"
+)
+
+// sniffLength 识别文件类型时读取的字节数
+const sniffLength = 512
+
+// 媒体文件相关错误
+var (
+	ErrMediaNotFound       = notFoundError("媒体文件不存在")
+	ErrMediaTooLarge       = errors.New("文件大小超出限制")
+	ErrMediaTypeNotAllowed = errors.New("不支持的文件类型")
+	ErrMediaEmpty          = errors.New("文件内容为空")
+)
+
+// MediaService 物品媒体文件服务接口
+type MediaService interface {
+	UploadMedia(ctx context.Context, itemID string, content io.Reader, altText *string) (*models.MediaFile, error)
+	ListMedia(ctx context.Context, itemID string) ([]models.MediaFile, error)
+	OpenMedia(ctx context.Context, itemID, mediaID string) (*MediaContent, error)
+	DeleteMedia(ctx context.Context, itemID, mediaID string) error
+	ReorderMedia(ctx context.Context, itemID string, mediaIDs []string) error
+	MaxSize() int64
+
+	// 缩略图
+	RegenerateThumbnail(ctx context.Context, itemID, mediaID string) (*models.MediaFile, error)
+	OpenThumbnail(ctx context.Context, itemID, mediaID string) (*MediaContent, error)
+	StartThumbnailWorker(ctx context.Context)
+}
+
+// MediaContent 媒体文件内容，存储后端支持预签名时只返回下载地址，否则返回可Seek的内容
+type MediaContent struct {
+	Media       *models.MediaFile
+	Key         string
+	Body        io.ReadSeekCloser
+	RedirectURL string
+}
+
+type mediaService struct {
+	db         *gorm.DB
+	cfg        config.UploadConfig
+	storage    storage.Storage
+	thumbnails chan string // 待生成缩略图的媒体文件ID
+}
+
+// NewMediaService 创建媒体文件服务实例，需调用 StartThumbnailWorker 启动缩略图生成
+func NewMediaService(db *gorm.DB, cfg config.UploadConfig, backend storage.Storage) MediaService {
+	return &mediaService{
+		db:         db,
+		cfg:        cfg,
+		storage:    backend,
+		thumbnails: make(chan string, thumbnailQueueSize),
+	}
+}
+
+// MaxSize 单个文件的最大字节数
+func (s *mediaService) MaxSize() int64 {
+	return s.cfg.MaxSize
+}
+
+// UploadMedia 上传物品媒体文件：校验大小和真实文件类型，按内容哈希存储，相同内容只保存一份
+func (s *mediaService) UploadMedia(ctx context.Context, itemID string, content io.Reader, altText *string) (*models.MediaFile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.findItem(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+
+	// 根据文件头识别真实类型，不信任客户端声明的Content-Type
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrMediaEmpty
+	}
+	head = head[:n]
+
+	mimeType, err := DetectMediaType(head, s.cfg.AllowedTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = io.MultiReader(bytes.NewReader(head), content)
+	if mimeType == "image/jpeg" {
+		// 照片中的GPS定位属于隐私信息，入库前清除；JPEG需要完整读入后处理
+		data, err := io.ReadAll(io.LimitReader(body, s.cfg.MaxSize+1))
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(StripJPEGGPS(data))
+	}
+
+	media := &models.MediaFile{
+		ItemID:   itemID,
+		FileType: MediaFileType(mimeType),
+		MimeType: &mimeType,
+		AltText:  altText,
+	}
+
+	err = s.store(ctx, body, "", mimeType, func(tx *gorm.DB, key string, size int64) error {
+		media.StorageKey = key
+		media.FileSize = &size
+
+		// 新文件排在最后
+		var maxOrder *int
+		if err := tx.Model(&models.MediaFile{}).Where("item_id = ?", itemID).
+			Select("MAX(sort_order)").Scan(&maxOrder).Error; err != nil {
+			return err
+		}
+		if maxOrder != nil {
+			media.SortOrder = *maxOrder + 1
+		}
+
+		if err := tx.Omit(clause.Associations).Create(media).Error; err != nil {
+			return err
+		}
+
+		// 对外地址依赖记录ID，创建后再回填
+		media.FileURL = mediaURL(itemID, media.ID)
+		return tx.Model(media).Update("file_url", media.FileURL).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if media.FileType == MediaTypeImage {
+		s.enqueueThumbnail(media.ID)
+	}
+
+	return media, nil
+}
+
+// ListMedia 获取物品的媒体文件，按排序值升序
+func (s *mediaService) ListMedia(ctx context.Context, itemID string) ([]models.MediaFile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.findItem(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+
+	var media []models.MediaFile
+	err = s.db.WithContext(ctx).
+		Where("item_id = ?", itemID).
+		Order("sort_order, created_at").
+		Find(&media).Error
+
+	return media, err
+}
+
+// OpenMedia 打开媒体文件供读取，返回内容时调用方负责关闭
+func (s *mediaService) OpenMedia(ctx context.Context, itemID, mediaID string) (*MediaContent, error) {
+	media, err := s.findMedia(ctx, itemID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.open(ctx, media, media.StorageKey, ErrMediaNotFound)
+}
+
+// open 打开存储对象，优先使用预签名下载地址
+func (s *mediaService) open(ctx context.Context, media *models.MediaFile, key string, notFound error) (*MediaContent, error) {
+	content := &MediaContent{Media: media, Key: key}
+
+	url, err := s.storage.PresignURL(ctx, key, time.Duration(s.cfg.S3.PresignExpire)*time.Minute)
+	if err == nil {
+		content.RedirectURL = url
+		return content, nil
+	}
+	if !errors.Is(err, storage.ErrPresignUnsupported) {
+		return nil, err
+	}
+
+	content.Body, err = s.storage.Open(ctx, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return nil, notFound
+		}
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// DeleteMedia 删除媒体文件记录，内容不再被引用时同时删除磁盘文件
+func (s *mediaService) DeleteMedia(ctx context.Context, itemID, mediaID string) error {
+	media, err := s.findMedia(ctx, itemID, mediaID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Delete(&models.MediaFile{}, "id = ?", media.ID).Error; err != nil {
+		return err
+	}
+
+	s.removeIfUnused(ctx, media.StorageKey)
+	s.removeIfUnused(ctx, media.ThumbnailKey)
+	return nil
+}
+
+// ReorderMedia 按给定顺序重排物品的媒体文件，列表必须包含该物品的全部媒体文件
+func (s *mediaService) ReorderMedia(ctx context.Context, itemID string, mediaIDs []string) error {
+	media, err := s.ListMedia(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	if len(mediaIDs) != len(media) {
+		return errors.New("排序列表必须包含物品的全部媒体文件")
+	}
+	existing := make(map[string]bool, len(media))
+	for _, m := range media {
+		existing[m.ID] = true
+	}
+	for _, id := range mediaIDs {
+		if !existing[id] {
+			return ErrMediaNotFound
+		}
+		delete(existing, id)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range mediaIDs {
+			err := tx.Model(&models.MediaFile{}).
+				Where("id = ? AND item_id = ?", id, itemID).
+				Update("sort_order", i).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// findItem 校验物品对当前用户可见
+func (s *mediaService) findItem(ctx context.Context, userID, itemID string) error {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.Item{}).
+		Scopes(scopeItems(userID)).
+		Where("items.id = ?", itemID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+// findMedia 查找当前用户可见物品下的媒体文件
+func (s *mediaService) findMedia(ctx context.Context, itemID, mediaID string) (*models.MediaFile, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.findItem(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+
+	var media models.MediaFile
+	if err := s.db.WithContext(ctx).First(&media, "id = ? AND item_id = ?", mediaID, itemID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMediaNotFound
+		}
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// store 将内容写入存储后端，对象名为内容的SHA-256，相同内容只保存一份
+// 写入对象和 save 保存引用记录在同一事务中进行，并持有存储键的咨询锁，
+// 避免与 removeIfUnused 并发时刚确认存在的对象在记录提交前被删除
+func (s *mediaService) store(ctx context.Context, content io.Reader, prefix, mimeType string, save func(tx *gorm.DB, key string, size int64) error) error {
+	// 先写入本地临时文件计算哈希，确定存储键后再上传
+	tmp, err := os.CreateTemp("", "nookverse-upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	// 多读一个字节用于判断是否超出大小限制
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(content, s.cfg.MaxSize+1))
+	if err != nil {
+		return err
+	}
+	if size > s.cfg.MaxSize {
+		return ErrMediaTooLarge
+	}
+
+	key := prefix + MediaStorageKey(hex.EncodeToString(hash.Sum(nil)), mimeType)
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockStorageKey(tx, key); err != nil {
+			return err
+		}
+
+		exists, err := s.storage.Exists(ctx, key)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			if err := s.storage.Put(ctx, key, tmp, size, mimeType); err != nil {
+				return err
+			}
+		}
+
+		return save(tx, key, size)
+	})
+	if err != nil {
+		// 记录未保存时，本次写入的对象可能无人引用
+		s.removeIfUnused(ctx, key)
+		return err
+	}
+
+	return nil
+}
+
+// removeIfUnused 内容不再被任何媒体记录引用时从存储后端删除
+func (s *mediaService) removeIfUnused(ctx context.Context, key string) {
+	removeUnusedObject(ctx, s.db, s.storage, key)
+}
+
+// removeUnusedObject 存储对象不再被任何媒体记录引用时从存储后端删除，引用计数和删除在存储键的咨询锁内完成
+func removeUnusedObject(ctx context.Context, db *gorm.DB, backend storage.Storage, key string) {
+	if key == "" {
+		return
+	}
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockStorageKey(tx, key); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.MediaFile{}).Where("storage_key = ? OR thumbnail_key = ?", key, key).Count(&count).Error; err != nil || count > 0 {
+			return err
+		}
+		return backend.Delete(ctx, key)
+	})
+	if err != nil {
+		log.Printf("Failed to delete media object %s: %v", key, err)
+	}
+}
+
+// lockStorageKey 获取存储键的事务级咨询锁，事务结束时自动释放
+func lockStorageKey(tx *gorm.DB, key string) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error
+}
+
+// mediaURL 媒体文件的访问地址
+func mediaURL(itemID, mediaID string) string {
+	return fmt.Sprintf("/api/v1/items/%s/media/%s", itemID, mediaID)
+}
+
+// DetectMediaType 根据文件头识别MIME类型并校验是否在允许列表中
+func DetectMediaType(head []byte, allowedTypes []string) (string, error) {
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil {
+		return "", ErrMediaTypeNotAllowed
+	}
+
+	for _, allowed := range allowedTypes {
+		if strings.EqualFold(allowed, mimeType) {
+			return mimeType, nil
+		}
+	}
+
+	return "", ErrMediaTypeNotAllowed
+}
+
+// MediaFileType 根据MIME类型归类媒体文件
+func MediaFileType(mimeType string) string {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return MediaTypeImage
+	case strings.HasPrefix(mimeType, "video/"):
+		return MediaTypeVideo
+	default:
+		return MediaTypeDocument
+	}
+}
+
+// MediaStorageKey 由内容哈希生成存储键，按哈希前两位分目录避免单目录文件过多
+func MediaStorageKey(hash, mimeType string) string {
+	return hash[:2] + "/" + hash + mediaExtension(mimeType)
+}
+
+// mediaExtension MIME类型对应的文件扩展名
+func mediaExtension(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "video/mp4":
+		return ".mp4"
+	case "application/pdf":
+		return ".pdf"
+	}
+
+	if extensions, err := mime.ExtensionsByType(mimeType); err == nil && len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
+
+// orderMediaFiles 预加载媒体文件时按排序值排列
+func orderMediaFiles(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order, created_at")
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+// ErrNotificationNotFound 通知不存在或不属于当前用户
+var ErrNotificationNotFound = notFoundError("通知不存在")
+
+// NotificationService 站内通知（收件箱）服务接口
+type NotificationService interface {
+	ListNotifications(ctx context.Context, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error)
+	CountUnread(ctx context.Context) (int64, error)
+	MarkRead(ctx context.Context, notificationID string) error
+	MarkAllRead(ctx context.Context) (int64, error)
+	// Channels 可用于提醒的通知渠道
+	Channels() []string
+}
+
+type notificationService struct {
+	db        *gorm.DB
+	notifiers *NotifierRegistry
+}
+
+// NewNotificationService 创建站内通知服务实例
+func NewNotificationService(db *gorm.DB, notifiers *NotifierRegistry) NotificationService {
+	return &notificationService{db: db, notifiers: notifiers}
+}
+
+// ListNotifications 分页获取当前用户的通知，最新的在前
+func (s *notificationService) ListNotifications(ctx context.Context, unreadOnly bool, page, pageSize int) ([]models.Notification, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var notifications []models.Notification
+	err = query.
+		Order("created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+// CountUnread 当前用户的未读通知数
+func (s *notificationService) CountUnread(ctx context.Context) (int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+
+	return count, err
+}
+
+// MarkRead 标记通知为已读，重复标记保持首次阅读时间
+func (s *notificationService) MarkRead(ctx context.Context, notificationID string) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var notification models.Notification
+	err = s.db.WithContext(ctx).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		First(&notification).Error
+	if err != nil {
+		return ErrNotificationNotFound
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+
+	return s.db.WithContext(ctx).Model(&notification).Update("read_at", time.Now()).Error
+}
+
+// MarkAllRead 标记当前用户全部未读通知为已读，返回标记的数量
+func (s *notificationService) MarkAllRead(ctx context.Context) (int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	result := s.db.WithContext(ctx).Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+
+	return result.RowsAffected, result.Error
+}
+
+// Channels 可用于提醒的通知渠道
+func (s *notificationService) Channels() []string {
+	return s.notifiers.Channels()
+}
+
+// AppNotifier app渠道：为每个接收人写入一条站内通知
+type AppNotifier struct {
+	db         *gorm.DB
+	recipients RecipientResolver
+}
+
+// NewAppNotifier 创建站内通知渠道
+func NewAppNotifier(db *gorm.DB) *AppNotifier {
+	return &AppNotifier{db: db, recipients: ReminderRecipients(db)}
+}
+
+// Notify 写入站内通知，同一提醒的全部接收人在一个事务中写入
+func (n *AppNotifier) Notify(ctx context.Context, reminder *models.Reminder) error {
+	users, err := n.recipients(ctx, reminder)
+	if err != nil || len(users) == 0 {
+		return err
+	}
+
+	title := reminderTitle(reminder)
+	notifications := make([]models.Notification, 0, len(users))
+	for _, user := range users {
+		notifications = append(notifications, models.Notification{
+			UserID:     user.ID,
+			ReminderID: &reminder.ID,
+			ItemID:     &reminder.ItemID,
+			Title:      title,
+			Message:    reminder.Message,
+		})
+	}
+
+	return n.db.WithContext(ctx).Create(&notifications).Error
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+)
+
+// 内置通知渠道名称，其余渠道（如 webhook、sms、voice）通过配置中的webhook注册
+const (
+	NotifyChannelApp   = "app"
+	NotifyChannelEmail = "email"
+)
+
+// ErrUnsupportedNotifyChannel 提醒指定了未注册的通知渠道
+var ErrUnsupportedNotifyChannel = errors.New("不支持的通知渠道")
+
+// Notifier 提醒通知渠道
+type Notifier interface {
+	Notify(ctx context.Context, reminder *models.Reminder) error
+}
+
+// NotifierFunc 以函数实现的通知渠道
+type NotifierFunc func(ctx context.Context, reminder *models.Reminder) error
+
+// Notify 调用函数发送通知
+func (f NotifierFunc) Notify(ctx context.Context, reminder *models.Reminder) error {
+	return f(ctx, reminder)
+}
+
+// NotifierRegistry 通知渠道注册表，零值可直接使用
+type NotifierRegistry struct {
+	notifiers map[string]Notifier
+}
+
+// NewNotifierRegistry 根据配置注册通知渠道：app 始终可用，配置SMTP后启用 email，每个webhook配置注册为同名渠道
+func NewNotifierRegistry(db *gorm.DB, cfg config.NotifyConfig) (*NotifierRegistry, error) {
+	registry := &NotifierRegistry{}
+	recipients := ReminderRecipients(db)
+
+	registry.Register(NotifyChannelApp, NewAppNotifier(db))
+
+	if cfg.SMTP.Host != "" {
+		notifier, err := NewEmailNotifier(cfg.SMTP, recipients)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(NotifyChannelEmail, notifier)
+	}
+
+	for channel, webhook := range cfg.Webhooks {
+		if _, exists := registry.Get(channel); exists {
+			return nil, fmt.Errorf("notify channel %s is already registered", channel)
+		}
+		notifier, err := NewWebhookNotifier(channel, webhook, recipients)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(channel, notifier)
+	}
+
+	return registry, nil
+}
+
+// Register 注册通知渠道，同名渠道会被替换
+func (r *NotifierRegistry) Register(channel string, notifier Notifier) {
+	if r.notifiers == nil {
+		r.notifiers = make(map[string]Notifier)
+	}
+	r.notifiers[channel] = notifier
+}
+
+// Get 获取通知渠道
+func (r *NotifierRegistry) Get(channel string) (Notifier, bool) {
+	if r == nil {
+		return nil, false
+	}
+	notifier, ok := r.notifiers[channel]
+	return notifier, ok
+}
+
+// Channels 已注册的渠道名称，按名称排序
+func (r *NotifierRegistry) Channels() []string {
+	if r == nil {
+		return []string{}
+	}
+	channels := make([]string, 0, len(r.notifiers))
+	for channel := range r.notifiers {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Validate 校验渠道均已注册
+func (r *NotifierRegistry) Validate(channels []string) error {
+	for _, channel := range channels {
+		if _, ok := r.Get(channel); !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedNotifyChannel, channel)
+		}
+	}
+	return nil
+}
+
+// normalizeNotifyChannels 去除空白和重复的渠道名，保持原有顺序
+func normalizeNotifyChannels(channels []string) []string {
+	seen := make(map[string]bool, len(channels))
+	normalized := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		channel = strings.TrimSpace(channel)
+		if channel == "" || seen[channel] {
+			continue
+		}
+		seen[channel] = true
+		normalized = append(normalized, channel)
+	}
+	return normalized
+}
+
+// RecipientResolver 查询提醒的接收用户
+type RecipientResolver func(ctx context.Context, reminder *models.Reminder) ([]models.User, error)
+
+// reminderRecipientsSQL 能看到提醒所属物品的用户：物品所在房屋的家庭正常成员，私有物品仅限获得授权的成员
+const reminderRecipientsSQL = `SELECT fm.user_id FROM items i
+	JOIN rooms r ON r.id = i.room_id
+	JOIN family_houses fh ON fh.house_id = r.house_id
+	JOIN family_members fm ON fm.family_id = fh.family_id AND fm.status = 1
+	WHERE i.id = @item_id
+	AND (NOT EXISTS (SELECT 1 FROM item_permissions ip WHERE ip.item_id = i.id AND ip.permission_level = 'owner')
+	OR EXISTS (SELECT 1 FROM item_permissions ip WHERE ip.item_id = i.id AND ip.user_id = fm.user_id))`
+
+// ReminderRecipients 以物品可见性确定提醒接收人，与物品列表的访问范围一致
+func ReminderRecipients(db *gorm.DB) RecipientResolver {
+	return func(ctx context.Context, reminder *models.Reminder) ([]models.User, error) {
+		var users []models.User
+		err := db.WithContext(ctx).
+			Where("id IN ("+reminderRecipientsSQL+")", sql.Named("item_id", reminder.ItemID)).
+			Where("status = 1").
+			Order("created_at").
+			Find(&users).Error
+		return users, err
+	}
+}
+
+// reminderTypeTitles 提醒类型对应的通知标题
+var reminderTypeTitles = map[string]string{
+	ReminderTypeExpire:      "过期提醒",
+	ReminderTypeMaintenance: "保养提醒",
+	ReminderTypeWarranty:    "保修到期提醒",
+}
+
+// reminderTitle 通知标题，如"过期提醒：牛奶"
+func reminderTitle(reminder *models.Reminder) string {
+	title, ok := reminderTypeTitles[reminder.ReminderType]
+	if !ok {
+		title = "物品提醒"
+	}
+	if reminder.Item != nil {
+		title += "：" + reminder.Item.Name
+	}
+	return title
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+)
+
+// smtpTimeout 单次发送（建立连接到全部收件人投递完成）的超时时间
+const smtpTimeout = time.Minute
+
+// EmailNotifier email渠道：通过SMTP向每个接收人单独发送一封邮件，不暴露其他成员的邮箱
+type EmailNotifier struct {
+	addr       string
+	host       string
+	from       *mail.Address
+	auth       smtp.Auth
+	recipients RecipientResolver
+}
+
+// NewEmailNotifier 创建SMTP邮件渠道；服务器支持STARTTLS时自动启用加密，配置用户名时使用PLAIN认证
+func NewEmailNotifier(cfg config.SMTPConfig, recipients RecipientResolver) (*EmailNotifier, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid smtp from address %q: %w", cfg.From, err)
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = 587
+	}
+
+	notifier := &EmailNotifier{
+		addr:       net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		host:       cfg.Host,
+		from:       from,
+		recipients: recipients,
+	}
+	if cfg.Username != "" {
+		notifier.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	return notifier, nil
+}
+
+// Notify 向提醒的全部接收人发送邮件，部分收件人失败时返回汇总错误
+func (n *EmailNotifier) Notify(ctx context.Context, reminder *models.Reminder) error {
+	users, err := n.recipients(ctx, reminder)
+	if err != nil || len(users) == 0 {
+		return err
+	}
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", n.addr)
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(smtpTimeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, n.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: n.host}); err != nil {
+			return err
+		}
+	}
+	if n.auth != nil {
+		if ok, _ := client.Extension("AUTH"); ok {
+			if err := client.Auth(n.auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	subject := reminderTitle(reminder)
+	var failures []error
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		message := buildEmail(n.from, user.Email, subject, reminderEmailBody(reminder))
+		if err := n.send(client, user.Email, message); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", user.Email, err))
+			// 单个收件人被拒绝后重置会话，继续投递其他收件人
+			if resetErr := client.Reset(); resetErr != nil {
+				return errors.Join(append(failures, resetErr)...)
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	return client.Quit()
+}
+
+// send 在已建立的会话中投递一封邮件
+func (n *EmailNotifier) send(client *smtp.Client, to string, message []byte) error {
+	if err := client.Mail(n.from.Address); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// reminderEmailBody 邮件正文
+func reminderEmailBody(reminder *models.Reminder) string {
+	body := reminder.Message + "\r\n\r\n"
+	if reminder.Item != nil {
+		body += "物品：" + reminder.Item.Name + "\r\n"
+	}
+	body += "提醒时间：" + reminder.TriggerTime.Format("2006-01-02 15:04") + "\r\n"
+	return body
+}
+
+// buildEmail 构造UTF-8纯文本邮件，主题按RFC 2047编码，正文使用base64传输编码
+func buildEmail(from *mail.Address, to, subject, body string) []byte {
+	var b bytes.Buffer
+	b.WriteString("From: " + from.String() + "\r\n")
+	b.WriteString("To: " + (&mail.Address{Address: to}).String() + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+
+	return b.Bytes()
+}
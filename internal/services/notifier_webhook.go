@@ -0,0 +1,167 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+)
+
+// webhook请求头
+const (
+	WebhookSignatureHeader = "X-Nookverse-Signature" // sha256=<HMAC-SHA256(secret, 时间戳 + "." + 请求体)的十六进制>
+	WebhookTimestampHeader = "X-Nookverse-Timestamp" // Unix秒级时间戳，接收方可据此拒绝过期请求
+	WebhookDeliveryHeader  = "X-Nookverse-Delivery"  // 提醒ID，重试时不变，接收方可据此去重
+)
+
+// defaultWebhookTimeout 未配置超时时间时的默认值
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookPayload webhook请求体
+type WebhookPayload struct {
+	Channel    string             `json:"channel"`
+	Reminder   WebhookReminder    `json:"reminder"`
+	Recipients []WebhookRecipient `json:"recipients"`
+	SentAt     time.Time          `json:"sent_at"`
+}
+
+// WebhookReminder webhook请求中的提醒信息
+type WebhookReminder struct {
+	ID           string    `json:"id"`
+	ItemID       string    `json:"item_id"`
+	ItemName     string    `json:"item_name,omitempty"`
+	ReminderType string    `json:"reminder_type"`
+	Title        string    `json:"title"`
+	Message      string    `json:"message"`
+	TriggerTime  time.Time `json:"trigger_time"`
+}
+
+// WebhookRecipient webhook请求中的接收人，短信/语音网关使用手机号
+type WebhookRecipient struct {
+	UserID   string  `json:"user_id"`
+	Username string  `json:"username"`
+	Email    string  `json:"email"`
+	Phone    *string `json:"phone,omitempty"`
+}
+
+// WebhookNotifier 通用HTTP webhook渠道：向配置的地址POST JSON，并以共享密钥对请求签名
+type WebhookNotifier struct {
+	channel    string
+	url        string
+	secret     []byte
+	client     *http.Client
+	recipients RecipientResolver
+}
+
+// NewWebhookNotifier 创建webhook渠道
+func NewWebhookNotifier(channel string, cfg config.WebhookConfig, recipients RecipientResolver) (*WebhookNotifier, error) {
+	endpoint, err := url.Parse(cfg.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid webhook url for channel %s: %q", channel, cfg.URL)
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("webhook channel %s requires a secret", channel)
+	}
+
+	timeout := defaultWebhookTimeout
+	if cfg.Timeout > 0 {
+		timeout = time.Duration(cfg.Timeout) * time.Second
+	}
+
+	return &WebhookNotifier{
+		channel:    channel,
+		url:        cfg.URL,
+		secret:     []byte(cfg.Secret),
+		client:     &http.Client{Timeout: timeout},
+		recipients: recipients,
+	}, nil
+}
+
+// Notify 发送webhook请求，非2xx响应视为失败并由调度器重试
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder *models.Reminder) error {
+	users, err := n.recipients(ctx, reminder)
+	if err != nil {
+		return err
+	}
+
+	payload := WebhookPayload{
+		Channel: n.channel,
+		Reminder: WebhookReminder{
+			ID:           reminder.ID,
+			ItemID:       reminder.ItemID,
+			ReminderType: reminder.ReminderType,
+			Title:        reminderTitle(reminder),
+			Message:      reminder.Message,
+			TriggerTime:  reminder.TriggerTime,
+		},
+		Recipients: make([]WebhookRecipient, 0, len(users)),
+		SentAt:     time.Now().UTC(),
+	}
+	if reminder.Item != nil {
+		payload.Reminder.ItemName = reminder.Item.Name
+	}
+	for _, user := range users {
+		payload.Recipients = append(payload.Recipients, WebhookRecipient{
+			UserID:   user.ID,
+			Username: user.Username,
+			Email:    user.Email,
+			Phone:    user.Phone,
+		})
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := payload.SentAt.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(n.secret, timestamp, body))
+	req.Header.Set(WebhookDeliveryHeader, reminder.ID)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s: %s %s", n.channel, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// SignWebhook 计算webhook签名头的值：sha256=<HMAC-SHA256(secret, 时间戳 + "." + 请求体)的十六进制>
+func SignWebhook(secret []byte, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook 校验webhook签名，接收方可用于验证请求来源
+func VerifyWebhook(secret []byte, timestamp int64, body []byte, signature string) error {
+	if !hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature)) {
+		return errors.New("webhook签名无效")
+	}
+	return nil
+}
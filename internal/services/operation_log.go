@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"nookverse/internal/models"
+)
+
+// 操作类型
+const (
+	OperationCreate   = "create"
+	OperationUpdate   = "update"
+	OperationDelete   = "delete"
+	OperationMove     = "move"
+	OperationComplete = "complete"
+	OperationSnooze   = "snooze"
+	OperationCancel   = "cancel"
+	OperationRestore  = "restore"
+)
+
+// 操作对象类型
+const (
+	EntityItem     = "item"
+	EntityRoom     = "room"
+	EntityHouse    = "house"
+	EntityCategory = "category"
+	EntityReminder = "reminder"
+)
+
+// auditIgnoredColumns 不记录到字段差异中的列：主键、时间戳、回收站标记和调度器维护的发送状态
+var auditIgnoredColumns = map[string]bool{
+	"id":              true,
+	"created_at":      true,
+	"updated_at":      true,
+	"deleted_at":      true,
+	"attempts":        true,
+	"next_attempt_at": true,
+	"last_error":      true,
+}
+
+// auditSchemas 模型结构解析缓存
+var auditSchemas sync.Map
+
+// operationEntry 一条操作日志，操作人和客户端信息从事务的上下文读取
+type operationEntry struct {
+	EntityType  string
+	EntityID    string
+	ItemID      *string // 物品及其提醒的操作关联到物品，用于物品历史
+	HouseID     *string
+	Operation   string
+	Description string
+	OldValue    map[string]any
+	NewValue    map[string]any
+}
+
+// recordOperation 在事务中写入一条操作日志，与被记录的修改同时提交或回滚
+func recordOperation(tx *gorm.DB, entry operationEntry) error {
+	ctx := tx.Statement.Context
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+	meta := clientMetaFromContext(ctx)
+
+	log := &models.OperationLog{
+		UserID:        &userID,
+		ItemID:        entry.ItemID,
+		EntityType:    entry.EntityType,
+		EntityID:      &entry.EntityID,
+		HouseID:       entry.HouseID,
+		FamilyID:      houseFamilyID(tx, entry.HouseID),
+		OperationType: entry.Operation,
+		Description:   &entry.Description,
+		OldValue:      entry.OldValue,
+		NewValue:      entry.NewValue,
+	}
+	if entry.ItemID != nil {
+		log.OwnerID = privateItemOwner(tx, *entry.ItemID)
+	}
+	if meta.IPAddress != "" {
+		log.IPAddress = &meta.IPAddress
+	}
+	if meta.UserAgent != "" {
+		log.UserAgent = &meta.UserAgent
+	}
+
+	return tx.Omit(clause.Associations).Create(log).Error
+}
+
+// auditFields 模型的数据库列及其值（键为列名），不含关联数据和 auditIgnoredColumns 中的列
+func auditFields(model any) map[string]any {
+	s, err := schema.Parse(model, &auditSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil
+	}
+
+	value := reflect.Indirect(reflect.ValueOf(model))
+	fields := make(map[string]any, len(s.DBNames))
+	for _, field := range s.Fields {
+		if field.DBName == "" || auditIgnoredColumns[field.DBName] {
+			continue
+		}
+		v, _ := field.ValueOf(context.Background(), value)
+		switch rv := reflect.ValueOf(v); rv.Kind() {
+		case reflect.Pointer, reflect.Map, reflect.Slice:
+			if rv.IsNil() {
+				v = nil
+			}
+		}
+		fields[field.DBName] = v
+	}
+	return fields
+}
+
+// auditSnapshot 创建或删除时记录的完整数据，省略空值
+func auditSnapshot(model any) map[string]any {
+	fields := auditFields(model)
+	for column, v := range fields {
+		if v == nil {
+			delete(fields, column)
+		}
+	}
+	return fields
+}
+
+// AuditDiff 修改前后发生变化的列，分别返回旧值和新值；没有变化时返回nil
+func AuditDiff(before, after any) (map[string]any, map[string]any) {
+	oldFields, newFields := auditFields(before), auditFields(after)
+
+	var oldValue, newValue map[string]any
+	for column, v := range newFields {
+		previous := oldFields[column]
+		a, _ := json.Marshal(previous)
+		b, _ := json.Marshal(v)
+		if string(a) == string(b) {
+			continue
+		}
+		if oldValue == nil {
+			oldValue, newValue = make(map[string]any), make(map[string]any)
+		}
+		oldValue[column] = previous
+		newValue[column] = v
+	}
+	return oldValue, newValue
+}
+
+// roomHouseID 房间所在的房屋ID，房间不存在时返回nil
+func roomHouseID(tx *gorm.DB, roomID *string) *string {
+	if roomID == nil {
+		return nil
+	}
+	var houseIDs []string
+	tx.Model(&models.Room{}).Where("id = ?", *roomID).Limit(1).Pluck("house_id", &houseIDs)
+	if len(houseIDs) == 0 {
+		return nil
+	}
+	return &houseIDs[0]
+}
+
+// itemHouseID 物品所在的房屋ID
+func itemHouseID(tx *gorm.DB, itemID string) *string {
+	var roomIDs []*string
+	tx.Model(&models.Item{}).Where("id = ?", itemID).Limit(1).Pluck("room_id", &roomIDs)
+	if len(roomIDs) == 0 {
+		return nil
+	}
+	return roomHouseID(tx, roomIDs[0])
+}
+
+// houseFamilyID 房屋所属的家庭ID，房屋不存在时返回nil
+func houseFamilyID(tx *gorm.DB, houseID *string) *string {
+	if houseID == nil {
+		return nil
+	}
+	var familyIDs []string
+	tx.Table("family_houses").Where("house_id = ?", *houseID).Limit(1).Pluck("family_id", &familyIDs)
+	if len(familyIDs) == 0 {
+		return nil
+	}
+	return &familyIDs[0]
+}
+
+// privateItemOwner 私有物品的所有者ID，物品不是私有时返回nil
+func privateItemOwner(tx *gorm.DB, itemID string) *string {
+	var ownerIDs []string
+	tx.Model(&models.ItemPermission{}).
+		Where("item_id = ? AND permission_level = ?", itemID, PermissionOwner).
+		Limit(1).Pluck("user_id", &ownerIDs)
+	if len(ownerIDs) == 0 {
+		return nil
+	}
+	return &ownerIDs[0]
+}
+
+// ActivityFilters 家庭动态过滤条件
+type ActivityFilters struct {
+	EntityType *string
+	UserID     *string
+	Page       int
+	PageSize   int
+}
+
+// OperationLogService 操作日志查询服务接口
+type OperationLogService interface {
+	// ListItemHistory 物品及其提醒的操作历史，最近的在前
+	ListItemHistory(ctx context.Context, itemID string, page, pageSize int) ([]models.OperationLog, int64, error)
+	// ListFamilyActivity 家庭动态：记录时属于家庭房屋的全部操作，以及家庭成员对分类的操作
+	ListFamilyActivity(ctx context.Context, familyID string, filters ActivityFilters) ([]models.OperationLog, int64, error)
+}
+
+type operationLogService struct {
+	db *gorm.DB
+}
+
+// NewOperationLogService 创建操作日志查询服务实例
+func NewOperationLogService(db *gorm.DB) OperationLogService {
+	return &operationLogService{db: db}
+}
+
+// ListItemHistory 获取物品的操作历史
+func (s *operationLogService) ListItemHistory(ctx context.Context, itemID string, page, pageSize int) ([]models.OperationLog, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Item{}).Scopes(scopeItems(userID)).Where("id = ?", itemID).Count(&count)
+	if count == 0 {
+		return nil, 0, ErrItemNotFound
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.OperationLog{}).Where("item_id = ?", itemID)
+	return s.page(query, page, pageSize)
+}
+
+// familyActivitySQL 家庭动态的范围：记录时归属于该家庭的操作；分类不属于任何家庭，归属于操作人所在的家庭
+const familyActivitySQL = `(operation_logs.family_id = @fid
+	OR (operation_logs.entity_type = 'category'
+	AND operation_logs.user_id IN (SELECT user_id FROM family_members WHERE family_id = @fid AND status = 1)))`
+
+// activityVisibleSQL 当前用户可见的日志：私有物品的日志只对记录时的所有者可见（物品彻底删除后同样适用），现存物品（含回收站中的物品）按当前权限过滤
+const activityVisibleSQL = `(operation_logs.owner_id IS NULL OR operation_logs.owner_id = @uid)
+	AND (operation_logs.item_id IS NULL OR operation_logs.item_id IN (` + memberItemsSQL + `))`
+
+// ListFamilyActivity 获取家庭动态，当前用户看不到的私有物品的操作不会返回
+func (s *operationLogService) ListFamilyActivity(ctx context.Context, familyID string, filters ActivityFilters) ([]models.OperationLog, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Family{}).Scopes(scopeFamilies(userID)).Where("id = ?", familyID).Count(&count)
+	if count == 0 {
+		return nil, 0, ErrFamilyNotFound
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.OperationLog{}).
+		Where(familyActivitySQL, sql.Named("fid", familyID)).
+		Where(activityVisibleSQL, sql.Named("uid", userID))
+
+	if filters.EntityType != nil {
+		query = query.Where("operation_logs.entity_type = ?", *filters.EntityType)
+	}
+	if filters.UserID != nil {
+		query = query.Where("operation_logs.user_id = ?", *filters.UserID)
+	}
+
+	return s.page(query, filters.Page, filters.PageSize)
+}
+
+// page 分页查询日志并加载操作人
+func (s *operationLogService) page(query *gorm.DB, page, pageSize int) ([]models.OperationLog, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var logs []models.OperationLog
+	err := query.
+		Preload("User").
+		Order("operation_logs.created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&logs).Error
+
+	return logs, total, err
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+)
+
+// ErrForbidden 无权执行该操作
+var ErrForbidden = errors.New("无权执行该操作")
+
+// Action 操作类型
+type Action string
+
+const (
+	ActionView   Action = "view"   // 查看
+	ActionCreate Action = "create" // 在资源下创建子资源（房屋下建房间、房间/容器内放物品）
+	ActionEdit   Action = "edit"   // 修改
+	ActionDelete Action = "delete" // 删除
+	ActionManage Action = "manage" // 管理物品授权
+)
+
+// 家庭角色
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+	RoleViewer = "viewer"
+)
+
+// 物品权限级别
+const (
+	PermissionOwner = "owner"
+	PermissionEdit  = "edit"
+	PermissionView  = "view"
+)
+
+// roleRank 角色等级，数值越大权限越高
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleMember: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+// roleActions 家庭角色对家庭、房屋和房间允许的操作
+var roleActions = map[string]map[Action]bool{
+	RoleOwner:  {ActionView: true, ActionCreate: true, ActionEdit: true, ActionDelete: true, ActionManage: true},
+	RoleAdmin:  {ActionView: true, ActionCreate: true, ActionEdit: true, ActionDelete: true, ActionManage: true},
+	RoleMember: {ActionView: true},
+	RoleViewer: {ActionView: true},
+}
+
+// roomMemberActions 普通成员在房间内可以放置物品
+var roomMemberActions = map[Action]bool{ActionView: true, ActionCreate: true}
+
+// permissionActions 物品权限级别允许的操作
+var permissionActions = map[string]map[Action]bool{
+	PermissionOwner: {ActionView: true, ActionCreate: true, ActionEdit: true, ActionDelete: true, ActionManage: true},
+	PermissionEdit:  {ActionView: true, ActionCreate: true, ActionEdit: true, ActionDelete: true},
+	PermissionView:  {ActionView: true},
+}
+
+// roleItemPermission 未单独授权时，家庭角色对应的物品权限级别
+var roleItemPermission = map[string]string{
+	RoleOwner:  PermissionOwner,
+	RoleAdmin:  PermissionOwner,
+	RoleMember: PermissionEdit,
+	RoleViewer: PermissionView,
+}
+
+// PermissionService 权限策略服务接口
+type PermissionService interface {
+	// 策略判断，资源不可见时返回 ErrNotFound，权限不足时返回 ErrForbidden
+	AuthorizeFamily(ctx context.Context, familyID string, action Action) error
+	AuthorizeHouse(ctx context.Context, houseID string, action Action) error
+	AuthorizeRoom(ctx context.Context, roomID string, action Action) error
+	AuthorizeItem(ctx context.Context, itemID string, action Action) error
+	AuthorizeCategory(ctx context.Context, categoryID string, action Action) error
+
+	// 物品授权管理
+	GrantItemPermission(ctx context.Context, itemID, userID, level string) (*models.ItemPermission, error)
+	RevokeItemPermission(ctx context.Context, itemID, userID string) error
+	ListItemPermissions(ctx context.Context, itemID string) ([]models.ItemPermission, error)
+	GetEffectivePermission(ctx context.Context, itemID, userID string) (*EffectivePermission, error)
+}
+
+// EffectivePermission 用户对物品的有效权限
+type EffectivePermission struct {
+	ItemID     string          `json:"item_id"`
+	UserID     string          `json:"user_id"`
+	Role       string          `json:"role"`                  // 家庭角色
+	GrantLevel *string         `json:"grant_level,omitempty"` // 单独授权级别
+	Level      string          `json:"level"`                 // 最终生效的权限级别，为空表示不可见
+	Private    bool            `json:"private"`               // 是否为私有物品
+	Actions    map[Action]bool `json:"actions"`
+}
+
+type permissionService struct {
+	db *gorm.DB
+}
+
+// NewPermissionService 创建权限策略服务实例
+func NewPermissionService(db *gorm.DB) PermissionService {
+	return &permissionService{db: db}
+}
+
+// AuthorizeFamily 校验用户在家庭中的操作权限，familyID为空时使用用户唯一所属的家庭
+func (s *permissionService) AuthorizeFamily(ctx context.Context, familyID string, action Action) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	familyID, err = resolveFamily(ctx, s.db, userID, familyID)
+	if err != nil {
+		return err
+	}
+
+	var member models.FamilyMember
+	err = s.db.WithContext(ctx).
+		Where("family_id = ? AND user_id = ? AND status = 1", familyID, userID).
+		First(&member).Error
+	if err != nil {
+		return ErrFamilyNotFound
+	}
+
+	if !roleActions[member.Role][action] {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeHouse 校验用户对房屋的操作权限
+func (s *permissionService) AuthorizeHouse(ctx context.Context, houseID string, action Action) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	role, err := s.houseRole(ctx, userID, houseID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrHouseNotFound
+	}
+
+	if !roleActions[role][action] {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeRoom 校验用户对房间的操作权限
+func (s *permissionService) AuthorizeRoom(ctx context.Context, roomID string, action Action) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var room models.Room
+	if err := s.db.WithContext(ctx).Scopes(scopeRooms(userID)).First(&room, "id = ?", roomID).Error; err != nil {
+		return ErrRoomNotFound
+	}
+
+	role, err := s.houseRole(ctx, userID, room.HouseID)
+	if err != nil {
+		return err
+	}
+
+	allowed := roleActions[role][action]
+	if role == RoleMember {
+		allowed = roomMemberActions[action]
+	}
+	if !allowed {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeItem 校验用户对物品的操作权限
+func (s *permissionService) AuthorizeItem(ctx context.Context, itemID string, action Action) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	effective, err := s.effectivePermission(ctx, itemID, userID)
+	if err != nil {
+		return err
+	}
+	if effective.Level == "" {
+		return ErrItemNotFound
+	}
+
+	if !effective.Actions[action] {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeCategory 校验用户对分类的操作权限，categoryID为空时校验能否创建分类：
+// 系统分类只读；自定义分类仅创建者可以修改；只在家庭中担任 viewer 的用户不能创建或修改分类
+func (s *permissionService) AuthorizeCategory(ctx context.Context, categoryID string, action Action) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if categoryID != "" {
+		var category models.Category
+		if err := s.db.WithContext(ctx).Scopes(scopeCategories(userID)).First(&category, "id = ?", categoryID).Error; err != nil {
+			return ErrCategoryNotFound
+		}
+		if action == ActionView {
+			return nil
+		}
+		if category.IsSystem {
+			return ErrSystemCategory
+		}
+		if category.CreatedBy == nil || *category.CreatedBy != userID {
+			return ErrForbidden
+		}
+	} else if action == ActionView {
+		return nil
+	}
+
+	var roles []string
+	err = s.db.WithContext(ctx).Model(&models.FamilyMember{}).
+		Where("user_id = ? AND status = 1", userID).
+		Pluck("role", &roles).Error
+	if err != nil {
+		return err
+	}
+	for _, role := range roles {
+		if roleRank[role] >= roleRank[RoleMember] {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// GrantItemPermission 授予或更新用户对物品的权限
+func (s *permissionService) GrantItemPermission(ctx context.Context, itemID, userID, level string) (*models.ItemPermission, error) {
+	if _, ok := permissionActions[level]; !ok {
+		return nil, errors.New("无效的权限级别")
+	}
+
+	if err := s.AuthorizeItem(ctx, itemID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	granterID, _ := currentUserID(ctx)
+
+	// 被授权用户必须是物品所在家庭的成员
+	var item models.Item
+	if err := s.db.WithContext(ctx).First(&item, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+	role, err := s.roomRole(ctx, userID, item.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, notFoundError("被授权用户不是该家庭成员")
+	}
+
+	permission := &models.ItemPermission{
+		ItemID:          itemID,
+		UserID:          userID,
+		PermissionLevel: level,
+		GrantedBy:       &granterID,
+	}
+
+	err = s.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "item_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"permission_level", "granted_by"}),
+		}).
+		Create(permission).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return permission, nil
+}
+
+// RevokeItemPermission 撤销用户对物品的单独授权
+func (s *permissionService) RevokeItemPermission(ctx context.Context, itemID, userID string) error {
+	if err := s.AuthorizeItem(ctx, itemID, ActionManage); err != nil {
+		return err
+	}
+
+	result := s.db.WithContext(ctx).
+		Where("item_id = ? AND user_id = ?", itemID, userID).
+		Delete(&models.ItemPermission{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return notFoundError("授权记录不存在")
+	}
+
+	return nil
+}
+
+// ListItemPermissions 列出物品的单独授权
+func (s *permissionService) ListItemPermissions(ctx context.Context, itemID string) ([]models.ItemPermission, error) {
+	if err := s.AuthorizeItem(ctx, itemID, ActionManage); err != nil {
+		return nil, err
+	}
+
+	var permissions []models.ItemPermission
+	err := s.db.WithContext(ctx).
+		Where("item_id = ?", itemID).
+		Preload("User").
+		Order("created_at").
+		Find(&permissions).Error
+
+	return permissions, err
+}
+
+// GetEffectivePermission 查看用户对物品的有效权限，仅本人或物品管理者可查看
+func (s *permissionService) GetEffectivePermission(ctx context.Context, itemID, userID string) (*EffectivePermission, error) {
+	callerID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if callerID != userID {
+		if err := s.AuthorizeItem(ctx, itemID, ActionManage); err != nil {
+			return nil, err
+		}
+	}
+
+	effective, err := s.effectivePermission(ctx, itemID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if callerID == userID && effective.Level == "" {
+		return nil, ErrItemNotFound
+	}
+
+	return effective, nil
+}
+
+// effectivePermission 计算用户对物品的有效权限
+func (s *permissionService) effectivePermission(ctx context.Context, itemID, userID string) (*EffectivePermission, error) {
+	var item models.Item
+	if err := s.db.WithContext(ctx).Select("id", "room_id").First(&item, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	role, err := s.roomRole(ctx, userID, item.RoomID)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrItemNotFound
+	}
+
+	var grants []models.ItemPermission
+	if err := s.db.WithContext(ctx).Where("item_id = ?", itemID).Find(&grants).Error; err != nil {
+		return nil, err
+	}
+
+	return ResolveEffectivePermission(itemID, userID, role, grants), nil
+}
+
+// ResolveEffectivePermission 根据家庭角色和物品的全部单独授权计算有效权限：
+// 单独授权优先；存在owner级授权的物品为私有物品，无授权时不可见；否则由家庭角色决定
+func ResolveEffectivePermission(itemID, userID, role string, grants []models.ItemPermission) *EffectivePermission {
+	effective := &EffectivePermission{
+		ItemID:  itemID,
+		UserID:  userID,
+		Role:    role,
+		Actions: make(map[Action]bool),
+	}
+
+	for _, grant := range grants {
+		if grant.PermissionLevel == PermissionOwner {
+			effective.Private = true
+		}
+		if grant.UserID == userID {
+			level := grant.PermissionLevel
+			effective.GrantLevel = &level
+		}
+	}
+
+	switch {
+	case effective.GrantLevel != nil:
+		effective.Level = *effective.GrantLevel
+	case effective.Private:
+		effective.Level = ""
+	default:
+		effective.Level = roleItemPermission[role]
+	}
+
+	for action, allowed := range permissionActions[effective.Level] {
+		effective.Actions[action] = allowed
+	}
+
+	return effective
+}
+
+// roomRole 获取用户在房间所属家庭中的角色，房间为空或不可访问时返回空字符串
+func (s *permissionService) roomRole(ctx context.Context, userID string, roomID *string) (string, error) {
+	if roomID == nil {
+		return "", nil
+	}
+
+	var room models.Room
+	if err := s.db.WithContext(ctx).Select("id", "house_id").First(&room, "id = ?", *roomID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return s.houseRole(ctx, userID, room.HouseID)
+}
+
+// houseRole 获取用户在房屋所属家庭中的最高角色，不可访问或房屋在回收站中时返回空字符串
+func (s *permissionService) houseRole(ctx context.Context, userID, houseID string) (string, error) {
+	return familyRole(s.db.WithContext(ctx), userID, houseID, false)
+}
+
+// familyRole 获取用户在房屋所属家庭中的最高角色，includeTrashed为true时房屋在回收站中也返回角色
+func familyRole(db *gorm.DB, userID, houseID string, includeTrashed bool) (string, error) {
+	query := db.
+		Table("family_members fm").
+		Joins("JOIN family_houses fh ON fh.family_id = fm.family_id").
+		Where("fh.house_id = ? AND fm.user_id = ? AND fm.status = 1", houseID, userID)
+	if !includeTrashed {
+		query = query.Where("fh.house_id IN (SELECT id FROM houses WHERE deleted_at IS NULL)")
+	}
+
+	var roles []string
+	err := query.
+		Pluck("fm.role", &roles).Error
+	if err != nil {
+		return "", err
+	}
+
+	best := ""
+	for _, role := range roles {
+		if roleRank[role] > roleRank[best] {
+			best = role
+		}
+	}
+
+	return best, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+	"nookverse/internal/cache"
+	"nookverse/internal/models"
+)
+
+const bypassReadCacheContextKey contextKey = "bypass_read_cache"
+
+// ReadCache 房屋、房间和统计信息的读缓存。结果随用户的权限不同，缓存按用户区分，
+// 并以用户所在的家庭为标签：家庭内发生写入后，该家庭全部成员的缓存一并失效
+type ReadCache struct {
+	db    *gorm.DB
+	cache *cache.Cache
+}
+
+// NewReadCache 创建读缓存，c 未连接 Redis 时所有读取直接查询数据库
+func NewReadCache(db *gorm.DB, c *cache.Cache) *ReadCache {
+	return &ReadCache{db: db, cache: c}
+}
+
+// WithoutReadCache 标记请求不读取缓存，写入请求中读取的数据可能被回写，需读取最新数据
+func WithoutReadCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassReadCacheContextKey, true)
+}
+
+// InvalidateUser 使用户所在全部家庭的缓存失效，用户的一次写入可能涉及其任一家庭
+func (r *ReadCache) InvalidateUser(ctx context.Context, userID string) {
+	if r == nil {
+		return
+	}
+
+	familyIDs, err := userFamilyIDs(r.db.WithContext(ctx), userID)
+	if err != nil {
+		return
+	}
+	r.cache.Invalidate(ctx, familyTags(familyIDs)...)
+}
+
+// cachedRead 以当前用户和查询参数为键读取缓存，用户所在的家庭为标签
+func cachedRead[T any](ctx context.Context, r *ReadCache, name string, load func() (T, error), args ...any) (T, error) {
+	if r == nil || !r.cache.Enabled() {
+		return load()
+	}
+	if bypass, _ := ctx.Value(bypassReadCacheContextKey).(bool); bypass {
+		return load()
+	}
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return load()
+	}
+
+	familyIDs, err := userFamilyIDs(r.db.WithContext(ctx), userID)
+	if err != nil {
+		return load()
+	}
+	params, err := json.Marshal(args)
+	if err != nil {
+		return load()
+	}
+	return cache.Fetch(ctx, r.cache, name+":"+userID+":"+string(params), familyTags(familyIDs), load)
+}
+
+// userFamilyIDs 用户正常加入的家庭
+func userFamilyIDs(db *gorm.DB, userID string) ([]string, error) {
+	var familyIDs []string
+	err := db.Model(&models.FamilyMember{}).
+		Where("user_id = ? AND status = ?", userID, MemberStatusActive).
+		Pluck("family_id", &familyIDs).Error
+	return familyIDs, err
+}
+
+// familyTags 家庭的缓存标签
+func familyTags(familyIDs []string) []string {
+	tags := make([]string, 0, len(familyIDs))
+	for _, id := range familyIDs {
+		tags = append(tags, "family:"+id)
+	}
+	return tags
+}
+
+// cachedHouseService 在房屋服务前加读缓存：房屋详情、房屋内房间和统计信息
+type cachedHouseService struct {
+	HouseService
+	cache *ReadCache
+}
+
+// NewCachedHouseService 为房屋服务加读缓存，readCache 为空时返回原服务
+func NewCachedHouseService(inner HouseService, readCache *ReadCache) HouseService {
+	if readCache == nil {
+		return inner
+	}
+	return &cachedHouseService{HouseService: inner, cache: readCache}
+}
+
+// GetHouseByID 根据ID获取房屋，包含房间及其中的物品
+func (s *cachedHouseService) GetHouseByID(ctx context.Context, id string) (*models.House, error) {
+	return cachedRead(ctx, s.cache, "house", func() (*models.House, error) {
+		return s.HouseService.GetHouseByID(ctx, id)
+	}, id)
+}
+
+// GetRoomsByHouse 获取房屋内房间
+func (s *cachedHouseService) GetRoomsByHouse(ctx context.Context, houseID string) ([]models.Room, error) {
+	return cachedRead(ctx, s.cache, "house_rooms", func() ([]models.Room, error) {
+		return s.HouseService.GetRoomsByHouse(ctx, houseID)
+	}, houseID)
+}
+
+// GetHouseStatistics 获取房屋统计信息
+func (s *cachedHouseService) GetHouseStatistics(ctx context.Context) (*HouseStatistics, error) {
+	return cachedRead(ctx, s.cache, "house_statistics", func() (*HouseStatistics, error) {
+		return s.HouseService.GetHouseStatistics(ctx)
+	})
+}
+
+// cachedItemService 在物品服务前加读缓存：物品统计信息
+type cachedItemService struct {
+	ItemService
+	cache *ReadCache
+}
+
+// NewCachedItemService 为物品服务加读缓存，readCache 为空时返回原服务
+func NewCachedItemService(inner ItemService, readCache *ReadCache) ItemService {
+	if readCache == nil {
+		return inner
+	}
+	return &cachedItemService{ItemService: inner, cache: readCache}
+}
+
+// GetItemStatistics 获取物品统计信息
+func (s *cachedItemService) GetItemStatistics(ctx context.Context, userID string, filters ItemFilters) (*ItemStatistics, error) {
+	return cachedRead(ctx, s.cache, "item_statistics", func() (*ItemStatistics, error) {
+		return s.ItemService.GetItemStatistics(ctx, userID, filters)
+	}, userID, filters)
+}
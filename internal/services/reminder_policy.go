@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+// 提醒类型
+const (
+	ReminderTypeExpire      = "expire"
+	ReminderTypeMaintenance = "maintenance"
+	ReminderTypeWarranty    = "warranty"
+	ReminderTypeCustom      = "custom"
+)
+
+// 提醒策略限制
+const (
+	maxReminderPolicies = 20
+	maxPolicyDaysBefore = 365
+	policyReminderHour  = 9 // 自动提醒在当天9点触发
+	itemStatusDiscarded = "discarded"
+)
+
+// policyReminderTypes 可配置策略的提醒类型
+var policyReminderTypes = map[string]bool{
+	ReminderTypeExpire:   true,
+	ReminderTypeWarranty: true,
+}
+
+// ReminderPolicyService 家庭提醒策略服务接口
+type ReminderPolicyService interface {
+	ListPolicies(ctx context.Context, familyID string) ([]models.ReminderPolicy, error)
+	// ReplacePolicies 以给定策略整体替换家庭的提醒策略，并同步家庭内全部物品的自动提醒
+	ReplacePolicies(ctx context.Context, familyID string, policies []models.ReminderPolicy) ([]models.ReminderPolicy, error)
+}
+
+type reminderPolicyService struct {
+	db        *gorm.DB
+	notifiers *NotifierRegistry
+}
+
+// NewReminderPolicyService 创建提醒策略服务实例
+func NewReminderPolicyService(db *gorm.DB, notifiers *NotifierRegistry) ReminderPolicyService {
+	return &reminderPolicyService{db: db, notifiers: notifiers}
+}
+
+// ListPolicies 获取家庭的提醒策略
+func (s *reminderPolicyService) ListPolicies(ctx context.Context, familyID string) ([]models.ReminderPolicy, error) {
+	if err := s.findFamily(ctx, familyID); err != nil {
+		return nil, err
+	}
+
+	var policies []models.ReminderPolicy
+	err := s.db.WithContext(ctx).
+		Where("family_id = ?", familyID).
+		Order("reminder_type, days_before DESC").
+		Find(&policies).Error
+
+	return policies, err
+}
+
+// ReplacePolicies 替换家庭的提醒策略，未变化的策略保留原ID，已生成的提醒随之调整或取消
+func (s *reminderPolicyService) ReplacePolicies(ctx context.Context, familyID string, policies []models.ReminderPolicy) ([]models.ReminderPolicy, error) {
+	if err := s.findFamily(ctx, familyID); err != nil {
+		return nil, err
+	}
+	if len(policies) > maxReminderPolicies {
+		return nil, fmt.Errorf("每个家庭最多配置%d条提醒策略", maxReminderPolicies)
+	}
+
+	seen := make(map[string]bool, len(policies))
+	for i := range policies {
+		policy := &policies[i]
+		if !policyReminderTypes[policy.ReminderType] {
+			return nil, errors.New("提醒策略类型只能为expire或warranty")
+		}
+		if policy.DaysBefore < 0 || policy.DaysBefore > maxPolicyDaysBefore {
+			return nil, fmt.Errorf("提前天数必须在0到%d之间", maxPolicyDaysBefore)
+		}
+		key := fmt.Sprintf("%s/%d", policy.ReminderType, policy.DaysBefore)
+		if seen[key] {
+			return nil, errors.New("提醒策略重复")
+		}
+		seen[key] = true
+
+		policy.NotifyChannels = normalizeNotifyChannels(policy.NotifyChannels)
+		if err := s.notifiers.Validate(policy.NotifyChannels); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []models.ReminderPolicy
+		if err := tx.Where("family_id = ?", familyID).Find(&existing).Error; err != nil {
+			return err
+		}
+		existingByKey := make(map[string]models.ReminderPolicy, len(existing))
+		for _, policy := range existing {
+			existingByKey[fmt.Sprintf("%s/%d", policy.ReminderType, policy.DaysBefore)] = policy
+		}
+
+		for i := range policies {
+			policy := &policies[i]
+			policy.FamilyID = familyID
+			key := fmt.Sprintf("%s/%d", policy.ReminderType, policy.DaysBefore)
+
+			if previous, ok := existingByKey[key]; ok {
+				policy.ID = previous.ID
+				policy.CreatedAt = previous.CreatedAt
+				delete(existingByKey, key)
+				if err := tx.Save(policy).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			policy.ID = ""
+			if err := tx.Create(policy).Error; err != nil {
+				return err
+			}
+		}
+
+		// 删除不再使用的策略，并取消其尚未发送的提醒
+		var removed []string
+		for _, policy := range existingByKey {
+			removed = append(removed, policy.ID)
+		}
+		if len(removed) > 0 {
+			err := tx.Model(&models.Reminder{}).
+				Where("policy_id IN ? AND status = ?", removed, ReminderStatusPending).
+				Updates(map[string]any{"status": ReminderStatusCancelled, "next_attempt_at": nil}).Error
+			if err != nil {
+				return err
+			}
+			if err := tx.Delete(&models.ReminderPolicy{}, "id IN ?", removed).Error; err != nil {
+				return err
+			}
+		}
+
+		var itemIDs []string
+		err := tx.Model(&models.Item{}).
+			Where("room_id IN (SELECT r.id FROM rooms r JOIN family_houses fh ON fh.house_id = r.house_id WHERE fh.family_id = ?)", familyID).
+			Pluck("id", &itemIDs).Error
+		if err != nil {
+			return err
+		}
+		return syncPolicyReminders(tx, itemIDs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ListPolicies(ctx, familyID)
+}
+
+// findFamily 校验家庭存在且当前用户为家庭成员
+func (s *reminderPolicyService) findFamily(ctx context.Context, familyID string) error {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return err
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Family{}).Scopes(scopeFamilies(userID)).Where("id = ?", familyID).Count(&count)
+	if count == 0 {
+		return ErrFamilyNotFound
+	}
+	return nil
+}
+
+// syncSubtreeReminders 同步物品及其容器内全部物品的自动提醒，用于物品移动到其他房屋后
+func syncSubtreeReminders(tx *gorm.DB, itemID string) error {
+	var itemIDs []string
+	err := tx.Table("item_hierarchy").
+		Where("ancestor_id = ?", itemID).
+		Pluck("descendant_id", &itemIDs).Error
+	if err != nil {
+		return err
+	}
+	return syncPolicyReminders(tx, itemIDs...)
+}
+
+// syncPolicyReminders 按物品所在家庭的提醒策略创建、调整或取消自动提醒
+// 物品被丢弃、日期被清空或到期日已不足提前天数时取消未发送的提醒；已发送或被手动取消的同一时间提醒不会重复创建
+func syncPolicyReminders(tx *gorm.DB, itemIDs ...string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	var items []models.Item
+	if err := tx.Where("id IN ?", itemIDs).Find(&items).Error; err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range items {
+		item := &items[i]
+
+		var policies []models.ReminderPolicy
+		if item.RoomID != nil {
+			err := tx.Where("family_id IN (SELECT fh.family_id FROM family_houses fh JOIN rooms r ON r.house_id = fh.house_id WHERE r.id = ?)", *item.RoomID).
+				Find(&policies).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		desired := make(map[string]*models.Reminder, len(policies))
+		for _, policy := range policies {
+			reminder := PolicyReminder(item, policy)
+			if reminder != nil && reminder.TriggerTime.After(now) {
+				desired[policy.ID] = reminder
+			}
+		}
+
+		var existing []models.Reminder
+		if err := tx.Where("item_id = ? AND policy_id IS NOT NULL", item.ID).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		for _, reminder := range existing {
+			want, ok := desired[*reminder.PolicyID]
+			// 比较计划时间而非提醒时间，稍后提醒推迟的时间不会被同步覆盖
+			scheduled := reminder.TriggerTime
+			if reminder.OccurrenceAt != nil {
+				scheduled = *reminder.OccurrenceAt
+			}
+			switch {
+			case reminder.Status == ReminderStatusPending && !ok:
+				err := tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).
+					Updates(map[string]any{"status": ReminderStatusCancelled, "next_attempt_at": nil}).Error
+				if err != nil {
+					return err
+				}
+			case reminder.Status == ReminderStatusPending:
+				delete(desired, *reminder.PolicyID)
+				if scheduled.Equal(want.TriggerTime) && reminder.Message == want.Message &&
+					slices.Equal(reminder.NotifyChannels, want.NotifyChannels) {
+					continue
+				}
+				updates := map[string]any{
+					"message":         want.Message,
+					"notify_channels": want.NotifyChannels,
+				}
+				if !scheduled.Equal(want.TriggerTime) {
+					updates["trigger_time"] = want.TriggerTime
+					updates["occurrence_at"] = want.TriggerTime
+					updates["attempts"] = 0
+					updates["next_attempt_at"] = nil
+					updates["last_error"] = nil
+				}
+				err := tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).Updates(updates).Error
+				if err != nil {
+					return err
+				}
+			case ok && scheduled.Equal(want.TriggerTime):
+				delete(desired, *reminder.PolicyID)
+			}
+		}
+
+		for _, reminder := range desired {
+			if err := tx.Create(reminder).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// PolicyReminder 按策略为物品生成的提醒，物品没有对应日期或已丢弃时返回nil
+func PolicyReminder(item *models.Item, policy models.ReminderPolicy) *models.Reminder {
+	if item.Status == itemStatusDiscarded {
+		return nil
+	}
+
+	var due time.Time
+	var message string
+	switch policy.ReminderType {
+	case ReminderTypeExpire:
+		if item.ExpireDate == nil {
+			return nil
+		}
+		due = *item.ExpireDate
+		message = fmt.Sprintf("%s将于%s过期", item.Name, due.Format("2006-01-02"))
+	case ReminderTypeWarranty:
+		if item.PurchaseDate == nil || item.WarrantyPeriod == nil || *item.WarrantyPeriod <= 0 {
+			return nil
+		}
+		due = item.PurchaseDate.AddDate(0, *item.WarrantyPeriod, 0)
+		message = fmt.Sprintf("%s的保修将于%s到期", item.Name, due.Format("2006-01-02"))
+	default:
+		return nil
+	}
+
+	policyID := policy.ID
+	triggerTime := time.Date(due.Year(), due.Month(), due.Day()-policy.DaysBefore, policyReminderHour, 0, 0, 0, due.Location())
+	return &models.Reminder{
+		ItemID:         item.ID,
+		ReminderType:   policy.ReminderType,
+		TriggerTime:    triggerTime,
+		OccurrenceAt:   &triggerTime,
+		Message:        message,
+		Status:         ReminderStatusPending,
+		NotifyChannels: policy.NotifyChannels,
+		PolicyID:       &policyID,
+	}
+}
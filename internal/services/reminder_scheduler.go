@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+)
+
+// 提醒状态
+const (
+	ReminderStatusPending   = "pending"
+	ReminderStatusSent      = "sent"
+	ReminderStatusCompleted = "completed"
+	ReminderStatusCancelled = "cancelled"
+	ReminderStatusFailed    = "failed" // 重试次数用尽
+)
+
+// 提醒调度参数
+const (
+	reminderPollInterval = 30 * time.Second
+	reminderBatchSize    = 50
+	reminderLease        = 5 * time.Minute // 领取后在此时间内其他副本不会重复领取
+	reminderMaxAttempts  = 5
+	reminderBaseBackoff  = time.Minute
+	reminderMaxBackoff   = time.Hour
+)
+
+// ReminderScheduler 提醒调度器：定期领取到期的待发送提醒，逐个渠道发送并在失败时退避重试
+// 多副本部署时通过行锁（FOR UPDATE SKIP LOCKED）和领取租约保证同一提醒只被一个副本处理
+type ReminderScheduler struct {
+	db        *gorm.DB
+	notifiers *NotifierRegistry
+	wg        sync.WaitGroup
+}
+
+// NewReminderScheduler 创建提醒调度器
+func NewReminderScheduler(db *gorm.DB, notifiers *NotifierRegistry) *ReminderScheduler {
+	return &ReminderScheduler{db: db, notifiers: notifiers}
+}
+
+// Start 启动后台调度，ctx取消后处理完当前批次即退出
+func (s *ReminderScheduler) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(reminderPollInterval)
+		defer ticker.Stop()
+
+		for {
+			// 一批处理满时立即继续，避免积压
+			for {
+				processed, err := s.RunOnce(ctx)
+				if err != nil && ctx.Err() == nil {
+					log.Printf("Reminder scheduler: %v", err)
+				}
+				if processed < reminderBatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait 等待调度器退出
+func (s *ReminderScheduler) Wait() {
+	s.wg.Wait()
+}
+
+// RunOnce 领取并发送一批到期提醒，返回处理的提醒数
+func (s *ReminderScheduler) RunOnce(ctx context.Context) (int, error) {
+	reminders, err := s.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range reminders {
+		if err := s.dispatch(ctx, &reminders[i]); err != nil {
+			return i + 1, err
+		}
+	}
+
+	return len(reminders), nil
+}
+
+// claim 锁定到期提醒并写入领取租约，租约到期前其他副本不会再次领取；进程中途退出时租约到期后自动重试
+func (s *ReminderScheduler) claim(ctx context.Context) ([]models.Reminder, error) {
+	now := time.Now()
+	var ids []string
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Reminder{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND trigger_time <= ?", ReminderStatusPending, now).
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			// 回收站中物品的提醒暂停发送，恢复后继续
+			Where("item_id IN (SELECT id FROM items WHERE deleted_at IS NULL)").
+			Order("trigger_time").
+			Limit(reminderBatchSize).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		return tx.Model(&models.Reminder{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(reminderLease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var reminders []models.Reminder
+	err = s.db.WithContext(ctx).
+		Preload("Item").
+		Where("id IN ?", ids).
+		Order("trigger_time").
+		Find(&reminders).Error
+
+	return reminders, err
+}
+
+// dispatch 向提醒的每个渠道发送通知，全部成功后标记为已发送，否则安排重试
+func (s *ReminderScheduler) dispatch(ctx context.Context, reminder *models.Reminder) error {
+	var delivered []string
+	err := s.db.WithContext(ctx).Model(&models.ReminderDelivery{}).
+		Where("reminder_id = ?", reminder.ID).
+		Pluck("channel", &delivered).Error
+	if err != nil {
+		return err
+	}
+	done := make(map[string]bool, len(delivered))
+	for _, channel := range delivered {
+		done[channel] = true
+	}
+
+	channels := reminder.NotifyChannels
+	if len(channels) == 0 {
+		channels = []string{NotifyChannelApp}
+	}
+
+	var failures []error
+	for _, channel := range channels {
+		if done[channel] {
+			continue
+		}
+
+		notifier, ok := s.notifiers.Get(channel)
+		if !ok {
+			failures = append(failures, fmt.Errorf("%s: unsupported channel", channel))
+			continue
+		}
+		if err := notifier.Notify(ctx, reminder); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", channel, err))
+			continue
+		}
+
+		err := s.db.WithContext(ctx).
+			Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.ReminderDelivery{ReminderID: reminder.ID, Channel: channel, SentAt: time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		done[channel] = true
+	}
+
+	// 服务关闭导致的失败不计入重试次数，租约到期后重新发送
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	// 发送期间提醒被完成、取消、推迟或修改时状态已变化或领取租约已被清空，不再覆盖新的状态
+	now := time.Now()
+	if len(failures) == 0 {
+		return s.db.WithContext(ctx).Model(&models.Reminder{}).
+			Where("id = ? AND status = ? AND next_attempt_at IS NOT NULL", reminder.ID, ReminderStatusPending).
+			Updates(map[string]any{
+				"status":          ReminderStatusSent,
+				"sent_at":         now,
+				"next_attempt_at": nil,
+				"last_error":      nil,
+			}).Error
+	}
+
+	attempts := reminder.Attempts + 1
+	updates := map[string]any{
+		"attempts":        attempts,
+		"last_error":      errors.Join(failures...).Error(),
+		"next_attempt_at": now.Add(ReminderRetryDelay(attempts)),
+	}
+	if attempts >= reminderMaxAttempts {
+		updates["status"] = ReminderStatusFailed
+		updates["next_attempt_at"] = nil
+	}
+
+	return s.db.WithContext(ctx).Model(&models.Reminder{}).
+		Where("id = ? AND status = ? AND next_attempt_at IS NOT NULL", reminder.ID, ReminderStatusPending).
+		Updates(updates).Error
+}
+
+// ReminderRetryDelay 第attempts次发送失败后的重试间隔，按指数退避并设置上限
+func ReminderRetryDelay(attempts int) time.Duration {
+	delay := reminderBaseBackoff
+	for i := 1; i < attempts && delay < reminderMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, reminderMaxBackoff)
+}
@@ -0,0 +1,547 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/models"
+	"nookverse/internal/recurrence"
+)
+
+// maxSnooze 稍后提醒的最长推迟时间
+const maxSnooze = 365 * 24 * time.Hour
+
+// 提醒相关错误
+var (
+	ErrReminderNotFound          = notFoundError("提醒不存在")
+	ErrInvalidReminderTransition = errors.New("提醒当前状态不允许该操作")
+)
+
+// ReminderStatuses 全部提醒状态
+var ReminderStatuses = []string{
+	ReminderStatusPending,
+	ReminderStatusSent,
+	ReminderStatusFailed,
+	ReminderStatusCompleted,
+	ReminderStatusCancelled,
+}
+
+// reminderTransitions 提醒状态机：pending 由调度器发送后变为 sent 或 failed；
+// 未结束的提醒可以重新安排（回到 pending）、完成或取消；completed 和 cancelled 为终态
+var reminderTransitions = map[string][]string{
+	ReminderStatusPending: {ReminderStatusPending, ReminderStatusSent, ReminderStatusFailed, ReminderStatusCompleted, ReminderStatusCancelled},
+	ReminderStatusSent:    {ReminderStatusPending, ReminderStatusCompleted, ReminderStatusCancelled},
+	ReminderStatusFailed:  {ReminderStatusPending, ReminderStatusCompleted, ReminderStatusCancelled},
+}
+
+// openReminderStatuses 未结束的提醒状态，已到时间仍处于这些状态的提醒视为逾期
+var openReminderStatuses = []string{ReminderStatusPending, ReminderStatusSent, ReminderStatusFailed}
+
+// recurringReminderTypes 允许设置重复规则的提醒类型，过期和保修提醒由物品日期决定
+var recurringReminderTypes = map[string]bool{
+	ReminderTypeMaintenance: true,
+	ReminderTypeCustom:      true,
+}
+
+// ReminderService 提醒服务接口
+type ReminderService interface {
+	ListReminders(ctx context.Context, filters ReminderFilters) ([]models.Reminder, int64, error)
+	GetReminder(ctx context.Context, id string) (*models.Reminder, error)
+	// UpdateReminder 修改提醒的时间、内容、渠道或重复规则，修改时间后重新进入待发送状态
+	UpdateReminder(ctx context.Context, id string, update ReminderUpdate) (*models.Reminder, error)
+	// CancelReminder 取消提醒，重复提醒的后续安排一并取消
+	CancelReminder(ctx context.Context, id string) (*models.Reminder, error)
+	// CompleteReminder 完成提醒；重复提醒记录本次完成后安排下一次
+	CompleteReminder(ctx context.Context, id string, note *string) (*models.Reminder, error)
+	// SnoozeReminder 推迟提醒到指定时间，重复提醒的后续安排不受影响
+	SnoozeReminder(ctx context.Context, id string, until time.Time) (*models.Reminder, error)
+	// ListCompletions 物品的提醒完成历史，最近的在前
+	ListCompletions(ctx context.Context, itemID string, page, pageSize int) ([]models.ReminderCompletion, int64, error)
+}
+
+// ReminderFilters 提醒查询过滤条件
+type ReminderFilters struct {
+	Statuses     []string
+	ReminderType *string
+	ItemID       *string
+	HouseID      *string
+	From         *time.Time // 提醒时间下限（含）
+	To           *time.Time // 提醒时间上限（不含）
+	Overdue      bool       // 仅返回已到提醒时间但未完成或取消的提醒
+	Page         int
+	PageSize     int
+}
+
+// ReminderUpdate 提醒修改内容，nil 字段保持不变
+type ReminderUpdate struct {
+	TriggerTime    *time.Time
+	Message        *string
+	NotifyChannels []string
+	Recurrence     *string // 空字符串表示取消重复
+}
+
+type reminderService struct {
+	db        *gorm.DB
+	notifiers *NotifierRegistry
+}
+
+// NewReminderService 创建提醒服务实例
+func NewReminderService(db *gorm.DB, notifiers *NotifierRegistry) ReminderService {
+	return &reminderService{db: db, notifiers: notifiers}
+}
+
+// ListReminders 获取当前用户可见物品的提醒，按提醒时间排序
+func (s *reminderService) ListReminders(ctx context.Context, filters ReminderFilters) ([]models.Reminder, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Reminder{}).Scopes(scopeReminders(userID))
+
+	if len(filters.Statuses) > 0 {
+		query = query.Where("reminders.status IN ?", filters.Statuses)
+	}
+	if filters.ReminderType != nil {
+		query = query.Where("reminders.reminder_type = ?", *filters.ReminderType)
+	}
+	if filters.ItemID != nil {
+		query = query.Where("reminders.item_id = ?", *filters.ItemID)
+	}
+	if filters.HouseID != nil {
+		query = query.Where("reminders.item_id IN (SELECT i.id FROM items i JOIN rooms r ON r.id = i.room_id WHERE r.house_id = ?)", *filters.HouseID)
+	}
+	if filters.From != nil {
+		query = query.Where("reminders.trigger_time >= ?", *filters.From)
+	}
+	if filters.To != nil {
+		query = query.Where("reminders.trigger_time < ?", *filters.To)
+	}
+	if filters.Overdue {
+		query = query.Where("reminders.status IN ? AND reminders.trigger_time <= ?", openReminderStatuses, time.Now())
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+	if filters.PageSize <= 0 {
+		filters.PageSize = 20
+	}
+
+	var reminders []models.Reminder
+	err = query.
+		Preload("Item").
+		Order("reminders.trigger_time").
+		Offset((filters.Page - 1) * filters.PageSize).
+		Limit(filters.PageSize).
+		Find(&reminders).Error
+
+	return reminders, total, err
+}
+
+// GetReminder 获取当前用户可见物品的提醒
+func (s *reminderService) GetReminder(ctx context.Context, id string) (*models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var reminder models.Reminder
+	err = s.db.WithContext(ctx).
+		Scopes(scopeReminders(userID)).
+		Preload("Item").
+		First(&reminder, "reminders.id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReminderNotFound
+		}
+		return nil, err
+	}
+
+	return &reminder, nil
+}
+
+// UpdateReminder 修改未结束的提醒；修改时间会清空发送结果并在新时间重新发送，重复提醒以新时间作为规则起点
+// 自动提醒的时间由物品日期和家庭策略决定，只能修改内容和渠道
+func (s *reminderService) UpdateReminder(ctx context.Context, id string, update ReminderUpdate) (*models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.TriggerTime != nil && !update.TriggerTime.After(time.Now()) {
+		return nil, errors.New("提醒时间不能早于当前时间")
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reminder, err := lockReminder(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if len(reminderTransitions[reminder.Status]) == 0 {
+			return fmt.Errorf("%w: %s的提醒不能修改", ErrInvalidReminderTransition, reminder.Status)
+		}
+		if reminder.PolicyID != nil && (update.TriggerTime != nil || update.Recurrence != nil) {
+			return errors.New("自动提醒的时间由物品日期和家庭提醒策略决定，不能直接修改")
+		}
+
+		updates := make(map[string]any)
+		if update.Message != nil {
+			message := strings.TrimSpace(*update.Message)
+			if message == "" {
+				return errors.New("提醒内容不能为空")
+			}
+			updates["message"] = message
+		}
+		if update.NotifyChannels != nil {
+			channels := normalizeNotifyChannels(update.NotifyChannels)
+			if err := s.notifiers.Validate(channels); err != nil {
+				return err
+			}
+			updates["notify_channels"] = channels
+		}
+
+		recurring := reminder.Recurrence != nil
+		if update.Recurrence != nil {
+			if *update.Recurrence == "" {
+				recurring = false
+				updates["recurrence"] = nil
+				updates["recurrence_start"] = nil
+				updates["occurrence_at"] = nil
+			} else {
+				normalized, err := parseRecurrence(reminder.ReminderType, *update.Recurrence)
+				if err != nil {
+					return err
+				}
+				start := reminder.TriggerTime
+				if reminder.OccurrenceAt != nil {
+					start = *reminder.OccurrenceAt
+				}
+				recurring = true
+				updates["recurrence"] = normalized
+				updates["recurrence_start"] = start
+				updates["occurrence_at"] = start
+			}
+		}
+
+		if update.TriggerTime == nil {
+			if len(updates) == 0 {
+				return nil
+			}
+			if err := tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			return recordReminderOperation(tx, reminder, OperationUpdate, "更新提醒: "+reminder.Message, nil)
+		}
+
+		for key, value := range rearmReminder(*update.TriggerTime) {
+			updates[key] = value
+		}
+		if recurring {
+			updates["recurrence_start"] = *update.TriggerTime
+			updates["occurrence_at"] = *update.TriggerTime
+		}
+		if err := rescheduleReminder(tx, reminder.ID, updates); err != nil {
+			return err
+		}
+		return recordReminderOperation(tx, reminder, OperationUpdate, "更新提醒: "+reminder.Message, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReminder(ctx, id)
+}
+
+// CancelReminder 取消未结束的提醒
+func (s *reminderService) CancelReminder(ctx context.Context, id string) (*models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reminder, err := lockReminder(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkReminderTransition(reminder.Status, ReminderStatusCancelled); err != nil {
+			return err
+		}
+		err = tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).
+			Updates(map[string]any{"status": ReminderStatusCancelled, "next_attempt_at": nil}).Error
+		if err != nil {
+			return err
+		}
+		return recordReminderOperation(tx, reminder, OperationCancel, "取消提醒: "+reminder.Message, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReminder(ctx, id)
+}
+
+// CompleteReminder 完成提醒并写入完成记录；重复提醒跳过已错过的时间，重新安排到当前时间之后的下一次，规则结束时标记为已完成
+func (s *reminderService) CompleteReminder(ctx context.Context, id string, note *string) (*models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reminder, err := lockReminder(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkReminderTransition(reminder.Status, ReminderStatusCompleted); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		occurrence := reminder.TriggerTime
+		if reminder.OccurrenceAt != nil {
+			occurrence = *reminder.OccurrenceAt
+		}
+
+		err = tx.Omit(clause.Associations).Create(&models.ReminderCompletion{
+			ReminderID:   &reminder.ID,
+			ItemID:       reminder.ItemID,
+			UserID:       userID,
+			ReminderType: reminder.ReminderType,
+			Message:      reminder.Message,
+			OccurrenceAt: occurrence,
+			CompletedAt:  now,
+			Note:         note,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		next, ok, err := NextOccurrence(reminder, later(occurrence, now))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			err = tx.Model(&models.Reminder{}).Where("id = ?", reminder.ID).
+				Updates(map[string]any{"status": ReminderStatusCompleted, "next_attempt_at": nil}).Error
+		} else {
+			updates := rearmReminder(next)
+			updates["occurrence_at"] = next
+			err = rescheduleReminder(tx, reminder.ID, updates)
+		}
+		if err != nil {
+			return err
+		}
+
+		var extra map[string]any
+		if note != nil {
+			extra = map[string]any{"note": *note}
+		}
+		return recordReminderOperation(tx, reminder, OperationComplete, "完成提醒: "+reminder.Message, extra)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReminder(ctx, id)
+}
+
+// SnoozeReminder 推迟提醒，已发送的提醒会在新时间重新发送
+func (s *reminderService) SnoozeReminder(ctx context.Context, id string, until time.Time) (*models.Reminder, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !until.After(now) {
+		return nil, errors.New("稍后提醒时间必须晚于当前时间")
+	}
+	if until.Sub(now) > maxSnooze {
+		return nil, errors.New("稍后提醒时间不能超过一年")
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		reminder, err := lockReminder(tx, userID, id)
+		if err != nil {
+			return err
+		}
+		if err := checkReminderTransition(reminder.Status, ReminderStatusPending); err != nil {
+			return err
+		}
+		if err := rescheduleReminder(tx, reminder.ID, rearmReminder(until)); err != nil {
+			return err
+		}
+		return recordReminderOperation(tx, reminder, OperationSnooze, "稍后提醒: "+reminder.Message, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReminder(ctx, id)
+}
+
+// ListCompletions 物品的提醒完成历史
+func (s *reminderService) ListCompletions(ctx context.Context, itemID string, page, pageSize int) ([]models.ReminderCompletion, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.Item{}).Scopes(scopeItems(userID)).Where("id = ?", itemID).Count(&count)
+	if count == 0 {
+		return nil, 0, ErrItemNotFound
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.ReminderCompletion{}).Where("item_id = ?", itemID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	var completions []models.ReminderCompletion
+	err = query.
+		Preload("User").
+		Order("completed_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&completions).Error
+
+	return completions, total, err
+}
+
+// NextOccurrence 重复提醒在 after 之后的下一次计划时间，非重复提醒或规则已结束时返回false
+func NextOccurrence(reminder *models.Reminder, after time.Time) (time.Time, bool, error) {
+	if reminder.Recurrence == nil {
+		return time.Time{}, false, nil
+	}
+
+	rule, err := recurrence.Parse(*reminder.Recurrence)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	start := reminder.TriggerTime
+	if reminder.RecurrenceStart != nil {
+		start = *reminder.RecurrenceStart
+	}
+
+	next, ok := rule.After(start, after)
+	return next, ok, nil
+}
+
+// CanTransitionReminder 提醒能否从状态 from 变为 to
+func CanTransitionReminder(from, to string) bool {
+	return slices.Contains(reminderTransitions[from], to)
+}
+
+// checkReminderTransition 校验状态变化，不允许时返回 ErrInvalidReminderTransition
+func checkReminderTransition(from, to string) error {
+	if !CanTransitionReminder(from, to) {
+		return fmt.Errorf("%w: %s → %s", ErrInvalidReminderTransition, from, to)
+	}
+	return nil
+}
+
+// parseRecurrence 校验提醒类型允许重复并返回规范化的规则文本
+func parseRecurrence(reminderType, text string) (string, error) {
+	if !recurringReminderTypes[reminderType] {
+		return "", errors.New("只有maintenance和custom类型的提醒可以设置重复规则")
+	}
+	rule, err := recurrence.Parse(text)
+	if err != nil {
+		return "", err
+	}
+	return rule.String(), nil
+}
+
+// lockReminder 锁定提醒，避免与调度器或并发请求同时修改
+func lockReminder(tx *gorm.DB, userID, id string) (*models.Reminder, error) {
+	var reminder models.Reminder
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Scopes(scopeReminders(userID)).
+		First(&reminder, "reminders.id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReminderNotFound
+		}
+		return nil, err
+	}
+	return &reminder, nil
+}
+
+// recordReminderOperation 记录提醒操作日志，字段差异由修改前的提醒与事务内的最新数据比较得出，extra 附加到新值中
+func recordReminderOperation(tx *gorm.DB, before *models.Reminder, operation, description string, extra map[string]any) error {
+	var after models.Reminder
+	if err := tx.First(&after, "id = ?", before.ID).Error; err != nil {
+		return err
+	}
+
+	oldValue, newValue := AuditDiff(before, &after)
+	if len(extra) > 0 && newValue == nil {
+		newValue = make(map[string]any, len(extra))
+	}
+	for key, value := range extra {
+		newValue[key] = value
+	}
+
+	return recordOperation(tx, operationEntry{
+		EntityType:  EntityReminder,
+		EntityID:    before.ID,
+		ItemID:      &before.ItemID,
+		HouseID:     itemHouseID(tx, before.ItemID),
+		Operation:   operation,
+		Description: description,
+		OldValue:    oldValue,
+		NewValue:    newValue,
+	})
+}
+
+// rearmReminder 重新进入待发送状态所需的字段，清空上一次的发送结果
+func rearmReminder(triggerTime time.Time) map[string]any {
+	return map[string]any{
+		"status":          ReminderStatusPending,
+		"trigger_time":    triggerTime,
+		"attempts":        0,
+		"next_attempt_at": nil,
+		"last_error":      nil,
+		"sent_at":         nil,
+	}
+}
+
+// rescheduleReminder 更新提醒并清除渠道发送记录，使各渠道在新时间重新发送
+func rescheduleReminder(tx *gorm.DB, reminderID string, updates map[string]any) error {
+	if err := tx.Where("reminder_id = ?", reminderID).Delete(&models.ReminderDelivery{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Reminder{}).Where("id = ?", reminderID).Updates(updates).Error
+}
+
+// later 返回两个时间中较晚的一个
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
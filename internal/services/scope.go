@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+type contextKey string
+
+const (
+	userIDContextKey     contextKey = "user_id"
+	clientMetaContextKey contextKey = "client_meta"
+)
+
+// accessibleHousesSQL 用户所在家庭拥有的房屋ID，不含回收站中的房屋
+const accessibleHousesSQL = `SELECT fh.house_id FROM family_houses fh
+	JOIN family_members fm ON fm.family_id = fh.family_id
+	JOIN houses h ON h.id = fh.house_id AND h.deleted_at IS NULL
+	WHERE fm.user_id = @uid AND fm.status = 1`
+
+// accessibleRoomsSQL 用户可访问房屋下的房间ID，不含回收站中的房间
+const accessibleRoomsSQL = `SELECT r.id FROM rooms r WHERE r.deleted_at IS NULL AND r.house_id IN (` + accessibleHousesSQL + `)`
+
+// privateItemVisibleSQL 物品 i 对用户可见：私有物品（存在owner级授权）仅对获得授权的用户可见
+const privateItemVisibleSQL = `(NOT EXISTS (SELECT 1 FROM item_permissions ip WHERE ip.item_id = i.id AND ip.permission_level = 'owner')
+	OR EXISTS (SELECT 1 FROM item_permissions ip WHERE ip.item_id = i.id AND ip.user_id = @uid))`
+
+// accessibleItemsSQL 用户可访问房间内的可见物品ID，不含回收站中的物品
+const accessibleItemsSQL = `SELECT i.id FROM items i WHERE i.deleted_at IS NULL AND i.room_id IN (` + accessibleRoomsSQL + `)
+	AND ` + privateItemVisibleSQL
+
+// memberItemsSQL 用户所在家庭的可见物品ID，包含回收站中的物品、房间和房屋，用于查看已删除对象的记录
+const memberItemsSQL = `SELECT i.id FROM items i
+	JOIN rooms r ON r.id = i.room_id
+	JOIN family_houses fh ON fh.house_id = r.house_id
+	JOIN family_members fm ON fm.family_id = fh.family_id
+	WHERE fm.user_id = @uid AND fm.status = 1 AND ` + privateItemVisibleSQL
+
+// visibleCategoriesSQL 用户可见的分类：系统分类、自己创建的分类以及同一家庭成员创建的分类
+const visibleCategoriesSQL = `categories.is_system = TRUE OR categories.created_by = @uid
+	OR categories.created_by IN (SELECT fm2.user_id FROM family_members fm2
+	JOIN family_members fm ON fm.family_id = fm2.family_id
+	WHERE fm.user_id = @uid AND fm.status = 1 AND fm2.status = 1)`
+
+// WithUserID 将当前用户ID写入上下文
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// WithClientMeta 将发起请求的客户端信息写入上下文，写入操作日志时使用
+func WithClientMeta(ctx context.Context, meta ClientMeta) context.Context {
+	return context.WithValue(ctx, clientMetaContextKey, meta)
+}
+
+// clientMetaFromContext 从上下文读取客户端信息，未设置时返回零值
+func clientMetaFromContext(ctx context.Context) ClientMeta {
+	meta, _ := ctx.Value(clientMetaContextKey).(ClientMeta)
+	return meta
+}
+
+// UserIDFromContext 从上下文读取当前用户ID
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(string)
+	return userID, ok && userID != ""
+}
+
+// currentUserID 获取当前用户ID，未登录时返回错误
+func currentUserID(ctx context.Context) (string, error) {
+	userID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return "", ErrUnauthenticated
+	}
+	return userID, nil
+}
+
+// scopeFamilies 限定为用户正常加入的家庭
+func scopeFamilies(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("families.id IN (SELECT family_id FROM family_members WHERE user_id = @uid AND status = 1)", sql.Named("uid", userID))
+	}
+}
+
+// scopeHouses 限定为用户家庭下的房屋
+func scopeHouses(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("houses.id IN ("+accessibleHousesSQL+")", sql.Named("uid", userID))
+	}
+}
+
+// scopeRooms 限定为用户家庭房屋下的房间
+func scopeRooms(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("rooms.house_id IN ("+accessibleHousesSQL+")", sql.Named("uid", userID))
+	}
+}
+
+// scopeItems 限定为用户家庭房屋内、且对用户可见的物品
+func scopeItems(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("items.id IN ("+accessibleItemsSQL+")", sql.Named("uid", userID))
+	}
+}
+
+// scopeReminders 限定为用户可访问物品的提醒
+func scopeReminders(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("reminders.item_id IN ("+accessibleItemsSQL+")", sql.Named("uid", userID))
+	}
+}
+
+// scopeCategories 限定为用户可见的分类
+func scopeCategories(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("("+visibleCategoriesSQL+")", sql.Named("uid", userID))
+	}
+}
+
+// resolveFamily 校验用户属于指定家庭；未指定时使用用户唯一所属的家庭
+func resolveFamily(ctx context.Context, db *gorm.DB, userID, familyID string) (string, error) {
+	if familyID != "" {
+		var count int64
+		db.WithContext(ctx).Model(&models.FamilyMember{}).
+			Where("family_id = ? AND user_id = ? AND status = 1", familyID, userID).
+			Count(&count)
+		if count == 0 {
+			return "", ErrFamilyNotFound
+		}
+		return familyID, nil
+	}
+
+	var familyIDs []string
+	err := db.WithContext(ctx).Model(&models.FamilyMember{}).
+		Where("user_id = ? AND status = 1", userID).
+		Pluck("family_id", &familyIDs).Error
+	if err != nil {
+		return "", err
+	}
+
+	switch len(familyIDs) {
+	case 0:
+		return "", errors.New("当前用户不属于任何家庭")
+	case 1:
+		return familyIDs[0], nil
+	default:
+		return "", errors.New("用户属于多个家庭，请指定家庭ID")
+	}
+}
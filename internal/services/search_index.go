@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"html"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchDocumentSQL 物品的全文检索文档：名称权重最高，其次是品牌、型号和描述，再次是标签和自定义位置，
+// 扩展属性的值最低。与 db/init.sql 中 update_search_index 触发器的文档保持一致
+const searchDocumentSQL = `setweight(to_tsvector('simple', COALESCE(items.name, '')), 'A') ||
+	setweight(to_tsvector('simple', COALESCE(items.brand, '') || ' ' || COALESCE(items.model, '')), 'B') ||
+	setweight(to_tsvector('simple', COALESCE(items.description, '')), 'B') ||
+	setweight(to_tsvector('simple', array_to_string(COALESCE(items.labels, '{}'), ' ')), 'C') ||
+	setweight(to_tsvector('simple', COALESCE(items.custom_position, '')), 'C') ||
+	setweight(jsonb_to_tsvector('simple', COALESCE(items.attributes, '{}'), '["string", "numeric"]'), 'D')`
+
+// searchTextSQL 生成高亮片段所用的物品文本，包含文档中的全部字段
+const searchTextSQL = `concat_ws(' ', items.name, items.brand, items.model, items.description,
+	array_to_string(items.labels, ' '), items.custom_position,
+	CASE WHEN jsonb_typeof(items.attributes) = 'object'
+		THEN (SELECT string_agg(value, ' ') FROM jsonb_each_text(items.attributes)) END)`
+
+// insertSearchIndexSQL 根据物品当前内容写入索引，调用方追加限定物品的条件
+const insertSearchIndexSQL = `INSERT INTO search_index (item_id, searchable_content)
+	SELECT items.id, ` + searchDocumentSQL + ` FROM items`
+
+// 高亮片段中匹配词的临时标记，转义物品文本后替换为 <mark> 标签
+const (
+	snippetStartSel = "\x02"
+	snippetStopSel  = "\x03"
+)
+
+// snippetOptionsSQL ts_headline 选项：最多两段，每段不超过20个词
+const snippetOptionsSQL = `'StartSel=` + snippetStartSel + `, StopSel=` + snippetStopSel + `, MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" … "'`
+
+// searchMatch 搜索命中的物品ID及其相关度和高亮片段
+type searchMatch struct {
+	ID      string
+	Rank    float64
+	Snippet string
+}
+
+// snippetReplacer 将临时标记替换为 <mark> 标签
+var snippetReplacer = strings.NewReplacer(snippetStartSel, "<mark>", snippetStopSel, "</mark>")
+
+// syncSearchIndex 按物品当前内容重写其全文检索索引。由 db/init.sql 建库时触发器已经写入同样的内容，
+// 仅由 AutoMigrate 建表时没有触发器，靠这里保持同步
+func syncSearchIndex(tx *gorm.DB, itemIDs ...string) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+	if err := tx.Exec(`DELETE FROM search_index WHERE item_id IN ?`, itemIDs).Error; err != nil {
+		return err
+	}
+	return tx.Exec(insertSearchIndexSQL+` WHERE items.id IN ?`, itemIDs).Error
+}
+
+// RebuildSearchIndex 根据物品当前内容重建整个全文检索索引（包括回收站中的物品），返回写入的记录数
+func RebuildSearchIndex(ctx context.Context, db *gorm.DB) (int64, error) {
+	var rows int64
+
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`DELETE FROM search_index`).Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(insertSearchIndexSQL)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		rows = result.RowsAffected
+		return nil
+	})
+
+	return rows, err
+}
+
+// highlightSnippet 转义高亮片段中的物品文本，并将匹配词标记为 <mark>
+func highlightSnippet(snippet string) string {
+	return snippetReplacer.Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+)
+
+// 缩略图参数
+const (
+	ThumbnailSize      = 320 // 缩略图最长边（像素）
+	thumbnailQuality   = 80
+	thumbnailQueueSize = 100
+	thumbnailPrefix    = "thumbs/"
+	maxImagePixels     = 40_000_000 // 解码前校验像素数，避免小文件解码出超大图片耗尽内存
+)
+
+// 缩略图相关错误
+var (
+	ErrNotImage          = errors.New("只有图片可以生成缩略图")
+	ErrThumbnailNotReady = notFoundError("缩略图尚未生成")
+)
+
+// enqueueThumbnail 将媒体文件加入缩略图生成队列，队列已满时放弃，可稍后通过重新生成接口补齐
+func (s *mediaService) enqueueThumbnail(mediaID string) {
+	select {
+	case s.thumbnails <- mediaID:
+	default:
+		log.Printf("Thumbnail queue full, skipping media %s", mediaID)
+	}
+}
+
+// StartThumbnailWorker 启动后台缩略图生成任务，ctx取消时退出
+func (s *mediaService) StartThumbnailWorker(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case mediaID := <-s.thumbnails:
+				var media models.MediaFile
+				if err := s.db.WithContext(ctx).First(&media, "id = ?", mediaID).Error; err != nil {
+					log.Printf("Thumbnail: failed to load media %s: %v", mediaID, err)
+					continue
+				}
+				if err := s.generateThumbnail(ctx, &media); err != nil {
+					log.Printf("Thumbnail: failed to generate for media %s: %v", mediaID, err)
+				}
+			}
+		}
+	}()
+}
+
+// RegenerateThumbnail 重新生成媒体文件的缩略图
+func (s *mediaService) RegenerateThumbnail(ctx context.Context, itemID, mediaID string) (*models.MediaFile, error) {
+	media, err := s.findMedia(ctx, itemID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.FileType != MediaTypeImage {
+		return nil, ErrNotImage
+	}
+
+	if err := s.generateThumbnail(ctx, media); err != nil {
+		return nil, err
+	}
+
+	return media, nil
+}
+
+// OpenThumbnail 打开媒体文件的缩略图供读取，返回内容时调用方负责关闭
+func (s *mediaService) OpenThumbnail(ctx context.Context, itemID, mediaID string) (*MediaContent, error) {
+	media, err := s.findMedia(ctx, itemID, mediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media.ThumbnailKey == "" {
+		return nil, ErrThumbnailNotReady
+	}
+
+	return s.open(ctx, media, media.ThumbnailKey, ErrThumbnailNotReady)
+}
+
+// generateThumbnail 读取原图生成缩略图并更新媒体记录
+func (s *mediaService) generateThumbnail(ctx context.Context, media *models.MediaFile) error {
+	file, err := s.storage.Open(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	thumbnail, err := GenerateThumbnail(file, ThumbnailSize)
+	if err != nil {
+		return err
+	}
+
+	previous := media.ThumbnailKey
+	err = s.store(ctx, bytes.NewReader(thumbnail), thumbnailPrefix, "image/jpeg", func(tx *gorm.DB, key string, _ int64) error {
+		media.ThumbnailKey = key
+		media.ThumbnailURL = mediaURL(media.ItemID, media.ID) + "/thumbnail"
+		return tx.Model(&models.MediaFile{}).
+			Where("id = ?", media.ID).
+			Updates(map[string]any{
+				"thumbnail_key": media.ThumbnailKey,
+				"thumbnail_url": media.ThumbnailURL,
+			}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if previous != "" && previous != media.ThumbnailKey {
+		s.removeIfUnused(ctx, previous)
+	}
+	return nil
+}
+
+// GenerateThumbnail 将图片等比缩放到最长边不超过size并编码为JPEG
+// 重新编码的JPEG不包含任何EXIF信息，透明区域填充为白色
+func GenerateThumbnail(r io.Reader, size int) ([]byte, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, errors.New("图片尺寸过大，无法生成缩略图")
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrNotImage
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, ErrNotImage
+	}
+	if width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, resizeImage(src, width, height), &jpeg.Options{Quality: thumbnailQuality}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resizeImage 按区域平均缩放图片，缩小时每个目标像素取对应源区域的均值
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcW/width)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					// 预乘alpha的颜色值叠加白色背景
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					white := uint64(0xffff - ca)
+					r += uint64(cr) + white
+					g += uint64(cg) + white
+					b += uint64(cb) + white
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
+
+// StripJPEGGPS 清除JPEG中EXIF的GPS定位信息，文件长度和其他EXIF字段保持不变
+// 非JPEG或EXIF结构无法识别时原样返回
+func StripJPEGGPS(data []byte) []byte {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return data
+	}
+
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		marker := data[i+1]
+		// 图像数据开始后不再有元数据段
+		if marker == 0xDA || marker == 0xD9 {
+			break
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			break
+		}
+
+		segment := data[i+4 : end]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			stripTIFFGPS(segment[6:])
+		}
+		i = end
+	}
+
+	return data
+}
+
+// stripTIFFGPS 在EXIF的TIFF结构中清空GPS子目录及其引用的数据
+func stripTIFFGPS(tiff []byte) {
+	if len(tiff) < 8 {
+		return
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < count; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return
+		}
+		if order.Uint16(tiff[entry:]) == 0x8825 {
+			clearIFD(tiff, int(order.Uint32(tiff[entry+8:])), order)
+		}
+	}
+}
+
+// clearIFD 清零目录中每个字段的值及其外部数据，并将字段数置为0
+func clearIFD(tiff []byte, offset int, order binary.ByteOrder) {
+	if offset+2 > len(tiff) {
+		return
+	}
+
+	// TIFF字段类型对应的单个值字节数
+	typeSizes := map[uint16]int{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+	count := int(order.Uint16(tiff[offset:]))
+	for k := 0; k < count; k++ {
+		entry := offset + 2 + k*12
+		if entry+12 > len(tiff) {
+			break
+		}
+
+		size := typeSizes[order.Uint16(tiff[entry+2:])] * int(order.Uint32(tiff[entry+4:]))
+		if size > 4 {
+			start := int(order.Uint32(tiff[entry+8:]))
+			if start >= 0 && size <= len(tiff)-start {
+				clear(tiff[start : start+size])
+			}
+		}
+		clear(tiff[entry : entry+12])
+	}
+
+	order.PutUint16(tiff[offset:], 0)
+}
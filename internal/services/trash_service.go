@@ -0,0 +1,488 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"nookverse/internal/models"
+	"nookverse/internal/storage"
+)
+
+// 回收站参数
+const (
+	defaultTrashRetention = 30 * 24 * time.Hour
+	trashPurgeInterval    = time.Hour
+)
+
+// ErrParentInTrash 恢复对象时其所在的房屋、房间或容器仍在回收站中
+var ErrParentInTrash = errors.New("上级位置在回收站中")
+
+// 恢复时上级位置不可用的错误，可通过 errors.Is(err, ErrParentInTrash) 识别
+var (
+	ErrItemRoomInTrash      = parentInTrashError("物品所在的房间在回收站中，请先恢复房间")
+	ErrItemContainerInTrash = parentInTrashError("物品所在的容器在回收站中，请先恢复容器")
+	ErrRoomHouseInTrash     = parentInTrashError("房间所在的房屋在回收站中，请先恢复房屋")
+)
+
+// parentInTrashError 可通过 errors.Is(err, ErrParentInTrash) 识别的错误
+type parentInTrashError string
+
+func (e parentInTrashError) Error() string { return string(e) }
+
+func (e parentInTrashError) Is(target error) bool { return target == ErrParentInTrash }
+
+// TrashEntry 回收站中的一个对象
+type TrashEntry struct {
+	EntityType string    `json:"entity_type"` // item, room, house
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	HouseID    string    `json:"house_id"`
+	RoomID     *string   `json:"room_id,omitempty"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAt    time.Time `json:"purge_at" gorm:"-"` // 到期后彻底删除
+}
+
+// TrashPurgeResult 一次清理彻底删除的对象数
+type TrashPurgeResult struct {
+	Items  int64
+	Rooms  int64
+	Houses int64
+}
+
+// trashEntriesSQL 家庭回收站中的房屋、房间和物品；私有物品只对获得授权的用户列出
+const trashEntriesSQL = `SELECT 'house' AS entity_type, h.id, h.name, h.id AS house_id, CAST(NULL AS uuid) AS room_id, h.deleted_at
+	FROM houses h JOIN family_houses fh ON fh.house_id = h.id
+	WHERE fh.family_id = @fid AND h.deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'room', r.id, r.name, r.house_id, r.id, r.deleted_at
+	FROM rooms r JOIN family_houses fh ON fh.house_id = r.house_id
+	WHERE fh.family_id = @fid AND r.deleted_at IS NOT NULL
+	UNION ALL
+	SELECT 'item', i.id, i.name, r.house_id, i.room_id, i.deleted_at
+	FROM items i JOIN rooms r ON r.id = i.room_id JOIN family_houses fh ON fh.house_id = r.house_id
+	WHERE fh.family_id = @fid AND i.deleted_at IS NOT NULL AND ` + privateItemVisibleSQL
+
+// TrashService 回收站服务接口：删除的物品、房间和房屋先移入回收站，可在保留期内恢复，到期后彻底删除
+type TrashService interface {
+	// ListTrash 家庭回收站中的对象，最近删除的在前；familyID为空时使用用户唯一的家庭
+	ListTrash(ctx context.Context, familyID string, page, pageSize int) ([]TrashEntry, int64, error)
+	RestoreItem(ctx context.Context, itemID string) (*models.Item, error)
+	RestoreRoom(ctx context.Context, roomID string) (*models.Room, error)
+	RestoreHouse(ctx context.Context, houseID string) (*models.House, error)
+	// PurgeExpired 彻底删除超过保留期的对象
+	PurgeExpired(ctx context.Context) (TrashPurgeResult, error)
+	// StartPurger 启动后台定期清理，ctx取消后退出
+	StartPurger(ctx context.Context)
+}
+
+type trashService struct {
+	db        *gorm.DB
+	storage   storage.Storage
+	retention time.Duration
+}
+
+// NewTrashService 创建回收站服务实例，retention 为回收站保留时间，不大于0时为30天；
+// backend 为媒体文件的存储后端，彻底删除物品后清理不再被引用的文件
+func NewTrashService(db *gorm.DB, backend storage.Storage, retention time.Duration) TrashService {
+	if retention <= 0 {
+		retention = defaultTrashRetention
+	}
+	return &trashService{db: db, storage: backend, retention: retention}
+}
+
+// ListTrash 获取家庭回收站
+func (s *trashService) ListTrash(ctx context.Context, familyID string, page, pageSize int) ([]TrashEntry, int64, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	familyID, err = resolveFamily(ctx, s.db, userID, familyID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	fid, uid := sql.Named("fid", familyID), sql.Named("uid", userID)
+
+	var total int64
+	if err := s.db.WithContext(ctx).Raw("SELECT COUNT(*) FROM ("+trashEntriesSQL+") t", fid, uid).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []TrashEntry
+	err = s.db.WithContext(ctx).
+		Raw("SELECT * FROM ("+trashEntriesSQL+") t ORDER BY deleted_at DESC, id LIMIT @limit OFFSET @offset",
+			fid, uid, sql.Named("limit", pageSize), sql.Named("offset", (page-1)*pageSize)).
+		Scan(&entries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range entries {
+		entries[i].PurgeAt = entries[i].DeletedAt.Add(s.retention)
+	}
+	return entries, total, nil
+}
+
+// RestoreItem 从回收站恢复物品，需要删除物品的权限；所在房间和容器必须仍然存在且不在回收站中
+func (s *trashService) RestoreItem(ctx context.Context, itemID string) (*models.Item, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var item models.Item
+	if err := s.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&item, "id = ?", itemID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	// 房间已被彻底删除的物品无法确定所属家庭，视为不存在
+	if item.RoomID == nil {
+		return nil, ErrItemNotFound
+	}
+	var room models.Room
+	if err := s.db.WithContext(ctx).Unscoped().First(&room, "id = ?", *item.RoomID).Error; err != nil {
+		return nil, ErrItemNotFound
+	}
+
+	role, err := familyRole(s.db.WithContext(ctx), userID, room.HouseID, true)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrItemNotFound
+	}
+
+	var grants []models.ItemPermission
+	if err := s.db.WithContext(ctx).Where("item_id = ?", itemID).Find(&grants).Error; err != nil {
+		return nil, err
+	}
+	effective := ResolveEffectivePermission(itemID, userID, role, grants)
+	if effective.Level == "" {
+		return nil, ErrItemNotFound
+	}
+	if !effective.Actions[ActionDelete] {
+		return nil, ErrForbidden
+	}
+
+	if room.DeletedAt.Valid {
+		return nil, ErrItemRoomInTrash
+	}
+	if item.ContainerID != nil {
+		var container models.Item
+		if err := s.db.WithContext(ctx).First(&container, "id = ?", *item.ContainerID).Error; err != nil {
+			return nil, ErrItemContainerInTrash
+		}
+		// 删除期间容器可能已被移动，物品随容器位于同一房间
+		item.RoomID = container.RoomID
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 同一次删除的容器内物品一并恢复，随容器位于同一房间
+		var items []models.Item
+		err := tx.Unscoped().
+			Where(`id IN (WITH RECURSIVE batch AS (
+				SELECT id FROM items WHERE id = ?
+				UNION ALL
+				SELECT i.id FROM items i JOIN batch b ON i.container_id = b.id WHERE i.deleted_at = ?
+			) SELECT id FROM batch)`, itemID, item.DeletedAt.Time).
+			Find(&items).Error
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].RoomID = item.RoomID
+		}
+		return restoreItems(tx, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item.DeletedAt = gorm.DeletedAt{}
+	return &item, nil
+}
+
+// RestoreRoom 从回收站恢复房间，需要删除房间的权限；所在房屋必须不在回收站中
+func (s *trashService) RestoreRoom(ctx context.Context, roomID string) (*models.Room, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var room models.Room
+	if err := s.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&room, "id = ?", roomID).Error; err != nil {
+		return nil, ErrRoomNotFound
+	}
+
+	role, err := familyRole(s.db.WithContext(ctx), userID, room.HouseID, true)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrRoomNotFound
+	}
+	allowed := roleActions[role][ActionDelete]
+	if role == RoleMember {
+		allowed = roomMemberActions[ActionDelete]
+	}
+	if !allowed {
+		return nil, ErrForbidden
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.House{}).Where("id = ?", room.HouseID).Count(&count)
+	if count == 0 {
+		return nil, ErrRoomHouseInTrash
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return restoreRooms(tx, []models.Room{room})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	room.DeletedAt = gorm.DeletedAt{}
+	return &room, nil
+}
+
+// RestoreHouse 从回收站恢复房屋，需要删除房屋的权限
+func (s *trashService) RestoreHouse(ctx context.Context, houseID string) (*models.House, error) {
+	userID, err := currentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var house models.House
+	if err := s.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").First(&house, "id = ?", houseID).Error; err != nil {
+		return nil, ErrHouseNotFound
+	}
+
+	role, err := familyRole(s.db.WithContext(ctx), userID, houseID, true)
+	if err != nil {
+		return nil, err
+	}
+	if role == "" {
+		return nil, ErrHouseNotFound
+	}
+	if !roleActions[role][ActionDelete] {
+		return nil, ErrForbidden
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.House{}).Where("id = ?", houseID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		err := recordOperation(tx, operationEntry{
+			EntityType:  EntityHouse,
+			EntityID:    houseID,
+			HouseID:     &house.ID,
+			Operation:   OperationRestore,
+			Description: "从回收站恢复房屋: " + house.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		// 同一次删除的房间一并恢复
+		var rooms []models.Room
+		if err := tx.Unscoped().Where("house_id = ? AND deleted_at = ?", houseID, house.DeletedAt.Time).Find(&rooms).Error; err != nil {
+			return err
+		}
+		return restoreRooms(tx, rooms)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	house.DeletedAt = gorm.DeletedAt{}
+	return &house, nil
+}
+
+// restoreRooms 在事务中恢复房间，同一次删除的房间内物品一并恢复
+func restoreRooms(tx *gorm.DB, rooms []models.Room) error {
+	for _, room := range rooms {
+		if err := tx.Unscoped().Model(&models.Room{}).Where("id = ?", room.ID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		err := recordOperation(tx, operationEntry{
+			EntityType:  EntityRoom,
+			EntityID:    room.ID,
+			HouseID:     &room.HouseID,
+			Operation:   OperationRestore,
+			Description: "从回收站恢复房间: " + room.Name,
+		})
+		if err != nil {
+			return err
+		}
+
+		var items []models.Item
+		if err := tx.Unscoped().Where("room_id = ? AND deleted_at = ?", room.ID, room.DeletedAt.Time).Find(&items).Error; err != nil {
+			return err
+		}
+		if err := restoreItems(tx, items); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreItems 在事务中恢复物品：容器先于其中的物品重新加入闭包表，重建自动提醒，并记录版本和日志
+func restoreItems(tx *gorm.DB, items []models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	if err := recordItemBaseline(tx, ids...); err != nil {
+		return err
+	}
+
+	for _, item := range containersFirst(items) {
+		err := tx.Unscoped().Model(&models.Item{}).
+			Where("id = ?", item.ID).
+			Updates(map[string]any{"deleted_at": nil, "room_id": item.RoomID}).Error
+		if err != nil {
+			return err
+		}
+		if err := insertItemHierarchy(tx, item.ID, item.ContainerID); err != nil {
+			return err
+		}
+		if err := recordItemVersion(tx, item.ID, OperationRestore); err != nil {
+			return err
+		}
+		err = recordOperation(tx, operationEntry{
+			EntityType:  EntityItem,
+			EntityID:    item.ID,
+			ItemID:      &item.ID,
+			HouseID:     roomHouseID(tx, item.RoomID),
+			Operation:   OperationRestore,
+			Description: "从回收站恢复物品: " + item.Name,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return syncPolicyReminders(tx, contentIDs(items)...)
+}
+
+// containersFirst 排列物品，使容器排在其中的物品之前
+func containersFirst(items []models.Item) []models.Item {
+	pending := make(map[string]bool, len(items))
+	for _, item := range items {
+		pending[item.ID] = true
+	}
+
+	ordered := make([]models.Item, 0, len(items))
+	for progressed := true; progressed; {
+		progressed = false
+		for _, item := range items {
+			if pending[item.ID] && (item.ContainerID == nil || !pending[*item.ContainerID]) {
+				ordered = append(ordered, item)
+				delete(pending, item.ID)
+				progressed = true
+			}
+		}
+	}
+
+	// 脏数据中的循环引用，剩余物品按原顺序排列
+	for _, item := range items {
+		if pending[item.ID] {
+			ordered = append(ordered, item)
+		}
+	}
+	return ordered
+}
+
+// PurgeExpired 按物品、房间、房屋的顺序彻底删除超过保留期的对象，媒体记录、提醒等随数据库外键级联删除，
+// 操作日志保留并置空物品ID；提交后清理不再被引用的媒体文件；仍有物品（含回收站中未到期的物品）的房间、仍有房间的房屋留到下次清理
+func (s *trashService) PurgeExpired(ctx context.Context) (TrashPurgeResult, error) {
+	var result TrashPurgeResult
+	keys := make(map[string]bool)
+	cutoff := time.Now().Add(-s.retention)
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var media []models.MediaFile
+		err := tx.Select("storage_key", "thumbnail_key").
+			Where("item_id IN (SELECT id FROM items WHERE deleted_at < ?)", cutoff).
+			Find(&media).Error
+		if err != nil {
+			return err
+		}
+		for _, file := range media {
+			keys[file.StorageKey] = true
+			keys[file.ThumbnailKey] = true
+		}
+
+		items := tx.Unscoped().Where("deleted_at < ?", cutoff).Delete(&models.Item{})
+		if items.Error != nil {
+			return items.Error
+		}
+
+		rooms := tx.Unscoped().
+			Where("deleted_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM items WHERE items.room_id = rooms.id)").
+			Delete(&models.Room{})
+		if rooms.Error != nil {
+			return rooms.Error
+		}
+
+		houses := tx.Unscoped().
+			Where("deleted_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM rooms WHERE rooms.house_id = houses.id)").
+			Delete(&models.House{})
+		if houses.Error != nil {
+			return houses.Error
+		}
+
+		result = TrashPurgeResult{Items: items.RowsAffected, Rooms: rooms.RowsAffected, Houses: houses.RowsAffected}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for key := range keys {
+		removeUnusedObject(ctx, s.db, s.storage, key)
+	}
+	return result, nil
+}
+
+// StartPurger 启动时和之后每小时清理一次回收站
+func (s *trashService) StartPurger(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			result, err := s.PurgeExpired(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Trash purger: %v", err)
+			} else if result.Items+result.Rooms+result.Houses > 0 {
+				log.Printf("Trash purger: purged %d items, %d rooms, %d houses", result.Items, result.Rooms, result.Houses)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
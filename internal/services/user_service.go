@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"nookverse/internal/auth"
+	"nookverse/internal/models"
+)
+
+// 用户认证相关错误
+var (
+	ErrUserExists          = errors.New("用户名或邮箱已被注册")
+	ErrInvalidCredentials  = errors.New("用户名或密码错误")
+	ErrUserDisabled        = errors.New("用户已被禁用")
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效或已过期")
+)
+
+// UserService 用户服务接口
+type UserService interface {
+	// 注册与登录
+	Register(ctx context.Context, user *models.User, password string) error
+	Login(ctx context.Context, account, password string, meta ClientMeta) (*TokenPair, *models.User, error)
+	RefreshToken(ctx context.Context, refreshToken string, meta ClientMeta) (*TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
+
+	// 令牌校验
+	Authenticate(ctx context.Context, accessToken string) (*auth.Claims, error)
+
+	// 用户信息
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+}
+
+// ClientMeta 发起请求的客户端信息
+type ClientMeta struct {
+	IPAddress string
+	UserAgent string
+}
+
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	TokenType        string    `json:"token_type"`
+}
+
+type userService struct {
+	db            *gorm.DB
+	tokens        *auth.TokenManager
+	refreshExpire time.Duration
+}
+
+// NewUserService 创建用户服务实例
+func NewUserService(db *gorm.DB, tokens *auth.TokenManager, refreshExpire time.Duration) UserService {
+	return &userService{db: db, tokens: tokens, refreshExpire: refreshExpire}
+}
+
+// Register 注册用户
+func (s *userService) Register(ctx context.Context, user *models.User, password string) error {
+	user.Username = strings.TrimSpace(user.Username)
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+
+	if user.Username == "" {
+		return errors.New("用户名不能为空")
+	}
+	if user.Email == "" {
+		return errors.New("邮箱不能为空")
+	}
+	if len(password) < 6 {
+		return errors.New("密码长度不能少于6位")
+	}
+
+	var count int64
+	s.db.WithContext(ctx).Model(&models.User{}).
+		Where("username = ? OR email = ?", user.Username, user.Email).
+		Count(&count)
+	if count > 0 {
+		return ErrUserExists
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = string(hash)
+	user.Status = 1
+
+	// 注册时同时创建个人家庭，用户作为家庭所有者
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+
+		family := &models.Family{
+			Name:    user.Username + "的家庭",
+			OwnerID: user.ID,
+		}
+		if err := tx.Omit(clause.Associations).Create(family).Error; err != nil {
+			return err
+		}
+
+		return tx.Omit(clause.Associations).Create(&models.FamilyMember{
+			FamilyID: family.ID,
+			UserID:   user.ID,
+			Role:     RoleOwner,
+			JoinedAt: time.Now(),
+			Status:   MemberStatusActive,
+		}).Error
+	})
+}
+
+// Login 用户名或邮箱登录，签发令牌
+func (s *userService) Login(ctx context.Context, account, password string, meta ClientMeta) (*TokenPair, *models.User, error) {
+	account = strings.TrimSpace(account)
+
+	var user models.User
+	err := s.db.WithContext(ctx).
+		Where("username = ? OR email = ?", account, strings.ToLower(account)).
+		First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidCredentials
+		}
+		return nil, nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if user.Status != 1 {
+		return nil, nil, ErrUserDisabled
+	}
+
+	var pair *TokenPair
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		user.LastLogin = &now
+		if err := tx.Model(&user).Update("last_login", now).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, _, err = s.issueTokens(tx, &user, meta)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pair, &user, nil
+}
+
+// RefreshToken 轮换刷新令牌并签发新的访问令牌
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string, meta ClientMeta) (*TokenPair, error) {
+	if refreshToken == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	var pair *TokenPair
+	reused := false
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var stored models.RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&stored, "token_hash = ?", hashToken(refreshToken)).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidRefreshToken
+			}
+			return err
+		}
+
+		// 已轮换过的令牌再次出现，说明令牌可能泄露，需吊销该用户全部刷新令牌
+		if stored.RevokedAt != nil {
+			reused = true
+			return ErrInvalidRefreshToken
+		}
+
+		if time.Now().After(stored.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+
+		var user models.User
+		if err := tx.First(&user, "id = ?", stored.UserID).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if user.Status != 1 {
+			return ErrUserDisabled
+		}
+
+		var newToken *models.RefreshToken
+		pair, newToken, err = s.issueTokens(tx, &user, meta)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&stored).Updates(map[string]any{
+			"revoked_at":  now,
+			"replaced_by": newToken.ID,
+		}).Error
+	})
+
+	if reused {
+		s.revokeAllByHash(ctx, hashToken(refreshToken))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Logout 吊销刷新令牌
+func (s *userService) Logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return ErrInvalidRefreshToken
+	}
+
+	return s.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hashToken(refreshToken)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Authenticate 校验访问令牌，并确认用户仍然有效
+func (s *userService) Authenticate(ctx context.Context, accessToken string) (*auth.Claims, error) {
+	claims, err := s.tokens.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "status").First(&user, "id = ?", claims.UserID).Error; err != nil {
+		return nil, auth.ErrInvalidToken
+	}
+	if user.Status != 1 {
+		return nil, ErrUserDisabled
+	}
+
+	return claims, nil
+}
+
+// GetUserByID 根据ID获取用户
+func (s *userService) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	var user models.User
+	err := s.db.WithContext(ctx).First(&user, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("用户不存在")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// issueTokens 在事务内签发一对新令牌
+func (s *userService) issueTokens(tx *gorm.DB, user *models.User, meta ClientMeta) (*TokenPair, *models.RefreshToken, error) {
+	accessToken, accessExpiresAt, err := s.tokens.GenerateAccessToken(user.ID, user.Username)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stored := &models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(s.refreshExpire),
+	}
+	if meta.UserAgent != "" {
+		stored.UserAgent = &meta.UserAgent
+	}
+	if err := tx.Create(stored).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      accessToken,
+		AccessExpiresAt:  accessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: stored.ExpiresAt,
+		TokenType:        "Bearer",
+	}, stored, nil
+}
+
+// revokeAllByHash 吊销令牌所属用户的全部刷新令牌
+func (s *userService) revokeAllByHash(ctx context.Context, tokenHash string) {
+	s.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = (SELECT user_id FROM refresh_tokens WHERE token_hash = ?) AND revoked_at IS NULL", tokenHash).
+		Update("revoked_at", time.Now())
+}
+
+// generateToken 生成随机令牌，用于刷新令牌和日历订阅令牌
+func generateToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashToken 计算令牌的SHA-256哈希，数据库中不保存明文
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
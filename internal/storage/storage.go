@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"nookverse/internal/config"
+)
+
+// 存储后端名称
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// 存储相关错误
+var (
+	ErrObjectNotFound     = errors.New("存储对象不存在")
+	ErrPresignUnsupported = errors.New("存储后端不支持预签名地址")
+)
+
+// Storage 媒体文件存储后端接口，key 为以 / 分隔的相对路径
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, content io.Reader, size int64, contentType string) error
+	// Open 打开对象供读取，支持Seek以便按Range返回部分内容
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// PresignURL 生成限时下载地址，不支持时返回 ErrPresignUnsupported
+	PresignURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// New 根据上传配置创建存储后端
+func New(cfg config.UploadConfig) (Storage, error) {
+	return NewBackend(cfg.Storage, cfg)
+}
+
+// NewBackend 创建指定名称的存储后端，名称为空时使用本地存储
+func NewBackend(name string, cfg config.UploadConfig) (Storage, error) {
+	switch name {
+	case "", BackendLocal:
+		return NewLocal(cfg.Path), nil
+	case BackendS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", name)
+	}
+}
@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// CalendarFeedResponse 日历订阅响应，Token 和 Path 只在重置令牌时返回
+type CalendarFeedResponse struct {
+	Token          string     `json:"token,omitempty"`
+	Path           string     `json:"path,omitempty"` // 订阅地址路径，拼接服务地址后添加到日历应用
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// ToCalendarFeedResponse 转换日历订阅为响应格式
+func ToCalendarFeedResponse(feed *models.CalendarFeed) CalendarFeedResponse {
+	return CalendarFeedResponse{
+		LastAccessedAt: feed.LastAccessedAt,
+		CreatedAt:      feed.CreatedAt,
+	}
+}
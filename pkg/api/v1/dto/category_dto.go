@@ -0,0 +1,59 @@
+package dto
+
+import (
+	"nookverse/internal/models"
+)
+
+// CreateCategoryRequest 创建分类请求
+type CreateCategoryRequest struct {
+	Name      string  `json:"name" binding:"required,max=100"`
+	ParentID  *string `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+	Icon      *string `json:"icon,omitempty"`
+	Color     *string `json:"color,omitempty"`
+	SortOrder *int    `json:"sort_order,omitempty"`
+}
+
+// UpdateCategoryRequest 更新分类请求
+type UpdateCategoryRequest struct {
+	Name        *string `json:"name,omitempty" binding:"omitempty,max=100"`
+	ParentID    *string `json:"parent_id,omitempty" binding:"omitempty,uuid"`
+	ClearParent bool    `json:"clear_parent,omitempty"` // 为true时移动到顶层
+	Icon        *string `json:"icon,omitempty"`
+	Color       *string `json:"color,omitempty"`
+	SortOrder   *int    `json:"sort_order,omitempty"`
+}
+
+// ReorderCategoriesRequest 分类排序请求
+type ReorderCategoriesRequest struct {
+	Orders []CategoryOrderRequest `json:"orders" binding:"required,min=1,dive"`
+}
+
+// CategoryOrderRequest 单个分类的排序值
+type CategoryOrderRequest struct {
+	ID        string `json:"id" binding:"required,uuid"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// MergeCategoryRequest 合并分类请求
+type MergeCategoryRequest struct {
+	TargetID string `json:"target_id" binding:"required,uuid"`
+}
+
+// ToCategoryResponse 转换分类模型为响应格式（包含已加载的子分类）
+func ToCategoryResponse(category *models.Category) CategoryResponse {
+	resp := CategoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		ParentID:  category.ParentID,
+		Icon:      category.Icon,
+		Color:     category.Color,
+		SortOrder: category.SortOrder,
+		IsSystem:  category.IsSystem,
+	}
+
+	for i := range category.Children {
+		resp.Children = append(resp.Children, ToCategoryResponse(&category.Children[i]))
+	}
+
+	return resp
+}
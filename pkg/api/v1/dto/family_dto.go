@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// CreateFamilyRequest 创建家庭请求
+type CreateFamilyRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description,omitempty"`
+}
+
+// RotateInviteCodeRequest 生成邀请码请求
+type RotateInviteCodeRequest struct {
+	Role           string `json:"role,omitempty" binding:"omitempty,oneof=admin member viewer"` // 加入后的默认角色，默认member
+	ExpiresInHours int    `json:"expires_in_hours,omitempty" binding:"omitempty,min=1,max=720"` // 有效期（小时），默认72
+}
+
+// JoinFamilyRequest 通过邀请码加入家庭请求
+type JoinFamilyRequest struct {
+	InviteCode string `json:"invite_code" binding:"required"`
+}
+
+// TransferOwnershipRequest 转让家庭所有权请求
+type TransferOwnershipRequest struct {
+	UserID string `json:"user_id" binding:"required,uuid"`
+}
+
+// FamilyResponse 家庭响应
+type FamilyResponse struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Description     *string    `json:"description,omitempty"`
+	OwnerID         string     `json:"owner_id"`
+	InviteCode      *string    `json:"invite_code,omitempty"`
+	InviteRole      string     `json:"invite_role,omitempty"`
+	InviteExpiresAt *time.Time `json:"invite_expires_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// FamilyMemberResponse 家庭成员响应
+type FamilyMemberResponse struct {
+	ID       string        `json:"id"`
+	FamilyID string        `json:"family_id"`
+	UserID   string        `json:"user_id"`
+	Role     string        `json:"role"`
+	Status   int           `json:"status"`
+	JoinedAt time.Time     `json:"joined_at"`
+	User     *UserResponse `json:"user,omitempty"`
+}
+
+// ToFamilyResponse 转换家庭模型为响应格式，邀请码只返回给能管理家庭的成员（withInvite），已过期时不返回
+func ToFamilyResponse(family *models.Family, withInvite bool) FamilyResponse {
+	response := FamilyResponse{
+		ID:          family.ID,
+		Name:        family.Name,
+		Description: family.Description,
+		OwnerID:     family.OwnerID,
+		CreatedAt:   family.CreatedAt,
+		UpdatedAt:   family.UpdatedAt,
+	}
+
+	if withInvite && family.InviteCode != nil && family.InviteExpiresAt != nil && family.InviteExpiresAt.After(time.Now()) {
+		response.InviteCode = family.InviteCode
+		response.InviteRole = family.InviteRole
+		response.InviteExpiresAt = family.InviteExpiresAt
+	}
+
+	return response
+}
+
+// ToFamilyMemberResponse 转换家庭成员模型为响应格式
+func ToFamilyMemberResponse(member *models.FamilyMember) FamilyMemberResponse {
+	response := FamilyMemberResponse{
+		ID:       member.ID,
+		FamilyID: member.FamilyID,
+		UserID:   member.UserID,
+		Role:     member.Role,
+		Status:   member.Status,
+		JoinedAt: member.JoinedAt,
+	}
+
+	if member.User.ID != "" {
+		user := ToUserResponse(&member.User)
+		response.User = &user
+	}
+
+	return response
+}
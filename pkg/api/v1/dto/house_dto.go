@@ -8,6 +8,7 @@ import (
 
 // CreateHouseRequest 创建房屋请求
 type CreateHouseRequest struct {
+	FamilyID    *string        `json:"family_id,omitempty"` // 所属家庭，用户只属于一个家庭时可省略
 	Name        string         `json:"name" binding:"required"`
 	Address     *string        `json:"address,omitempty"`
 	Description *string        `json:"description,omitempty"`
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"nookverse/internal/models"
+	"nookverse/internal/services"
 )
 
 // CreateItemRequest 创建物品请求
@@ -70,10 +71,19 @@ type ItemResponse struct {
 	Labels         []string          `json:"labels,omitempty"`
 	MediaFiles     []MediaFileResponse `json:"media_files,omitempty"`
 	Reminders      []ReminderResponse  `json:"reminders,omitempty"`
+	Location       []LocationNodeResponse `json:"location,omitempty"` // 房屋 → 房间 → 容器链 → 物品
+	Snippet        string            `json:"snippet,omitempty"` // 搜索结果的匹配片段，已做HTML转义，匹配词以 <mark></mark> 标出
 	CreatedAt      time.Time         `json:"created_at"`
 	UpdatedAt      time.Time         `json:"updated_at"`
 }
 
+// LocationNodeResponse 位置路径节点响应
+type LocationNodeResponse struct {
+	Type string `json:"type"` // house, room, item
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
 // CategoryResponse 分类响应
 type CategoryResponse struct {
 	ID        string              `json:"id"`
@@ -82,6 +92,7 @@ type CategoryResponse struct {
 	Icon      string              `json:"icon"`
 	Color     string              `json:"color"`
 	SortOrder int                 `json:"sort_order"`
+	IsSystem  bool                `json:"is_system"`
 	Children  []CategoryResponse  `json:"children,omitempty"`
 }
 
@@ -111,18 +122,27 @@ type MediaFileResponse struct {
 // ReminderResponse 提醒响应
 type ReminderResponse struct {
 	ID             string    `json:"id"`
+	ItemID         string    `json:"item_id"`
+	ItemName       string    `json:"item_name,omitempty"`
 	ReminderType   string    `json:"reminder_type"`
 	TriggerTime    time.Time `json:"trigger_time"`
 	Message        string    `json:"message"`
 	Status         string    `json:"status"`
 	NotifyChannels []string  `json:"notify_channels"`
+	Attempts       int        `json:"attempts"`
+	LastError      *string    `json:"last_error,omitempty"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	PolicyID       *string    `json:"policy_id,omitempty"` // 由家庭提醒策略自动生成
+	Recurrence     *string    `json:"recurrence,omitempty"`  // 重复规则（RRULE）
+	OccurrenceAt   *time.Time `json:"occurrence_at,omitempty"` // 重复提醒当前这一次的计划时间
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
 
-// MoveItemRequest 移动物品请求
+// MoveItemRequest 移动物品请求，目标容器和目标房间必须且只能指定一个
 type MoveItemRequest struct {
-	ContainerID string `json:"container_id" binding:"required"`
+	ContainerID *string `json:"container_id,omitempty" binding:"omitempty,uuid"`
+	RoomID      *string `json:"room_id,omitempty" binding:"omitempty,uuid"`
 }
 
 // CreateReminderRequest 创建提醒请求
@@ -131,6 +151,7 @@ type CreateReminderRequest struct {
 	TriggerTime    time.Time `json:"trigger_time" binding:"required"`
 	Message        string    `json:"message" binding:"required"`
 	NotifyChannels []string  `json:"notify_channels"`
+	Recurrence     *string   `json:"recurrence,omitempty"` // 重复规则（RRULE），如 FREQ=MONTHLY;INTERVAL=3
 }
 
 // ToItemResponse 转换物品模型为响应格式
@@ -157,14 +178,8 @@ func ToItemResponse(item *models.Item) ItemResponse {
 
 	// 转换分类信息
 	if item.Category != nil {
-		resp.Category = &CategoryResponse{
-			ID:        item.Category.ID,
-			Name:      item.Category.Name,
-			ParentID:  item.Category.ParentID,
-			Icon:      item.Category.Icon,
-			Color:     item.Category.Color,
-			SortOrder: item.Category.SortOrder,
-		}
+		category := ToCategoryResponse(item.Category)
+		resp.Category = &category
 	}
 
 	if item.Room != nil {
@@ -186,49 +201,87 @@ func ToItemResponse(item *models.Item) ItemResponse {
 	}
 
 	// 转换媒体文件
-	for _, media := range item.MediaFiles {
-		resp.MediaFiles = append(resp.MediaFiles, MediaFileResponse{
-			ID:          media.ID,
-			FileURL:     media.FileURL,
-			ThumbnailURL: &media.ThumbnailURL,
-			FileType:    media.FileType,
-			FileSize:    media.FileSize,
-			MimeType:    media.MimeType,
-			AltText:     media.AltText,
-			SortOrder:   media.SortOrder,
-			CreatedAt:   media.CreatedAt,
-		})
+	for i := range item.MediaFiles {
+		resp.MediaFiles = append(resp.MediaFiles, ToMediaFileResponse(&item.MediaFiles[i]))
 	}
 
 	// 转换提醒
-	for _, reminder := range item.Reminders {
-		resp.Reminders = append(resp.Reminders, ReminderResponse{
-			ID:             reminder.ID,
-			ReminderType:   reminder.ReminderType,
-			TriggerTime:    reminder.TriggerTime,
-			Message:        reminder.Message,
-			Status:         reminder.Status,
-			NotifyChannels: reminder.NotifyChannels,
-			CreatedAt:      reminder.CreatedAt,
-			UpdatedAt:      reminder.UpdatedAt,
-		})
+	for i := range item.Reminders {
+		resp.Reminders = append(resp.Reminders, ToReminderResponse(&item.Reminders[i]))
 	}
 
 	return resp
 }
 
+// ToLocationResponse 转换物品位置路径为响应格式
+func ToLocationResponse(path []services.LocationNode) []LocationNodeResponse {
+	var resp []LocationNodeResponse
+	for _, node := range path {
+		resp = append(resp, LocationNodeResponse{
+			Type: node.Type,
+			ID:   node.ID,
+			Name: node.Name,
+		})
+	}
+	return resp
+}
+
 // ToReminderResponse 转换提醒模型为响应格式
 func ToReminderResponse(reminder *models.Reminder) ReminderResponse {
-	return ReminderResponse{
+	response := ReminderResponse{
 		ID:             reminder.ID,
+		ItemID:         reminder.ItemID,
 		ReminderType:   reminder.ReminderType,
 		TriggerTime:    reminder.TriggerTime,
 		Message:        reminder.Message,
 		Status:         reminder.Status,
 		NotifyChannels: reminder.NotifyChannels,
+		Attempts:       reminder.Attempts,
+		LastError:      reminder.LastError,
+		SentAt:         reminder.SentAt,
+		PolicyID:       reminder.PolicyID,
+		Recurrence:     reminder.Recurrence,
+		OccurrenceAt:   reminder.OccurrenceAt,
 		CreatedAt:      reminder.CreatedAt,
 		UpdatedAt:      reminder.UpdatedAt,
 	}
+
+	if reminder.Item != nil {
+		response.ItemName = reminder.Item.Name
+	}
+
+	return response
+}
+
+// ItemTreeResponse 容器子树节点响应，汇总值包含节点自身及全部后代
+type ItemTreeResponse struct {
+	ItemResponse
+	Depth              int                `json:"depth"`
+	Truncated          bool               `json:"truncated"`
+	DescendantCount    int                `json:"descendant_count"`
+	TotalQuantity      int                `json:"total_quantity"`
+	TotalValue         float64            `json:"total_value"`
+	EarliestExpireDate *time.Time         `json:"earliest_expire_date,omitempty"`
+	Children           []ItemTreeResponse `json:"children,omitempty"`
+}
+
+// ToItemTreeResponse 转换子树节点为响应格式
+func ToItemTreeResponse(node *services.ItemTreeNode) ItemTreeResponse {
+	resp := ItemTreeResponse{
+		ItemResponse:       ToItemResponse(&node.Item),
+		Depth:              node.Depth,
+		Truncated:          node.Truncated,
+		DescendantCount:    node.DescendantCount,
+		TotalQuantity:      node.TotalQuantity,
+		TotalValue:         node.TotalValue,
+		EarliestExpireDate: node.EarliestExpireDate,
+	}
+
+	for _, child := range node.Children {
+		resp.Children = append(resp.Children, ToItemTreeResponse(child))
+	}
+
+	return resp
 }
 
 // SearchResponse 搜索响应
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// ItemVersionResponse 物品版本响应，snapshot 为该版本物品各列的值（键为列名）
+type ItemVersionResponse struct {
+	ID        string         `json:"id"`
+	ItemID    string         `json:"item_id"`
+	Version   int            `json:"version"`
+	Operation string         `json:"operation"`
+	Snapshot  map[string]any `json:"snapshot"`
+	User      *UserResponse  `json:"user,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// ToItemVersionResponse 转换物品版本为响应格式
+func ToItemVersionResponse(version *models.ItemVersion) ItemVersionResponse {
+	response := ItemVersionResponse{
+		ID:        version.ID,
+		ItemID:    version.ItemID,
+		Version:   version.Version,
+		Operation: version.Operation,
+		Snapshot:  version.Snapshot,
+		CreatedAt: version.CreatedAt,
+	}
+
+	if version.User != nil {
+		user := ToUserResponse(version.User)
+		response.User = &user
+	}
+
+	return response
+}
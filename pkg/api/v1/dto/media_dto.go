@@ -0,0 +1,30 @@
+package dto
+
+import (
+	"nookverse/internal/models"
+)
+
+// ReorderMediaRequest 媒体文件排序请求，按数组顺序排列
+type ReorderMediaRequest struct {
+	MediaIDs []string `json:"media_ids" binding:"required,min=1,dive,uuid"`
+}
+
+// ToMediaFileResponse 转换媒体文件模型为响应格式
+func ToMediaFileResponse(media *models.MediaFile) MediaFileResponse {
+	response := MediaFileResponse{
+		ID:        media.ID,
+		FileURL:   media.FileURL,
+		FileType:  media.FileType,
+		FileSize:  media.FileSize,
+		MimeType:  media.MimeType,
+		AltText:   media.AltText,
+		SortOrder: media.SortOrder,
+		CreatedAt: media.CreatedAt,
+	}
+
+	if media.ThumbnailURL != "" {
+		response.ThumbnailURL = &media.ThumbnailURL
+	}
+
+	return response
+}
@@ -0,0 +1,33 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// NotificationResponse 站内通知响应
+type NotificationResponse struct {
+	ID         string     `json:"id"`
+	ReminderID *string    `json:"reminder_id,omitempty"`
+	ItemID     *string    `json:"item_id,omitempty"`
+	Title      string     `json:"title"`
+	Message    string     `json:"message"`
+	Read       bool       `json:"read"`
+	ReadAt     *time.Time `json:"read_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToNotificationResponse 转换站内通知模型为响应格式
+func ToNotificationResponse(notification *models.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:         notification.ID,
+		ReminderID: notification.ReminderID,
+		ItemID:     notification.ItemID,
+		Title:      notification.Title,
+		Message:    notification.Message,
+		Read:       notification.ReadAt != nil,
+		ReadAt:     notification.ReadAt,
+		CreatedAt:  notification.CreatedAt,
+	}
+}
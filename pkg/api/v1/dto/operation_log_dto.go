@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// OperationLogResponse 操作日志响应，old_value 和 new_value 为变化字段（键为列名）修改前后的值
+type OperationLogResponse struct {
+	ID            string         `json:"id"`
+	OperationType string         `json:"operation_type"`
+	EntityType    string         `json:"entity_type"`
+	EntityID      *string        `json:"entity_id,omitempty"`
+	ItemID        *string        `json:"item_id,omitempty"`
+	HouseID       *string        `json:"house_id,omitempty"`
+	Description   *string        `json:"description,omitempty"`
+	OldValue      map[string]any `json:"old_value,omitempty"`
+	NewValue      map[string]any `json:"new_value,omitempty"`
+	IPAddress     *string        `json:"ip_address,omitempty"`
+	UserAgent     *string        `json:"user_agent,omitempty"`
+	User          *UserResponse  `json:"user,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// ToOperationLogResponse 转换操作日志为响应格式
+func ToOperationLogResponse(log *models.OperationLog) OperationLogResponse {
+	response := OperationLogResponse{
+		ID:            log.ID,
+		OperationType: log.OperationType,
+		EntityType:    log.EntityType,
+		EntityID:      log.EntityID,
+		ItemID:        log.ItemID,
+		HouseID:       log.HouseID,
+		Description:   log.Description,
+		OldValue:      log.OldValue,
+		NewValue:      log.NewValue,
+		IPAddress:     log.IPAddress,
+		UserAgent:     log.UserAgent,
+		CreatedAt:     log.CreatedAt,
+	}
+
+	if log.User != nil {
+		user := ToUserResponse(log.User)
+		response.User = &user
+	}
+
+	return response
+}
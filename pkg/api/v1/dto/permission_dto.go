@@ -0,0 +1,43 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// GrantItemPermissionRequest 物品授权请求
+type GrantItemPermissionRequest struct {
+	UserID          string `json:"user_id" binding:"required,uuid"`
+	PermissionLevel string `json:"permission_level" binding:"required,oneof=owner edit view"`
+}
+
+// ItemPermissionResponse 物品授权响应
+type ItemPermissionResponse struct {
+	ID              string        `json:"id"`
+	ItemID          string        `json:"item_id"`
+	UserID          string        `json:"user_id"`
+	PermissionLevel string        `json:"permission_level"`
+	GrantedBy       *string       `json:"granted_by,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	User            *UserResponse `json:"user,omitempty"`
+}
+
+// ToItemPermissionResponse 转换物品授权模型为响应格式
+func ToItemPermissionResponse(permission *models.ItemPermission) ItemPermissionResponse {
+	response := ItemPermissionResponse{
+		ID:              permission.ID,
+		ItemID:          permission.ItemID,
+		UserID:          permission.UserID,
+		PermissionLevel: permission.PermissionLevel,
+		GrantedBy:       permission.GrantedBy,
+		CreatedAt:       permission.CreatedAt,
+	}
+
+	if permission.User != nil {
+		user := ToUserResponse(permission.User)
+		response.User = &user
+	}
+
+	return response
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// UpdateReminderRequest 修改提醒请求，未提供的字段保持不变
+type UpdateReminderRequest struct {
+	TriggerTime    *time.Time `json:"trigger_time,omitempty"`
+	Message        *string    `json:"message,omitempty" binding:"omitempty,max=1000"`
+	NotifyChannels []string   `json:"notify_channels,omitempty"`
+	Recurrence     *string    `json:"recurrence,omitempty"` // 空字符串表示取消重复
+}
+
+// CompleteReminderRequest 完成提醒请求
+type CompleteReminderRequest struct {
+	Note *string `json:"note,omitempty" binding:"omitempty,max=1000"`
+}
+
+// SnoozeReminderRequest 稍后提醒请求，until 和 minutes 必须且只能指定一个
+type SnoozeReminderRequest struct {
+	Until   *time.Time `json:"until,omitempty"`
+	Minutes int        `json:"minutes,omitempty" binding:"omitempty,min=1,max=525600"` // 从当前时间起推迟的分钟数
+}
+
+// ReminderCompletionResponse 提醒完成记录响应
+type ReminderCompletionResponse struct {
+	ID           string        `json:"id"`
+	ReminderID   *string       `json:"reminder_id,omitempty"`
+	ItemID       string        `json:"item_id"`
+	ReminderType string        `json:"reminder_type"`
+	Message      string        `json:"message"`
+	OccurrenceAt time.Time     `json:"occurrence_at"`
+	CompletedAt  time.Time     `json:"completed_at"`
+	Note         *string       `json:"note,omitempty"`
+	CompletedBy  *UserResponse `json:"completed_by,omitempty"`
+}
+
+// ToReminderCompletionResponse 转换提醒完成记录为响应格式
+func ToReminderCompletionResponse(completion *models.ReminderCompletion) ReminderCompletionResponse {
+	response := ReminderCompletionResponse{
+		ID:           completion.ID,
+		ReminderID:   completion.ReminderID,
+		ItemID:       completion.ItemID,
+		ReminderType: completion.ReminderType,
+		Message:      completion.Message,
+		OccurrenceAt: completion.OccurrenceAt,
+		CompletedAt:  completion.CompletedAt,
+		Note:         completion.Note,
+	}
+
+	if completion.User != nil {
+		user := ToUserResponse(completion.User)
+		response.CompletedBy = &user
+	}
+
+	return response
+}
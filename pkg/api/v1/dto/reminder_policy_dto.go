@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// ReminderPolicyRequest 单条提醒策略
+type ReminderPolicyRequest struct {
+	ReminderType   string   `json:"reminder_type" binding:"required,oneof=expire warranty"`
+	DaysBefore     int      `json:"days_before" binding:"min=0,max=365"` // 提前天数，0表示当天
+	NotifyChannels []string `json:"notify_channels"`
+}
+
+// ReplaceReminderPoliciesRequest 整体替换家庭提醒策略请求，空数组表示关闭自动提醒
+type ReplaceReminderPoliciesRequest struct {
+	Policies []ReminderPolicyRequest `json:"policies" binding:"max=20,dive"`
+}
+
+// ReminderPolicyResponse 提醒策略响应
+type ReminderPolicyResponse struct {
+	ID             string    `json:"id"`
+	ReminderType   string    `json:"reminder_type"`
+	DaysBefore     int       `json:"days_before"`
+	NotifyChannels []string  `json:"notify_channels"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// ToReminderPolicyResponse 转换提醒策略模型为响应格式
+func ToReminderPolicyResponse(policy *models.ReminderPolicy) ReminderPolicyResponse {
+	return ReminderPolicyResponse{
+		ID:             policy.ID,
+		ReminderType:   policy.ReminderType,
+		DaysBefore:     policy.DaysBefore,
+		NotifyChannels: policy.NotifyChannels,
+		CreatedAt:      policy.CreatedAt,
+		UpdatedAt:      policy.UpdatedAt,
+	}
+}
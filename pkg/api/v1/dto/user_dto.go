@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"time"
+
+	"nookverse/internal/models"
+)
+
+// RegisterRequest 用户注册请求
+type RegisterRequest struct {
+	Username string  `json:"username" binding:"required,min=3,max=50"`
+	Email    string  `json:"email" binding:"required,email,max=100"`
+	Password string  `json:"password" binding:"required,min=6,max=72"`
+	Nickname *string `json:"nickname,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+}
+
+// LoginRequest 用户登录请求（用户名或邮箱）
+type LoginRequest struct {
+	Account  string `json:"account" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// UserResponse 用户响应
+type UserResponse struct {
+	ID        string     `json:"id"`
+	Username  string     `json:"username"`
+	Email     string     `json:"email"`
+	Nickname  *string    `json:"nickname,omitempty"`
+	AvatarURL *string    `json:"avatar_url,omitempty"`
+	Phone     *string    `json:"phone,omitempty"`
+	Status    int        `json:"status"`
+	LastLogin *time.Time `json:"last_login,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TokenResponse 令牌响应
+type TokenResponse struct {
+	AccessToken      string        `json:"access_token"`
+	AccessExpiresAt  time.Time     `json:"access_expires_at"`
+	RefreshToken     string        `json:"refresh_token"`
+	RefreshExpiresAt time.Time     `json:"refresh_expires_at"`
+	TokenType        string        `json:"token_type"`
+	User             *UserResponse `json:"user,omitempty"`
+}
+
+// ToUserResponse 转换用户模型为响应格式
+func ToUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Nickname:  user.Nickname,
+		AvatarURL: user.AvatarURL,
+		Phone:     user.Phone,
+		Status:    user.Status,
+		LastLogin: user.LastLogin,
+		CreatedAt: user.CreatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// calendarFeedPath 订阅地址路径前缀，与路由 /api/v1/calendar/:token 对应
+const calendarFeedPath = "/api/v1/calendar/"
+
+// CalendarHandler 日历订阅处理器
+type CalendarHandler struct {
+	calendarService services.CalendarService
+}
+
+// NewCalendarHandler 创建日历订阅处理器实例
+func NewCalendarHandler(calendarService services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// GetFeed 获取当前用户的日历订阅状态
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	feed, err := h.calendarService.GetFeed(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取日历订阅失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToCalendarFeedResponse(feed),
+	})
+}
+
+// ResetFeed 创建日历订阅或重置订阅令牌，旧的订阅地址立即失效
+func (h *CalendarHandler) ResetFeed(c *gin.Context) {
+	token, feed, err := h.calendarService.ResetFeedToken(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "生成日历订阅失败: " + err.Error(),
+		})
+		return
+	}
+
+	response := dto.ToCalendarFeedResponse(feed)
+	response.Token = token
+	response.Path = calendarFeedPath + token + ".ics"
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "日历订阅已生成，请妥善保管订阅地址",
+		"data":    response,
+	})
+}
+
+// RevokeFeed 取消日历订阅
+func (h *CalendarHandler) RevokeFeed(c *gin.Context) {
+	if err := h.calendarService.RevokeFeed(c.Request.Context()); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "取消日历订阅失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "日历订阅已取消",
+	})
+}
+
+// RenderFeed 输出iCalendar格式的订阅内容，以路径中的订阅令牌认证
+func (h *CalendarHandler) RenderFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+
+	calendar, err := h.calendarService.RenderFeed(c.Request.Context(), token)
+	if err != nil {
+		c.String(errorStatus(err, http.StatusInternalServerError), "获取日历失败: "+err.Error())
+		return
+	}
+
+	c.Header("Cache-Control", "private, max-age=300")
+	c.Header("Content-Disposition", `inline; filename="nookverse.ics"`)
+	c.Status(http.StatusOK)
+	c.Writer.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := calendar.Write(c.Writer); err != nil {
+		c.Error(err)
+	}
+}
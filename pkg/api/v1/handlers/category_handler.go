@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// CategoryHandler 分类处理器
+type CategoryHandler struct {
+	categoryService   services.CategoryService
+	permissionService services.PermissionService
+}
+
+// NewCategoryHandler 创建分类处理器实例
+func NewCategoryHandler(categoryService services.CategoryService, permissionService services.PermissionService) *CategoryHandler {
+	return &CategoryHandler{
+		categoryService:   categoryService,
+		permissionService: permissionService,
+	}
+}
+
+// CreateCategory 创建分类
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req dto.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	category := &models.Category{
+		Name:      req.Name,
+		ParentID:  req.ParentID,
+		Icon:      getValueOrDefault(req.Icon, ""),
+		Color:     getValueOrDefault(req.Color, "#666666"),
+		SortOrder: getValueOrDefault(req.SortOrder, 0),
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeCategory(c.Request.Context(), "", services.ActionCreate)) {
+		return
+	}
+
+	if err := h.categoryService.CreateCategory(c.Request.Context(), category); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "创建分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "分类创建成功",
+		"data":    dto.ToCategoryResponse(category),
+	})
+}
+
+// ListCategories 获取分类列表（平铺）
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categoryService.ListCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取分类列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toCategoryResponses(categories),
+	})
+}
+
+// GetCategoryTree 获取分类树
+func (h *CategoryHandler) GetCategoryTree(c *gin.Context) {
+	tree, err := h.categoryService.GetCategoryTree(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取分类树失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toCategoryResponses(tree),
+	})
+}
+
+// GetCategory 获取分类详情
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	category, err := h.categoryService.GetCategoryByID(c.Request.Context(), c.Param("categoryId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToCategoryResponse(category),
+	})
+}
+
+// UpdateCategory 更新分类
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	if !authorized(c, h.permissionService.AuthorizeCategory(c.Request.Context(), c.Param("categoryId"), services.ActionEdit)) {
+		return
+	}
+
+	existing, err := h.categoryService.GetCategoryByID(c.Request.Context(), c.Param("categoryId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "分类不存在",
+		})
+		return
+	}
+
+	var req dto.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	// 更新字段
+	if req.Name != nil {
+		existing.Name = *req.Name
+	}
+	if req.ParentID != nil {
+		existing.ParentID = req.ParentID
+	}
+	if req.ClearParent {
+		existing.ParentID = nil
+	}
+	if req.Icon != nil {
+		existing.Icon = *req.Icon
+	}
+	if req.Color != nil {
+		existing.Color = *req.Color
+	}
+	if req.SortOrder != nil {
+		existing.SortOrder = *req.SortOrder
+	}
+
+	if err := h.categoryService.UpdateCategory(c.Request.Context(), existing); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "更新分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "分类更新成功",
+		"data":    dto.ToCategoryResponse(existing),
+	})
+}
+
+// DeleteCategory 删除分类
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if !authorized(c, h.permissionService.AuthorizeCategory(c.Request.Context(), c.Param("categoryId"), services.ActionDelete)) {
+		return
+	}
+
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), c.Param("categoryId")); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "删除分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "分类删除成功",
+	})
+}
+
+// ReorderCategories 批量调整分类排序
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	var req dto.ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	orders := make([]services.CategoryOrder, 0, len(req.Orders))
+	for _, order := range req.Orders {
+		if !authorized(c, h.permissionService.AuthorizeCategory(c.Request.Context(), order.ID, services.ActionEdit)) {
+			return
+		}
+		orders = append(orders, services.CategoryOrder{ID: order.ID, SortOrder: order.SortOrder})
+	}
+
+	if err := h.categoryService.ReorderCategories(c.Request.Context(), orders); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "调整分类排序失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "分类排序已更新",
+	})
+}
+
+// MergeCategory 将分类合并到目标分类
+func (h *CategoryHandler) MergeCategory(c *gin.Context) {
+	var req dto.MergeCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !authorized(c, h.permissionService.AuthorizeCategory(ctx, c.Param("categoryId"), services.ActionDelete)) {
+		return
+	}
+	if !authorized(c, h.permissionService.AuthorizeCategory(ctx, req.TargetID, services.ActionView)) {
+		return
+	}
+
+	moved, err := h.categoryService.MergeCategory(c.Request.Context(), c.Param("categoryId"), req.TargetID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "合并分类失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "分类合并成功",
+		"data": gin.H{
+			"target_id":   req.TargetID,
+			"moved_items": moved,
+		},
+	})
+}
+
+// toCategoryResponses 转换分类列表为响应格式
+func toCategoryResponses(categories []models.Category) []dto.CategoryResponse {
+	responses := make([]dto.CategoryResponse, 0, len(categories))
+	for i := range categories {
+		responses = append(responses, dto.ToCategoryResponse(&categories[i]))
+	}
+	return responses
+}
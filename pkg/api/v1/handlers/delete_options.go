@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+)
+
+// deleteOptions 读取删除房屋、房间和容器的查询参数：cascade、dry_run、move_to_room_id 或 move_to_container_id，
+// 指定移动目标时校验用户能否在目标位置放置物品；参数不正确时写入错误响应并返回false
+func deleteOptions(c *gin.Context, permissionService services.PermissionService) (services.DeleteOptions, bool) {
+	opts := services.DeleteOptions{
+		Cascade: c.Query("cascade") == "true",
+		DryRun:  c.Query("dry_run") == "true",
+	}
+
+	roomID, containerID := c.Query("move_to_room_id"), c.Query("move_to_container_id")
+	if roomID == "" && containerID == "" {
+		return opts, true
+	}
+
+	if roomID != "" && containerID != "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "move_to_room_id 和 move_to_container_id 只能指定其一",
+		})
+		return opts, false
+	}
+	if opts.Cascade {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "cascade 不能与移动目标同时指定",
+		})
+		return opts, false
+	}
+
+	var target services.MoveTarget
+	if roomID != "" {
+		target.RoomID = &roomID
+	} else {
+		target.ContainerID = &containerID
+	}
+	if (target.RoomID != nil && !isValidUUID(roomID)) || (target.ContainerID != nil && !isValidUUID(containerID)) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "移动目标ID格式不正确",
+		})
+		return opts, false
+	}
+
+	if !authorized(c, authorizeLocation(c.Request.Context(), permissionService, target.RoomID, target.ContainerID)) {
+		return opts, false
+	}
+
+	opts.MoveTo = &target
+	return opts, true
+}
+
+// respondDeleted 输出删除结果，dry_run 时只返回将被删除的内容
+func respondDeleted(c *gin.Context, summary *services.DeleteSummary, message string) {
+	if summary.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"data": summary,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"data":    summary,
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/recurrence"
+	"nookverse/internal/services"
+)
+
+// errorStatus 根据服务层错误确定HTTP状态码，无法识别的错误使用fallback
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrUnsupportedNotifyChannel), errors.Is(err, recurrence.ErrInvalidRule),
+		errors.Is(err, services.ErrMoveTargetInScope):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrInvalidReminderTransition), errors.Is(err, services.ErrCategoryInUse),
+		errors.Is(err, services.ErrParentInTrash), errors.Is(err, services.ErrNotEmpty):
+		return http.StatusConflict
+	default:
+		return fallback
+	}
+}
+
+// authorized 处理权限校验结果，校验失败时写入错误响应并返回false
+func authorized(c *gin.Context, err error) bool {
+	if err == nil {
+		return true
+	}
+	c.JSON(errorStatus(err, http.StatusForbidden), gin.H{
+		"error": "权限校验失败: " + err.Error(),
+	})
+	return false
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// defaultInviteExpireHours 邀请码默认有效期（小时）
+const defaultInviteExpireHours = 72
+
+// FamilyHandler 家庭处理器
+type FamilyHandler struct {
+	familyService     services.FamilyService
+	permissionService services.PermissionService
+}
+
+// NewFamilyHandler 创建家庭处理器实例
+func NewFamilyHandler(familyService services.FamilyService, permissionService services.PermissionService) *FamilyHandler {
+	return &FamilyHandler{
+		familyService:     familyService,
+		permissionService: permissionService,
+	}
+}
+
+// CreateFamily 创建家庭
+func (h *FamilyHandler) CreateFamily(c *gin.Context) {
+	var req dto.CreateFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	family := &models.Family{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+
+	if err := h.familyService.CreateFamily(c.Request.Context(), family); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "创建家庭失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "家庭创建成功",
+		"data":    dto.ToFamilyResponse(family, true),
+	})
+}
+
+// ListFamilies 获取当前用户所在的家庭
+func (h *FamilyHandler) ListFamilies(c *gin.Context) {
+	families, err := h.familyService.ListFamilies(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取家庭列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.FamilyResponse, 0, len(families))
+	for i := range families {
+		responses = append(responses, dto.ToFamilyResponse(&families[i], h.canManage(c, families[i].ID)))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+	})
+}
+
+// GetFamily 获取家庭详情
+func (h *FamilyHandler) GetFamily(c *gin.Context) {
+	family, err := h.familyService.GetFamilyByID(c.Request.Context(), c.Param("familyId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取家庭失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToFamilyResponse(family, h.canManage(c, family.ID)),
+	})
+}
+
+// RotateInviteCode 生成新的邀请码
+func (h *FamilyHandler) RotateInviteCode(c *gin.Context) {
+	familyID := c.Param("familyId")
+
+	var req dto.RotateInviteCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage)) {
+		return
+	}
+
+	hours := req.ExpiresInHours
+	if hours == 0 {
+		hours = defaultInviteExpireHours
+	}
+
+	family, err := h.familyService.RotateInviteCode(c.Request.Context(), familyID, req.Role, time.Duration(hours)*time.Hour)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "生成邀请码失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "邀请码已更新",
+		"data":    dto.ToFamilyResponse(family, true),
+	})
+}
+
+// JoinFamily 通过邀请码申请加入家庭
+func (h *FamilyHandler) JoinFamily(c *gin.Context) {
+	var req dto.JoinFamilyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	member, err := h.familyService.JoinFamily(c.Request.Context(), req.InviteCode)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "加入家庭失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "已提交加入申请，等待管理员审核",
+		"data":    dto.ToFamilyMemberResponse(member),
+	})
+}
+
+// ListMembers 获取家庭成员列表
+func (h *FamilyHandler) ListMembers(c *gin.Context) {
+	members, err := h.familyService.ListMembers(c.Request.Context(), c.Param("familyId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取家庭成员失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.FamilyMemberResponse, 0, len(members))
+	for i := range members {
+		responses = append(responses, dto.ToFamilyMemberResponse(&members[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+	})
+}
+
+// ApproveMember 通过加入申请
+func (h *FamilyHandler) ApproveMember(c *gin.Context) {
+	familyID := c.Param("familyId")
+
+	if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage)) {
+		return
+	}
+
+	member, err := h.familyService.ApproveMember(c.Request.Context(), familyID, c.Param("userId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "审核失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已通过加入申请",
+		"data":    dto.ToFamilyMemberResponse(member),
+	})
+}
+
+// RejectMember 拒绝加入申请
+func (h *FamilyHandler) RejectMember(c *gin.Context) {
+	familyID := c.Param("familyId")
+
+	if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage)) {
+		return
+	}
+
+	if err := h.familyService.RejectMember(c.Request.Context(), familyID, c.Param("userId")); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "审核失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已拒绝加入申请",
+	})
+}
+
+// RemoveMember 移除家庭成员，成员也可以移除自己以退出家庭
+func (h *FamilyHandler) RemoveMember(c *gin.Context) {
+	familyID := c.Param("familyId")
+	userID := c.Param("userId")
+
+	if userID != c.GetString("user_id") {
+		if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage)) {
+			return
+		}
+	}
+
+	if err := h.familyService.RemoveMember(c.Request.Context(), familyID, userID); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "移除成员失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "成员已移除",
+	})
+}
+
+// TransferOwnership 转让家庭所有权
+func (h *FamilyHandler) TransferOwnership(c *gin.Context) {
+	var req dto.TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	family, err := h.familyService.TransferOwnership(c.Request.Context(), c.Param("familyId"), req.UserID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "转让所有权失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "所有权转让成功",
+		"data":    dto.ToFamilyResponse(family, h.canManage(c, family.ID)),
+	})
+}
+
+// canManage 当前用户能否管理家庭，管理者才能看到邀请码
+func (h *FamilyHandler) canManage(c *gin.Context, familyID string) bool {
+	return h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage) == nil
+}
@@ -12,13 +12,15 @@ import (
 
 // HouseHandler 房屋处理器
 type HouseHandler struct {
-	houseService services.HouseService
+	houseService      services.HouseService
+	permissionService services.PermissionService
 }
 
 // NewHouseHandler 创建房屋处理器实例
-func NewHouseHandler(houseService services.HouseService) *HouseHandler {
+func NewHouseHandler(houseService services.HouseService, permissionService services.PermissionService) *HouseHandler {
 	return &HouseHandler{
-		houseService: houseService,
+		houseService:      houseService,
+		permissionService: permissionService,
 	}
 }
 
@@ -42,9 +44,14 @@ func (h *HouseHandler) CreateHouse(c *gin.Context) {
 		Metadata:    req.Metadata,
 	}
 
+	familyID := getValueOrDefault(req.FamilyID, "")
+	if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionCreate)) {
+		return
+	}
+
 	// 创建房屋
-	if err := h.houseService.CreateHouse(c.Request.Context(), house); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if err := h.houseService.CreateHouse(c.Request.Context(), house, familyID); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "创建房屋失败: " + err.Error(),
 		})
 		return
@@ -78,7 +85,7 @@ func (h *HouseHandler) GetHouse(c *gin.Context) {
 	
 	house, err := h.houseService.GetHouseByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "房屋不存在: " + err.Error(),
 		})
 		return
@@ -96,12 +103,16 @@ func (h *HouseHandler) UpdateHouse(c *gin.Context) {
 	// 获取现有房屋
 	existingHouse, err := h.houseService.GetHouseByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "房屋不存在",
 		})
 		return
 	}
 
+	if !authorized(c, h.permissionService.AuthorizeHouse(c.Request.Context(), id, services.ActionEdit)) {
+		return
+	}
+
 	var req dto.UpdateHouseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -132,7 +143,7 @@ func (h *HouseHandler) UpdateHouse(c *gin.Context) {
 
 	// 保存更新
 	if err := h.houseService.UpdateHouse(c.Request.Context(), existingHouse); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "更新房屋失败: " + err.Error(),
 		})
 		return
@@ -148,16 +159,24 @@ func (h *HouseHandler) UpdateHouse(c *gin.Context) {
 func (h *HouseHandler) DeleteHouse(c *gin.Context) {
 	id := c.Param("houseId")
 	
-	if err := h.houseService.DeleteHouse(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if !authorized(c, h.permissionService.AuthorizeHouse(c.Request.Context(), id, services.ActionDelete)) {
+		return
+	}
+
+	opts, ok := deleteOptions(c, h.permissionService)
+	if !ok {
+		return
+	}
+
+	summary, err := h.houseService.DeleteHouseWithOptions(c.Request.Context(), id, opts)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "删除房屋失败: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "房屋删除成功",
-	})
+	respondDeleted(c, summary, "房屋删除成功")
 }
 
 // ListHouses 分页查询房屋
@@ -216,7 +235,7 @@ func (h *HouseHandler) ListHouses(c *gin.Context) {
 	// 执行查询
 	houses, total, err := h.houseService.ListHouses(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "查询房屋列表失败: " + err.Error(),
 		})
 		return
@@ -278,7 +297,7 @@ func (h *HouseHandler) SearchHouses(c *gin.Context) {
 	// 执行搜索
 	houses, total, err := h.houseService.SearchHouses(c.Request.Context(), query, filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "搜索房屋失败: " + err.Error(),
 		})
 		return
@@ -328,6 +347,10 @@ func (h *HouseHandler) CreateRoom(c *gin.Context) {
 		return
 	}
 
+	if !authorized(c, h.permissionService.AuthorizeHouse(c.Request.Context(), houseID, services.ActionCreate)) {
+		return
+	}
+
 	room := &models.Room{
 		HouseID:      houseID,
 		Name:         req.Name,
@@ -339,7 +362,7 @@ func (h *HouseHandler) CreateRoom(c *gin.Context) {
 	}
 
 	if err := h.houseService.CreateRoom(c.Request.Context(), room); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "创建房间失败: " + err.Error(),
 		})
 		return
@@ -372,7 +395,7 @@ func (h *HouseHandler) GetRoom(c *gin.Context) {
 	
 	room, err := h.houseService.GetRoomByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "房间不存在: " + err.Error(),
 		})
 		return
@@ -390,12 +413,16 @@ func (h *HouseHandler) UpdateRoom(c *gin.Context) {
 	// 获取现有房间
 	existingRoom, err := h.houseService.GetRoomByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "房间不存在",
 		})
 		return
 	}
 
+	if !authorized(c, h.permissionService.AuthorizeRoom(c.Request.Context(), id, services.ActionEdit)) {
+		return
+	}
+
 	var req dto.UpdateRoomRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -426,7 +453,7 @@ func (h *HouseHandler) UpdateRoom(c *gin.Context) {
 
 	// 保存更新
 	if err := h.houseService.UpdateRoom(c.Request.Context(), existingRoom); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "更新房间失败: " + err.Error(),
 		})
 		return
@@ -442,16 +469,24 @@ func (h *HouseHandler) UpdateRoom(c *gin.Context) {
 func (h *HouseHandler) DeleteRoom(c *gin.Context) {
 	id := c.Param("roomId")
 	
-	if err := h.houseService.DeleteRoom(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if !authorized(c, h.permissionService.AuthorizeRoom(c.Request.Context(), id, services.ActionDelete)) {
+		return
+	}
+
+	opts, ok := deleteOptions(c, h.permissionService)
+	if !ok {
+		return
+	}
+
+	summary, err := h.houseService.DeleteRoomWithOptions(c.Request.Context(), id, opts)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "删除房间失败: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "房间删除成功",
-	})
+	respondDeleted(c, summary, "房间删除成功")
 }
 
 // GetRoomsByHouse 获取房屋内房间
@@ -460,7 +495,7 @@ func (h *HouseHandler) GetRoomsByHouse(c *gin.Context) {
 	
 	rooms, err := h.houseService.GetRoomsByHouse(c.Request.Context(), houseID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取房屋房间失败: " + err.Error(),
 		})
 		return
@@ -481,7 +516,7 @@ func (h *HouseHandler) GetRoomsByHouse(c *gin.Context) {
 func (h *HouseHandler) GetHouseStatistics(c *gin.Context) {
 	stats, err := h.houseService.GetHouseStatistics(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取统计数据失败: " + err.Error(),
 		})
 		return
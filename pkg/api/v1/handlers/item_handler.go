@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 	"regexp"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"nookverse/internal/models"
@@ -42,16 +44,48 @@ func float64Ptr(f float64) *float64 {
 
 // ItemHandler 物品处理器
 type ItemHandler struct {
-	itemService services.ItemService
+	itemService       services.ItemService
+	permissionService services.PermissionService
 }
 
 // NewItemHandler 创建物品处理器实例
-func NewItemHandler(itemService services.ItemService) *ItemHandler {
+func NewItemHandler(itemService services.ItemService, permissionService services.PermissionService) *ItemHandler {
 	return &ItemHandler{
-		itemService: itemService,
+		itemService:       itemService,
+		permissionService: permissionService,
 	}
 }
 
+// authorizeLocation 校验用户能否在目标容器或房间中放置物品
+func authorizeLocation(ctx context.Context, permissionService services.PermissionService, roomID, containerID *string) error {
+	if containerID != nil {
+		return permissionService.AuthorizeItem(ctx, *containerID, services.ActionCreate)
+	}
+	if roomID != nil {
+		return permissionService.AuthorizeRoom(ctx, *roomID, services.ActionCreate)
+	}
+	return nil
+}
+
+// toItemResponses 转换物品列表为响应格式，withLocation为true时附加完整位置路径
+func (h *ItemHandler) toItemResponses(ctx context.Context, items []models.Item, withLocation bool) ([]dto.ItemResponse, error) {
+	var paths map[string][]services.LocationNode
+	if withLocation {
+		var err error
+		if paths, err = h.itemService.GetLocationPaths(ctx, items); err != nil {
+			return nil, err
+		}
+	}
+
+	var responses []dto.ItemResponse
+	for i := range items {
+		resp := dto.ToItemResponse(&items[i])
+		resp.Location = dto.ToLocationResponse(paths[items[i].ID])
+		responses = append(responses, resp)
+	}
+	return responses, nil
+}
+
 // CreateItem 创建物品
 func (h *ItemHandler) CreateItem(c *gin.Context) {
 	var req dto.CreateItemRequest
@@ -83,9 +117,13 @@ func (h *ItemHandler) CreateItem(c *gin.Context) {
 		Labels:         req.Labels,
 	}
 
+	if !authorized(c, authorizeLocation(c.Request.Context(), h.permissionService, item.RoomID, item.ContainerID)) {
+		return
+	}
+
 	// 创建物品
 	if err := h.itemService.CreateItem(c.Request.Context(), item); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "创建物品失败: " + err.Error(),
 		})
 		return
@@ -119,14 +157,22 @@ func (h *ItemHandler) GetItem(c *gin.Context) {
 	
 	item, err := h.itemService.GetItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "物品不存在: " + err.Error(),
 		})
 		return
 	}
 
+	responses, err := h.toItemResponses(c.Request.Context(), []models.Item{*item}, true)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品位置失败: " + err.Error(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"data": dto.ToItemResponse(item),
+		"data": responses[0],
 	})
 }
 
@@ -137,12 +183,16 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 	// 获取现有物品
 	existingItem, err := h.itemService.GetItemByID(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
+		c.JSON(errorStatus(err, http.StatusNotFound), gin.H{
 			"error": "物品不存在",
 		})
 		return
 	}
 
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), id, services.ActionEdit)) {
+		return
+	}
+
 	var req dto.UpdateItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -151,7 +201,28 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 		return
 	}
 
+	// 容器内物品的房间由容器决定，指定不一致的房间会被忽略，直接拒绝以免客户端误以为修改成功
+	if req.RoomID != nil && req.ContainerID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "room_id与container_id只能指定一个，放入容器时房间随容器确定",
+		})
+		return
+	}
+	if req.RoomID != nil && existingItem.ContainerID != nil && (existingItem.RoomID == nil || *req.RoomID != *existingItem.RoomID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品位于容器中，房间随容器确定；移出容器请使用 POST /api/v1/items/{itemId}/move",
+		})
+		return
+	}
+
 	// 更新字段
+	// 变更位置时需要目标位置的放置权限
+	if req.RoomID != nil || req.ContainerID != nil {
+		if !authorized(c, authorizeLocation(c.Request.Context(), h.permissionService, req.RoomID, req.ContainerID)) {
+			return
+		}
+	}
+
 	if req.Name != nil {
 		existingItem.Name = *req.Name
 	}
@@ -206,7 +277,7 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 
 	// 保存更新
 	if err := h.itemService.UpdateItem(c.Request.Context(), existingItem); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "更新物品失败: " + err.Error(),
 		})
 		return
@@ -222,16 +293,24 @@ func (h *ItemHandler) UpdateItem(c *gin.Context) {
 func (h *ItemHandler) DeleteItem(c *gin.Context) {
 	id := c.Param("itemId")
 	
-	if err := h.itemService.DeleteItem(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), id, services.ActionDelete)) {
+		return
+	}
+
+	opts, ok := deleteOptions(c, h.permissionService)
+	if !ok {
+		return
+	}
+
+	summary, err := h.itemService.DeleteItemWithOptions(c.Request.Context(), id, opts)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "删除物品失败: " + err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "物品删除成功",
-	})
+	respondDeleted(c, summary, "物品删除成功")
 }
 
 // ListItems 分页查询物品
@@ -246,6 +325,7 @@ func (h *ItemHandler) ListItems(c *gin.Context) {
 	if categoryID := c.Query("category_id"); categoryID != "" {
 		filters.CategoryID = &categoryID
 	}
+	filters.IncludeDescendants = c.Query("include_descendants") == "true"
 	
 	if status := c.Query("status"); status != "" {
 		filters.Status = &status
@@ -275,16 +355,19 @@ func (h *ItemHandler) ListItems(c *gin.Context) {
 	// 执行查询
 	items, total, err := h.itemService.ListItems(c.Request.Context(), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "查询物品列表失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 转换为响应格式
-	var responses []dto.ItemResponse
-	for _, item := range items {
-		responses = append(responses, dto.ToItemResponse(&item))
+	// 转换为响应格式，include_location=true 时附加位置路径
+	responses, err := h.toItemResponses(c.Request.Context(), items, c.Query("include_location") == "true")
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品位置失败: " + err.Error(),
+		})
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -310,6 +393,11 @@ func (h *ItemHandler) SearchItems(c *gin.Context) {
 	// 构建查询过滤条件
 	var filters services.ItemFilters
 	
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		filters.CategoryID = &categoryID
+	}
+	filters.IncludeDescendants = c.Query("include_descendants") == "true"
+	
 	if page := c.Query("page"); page != "" {
 		if pageNum, err := strconv.Atoi(page); err == nil {
 			filters.Page = pageNum
@@ -323,18 +411,28 @@ func (h *ItemHandler) SearchItems(c *gin.Context) {
 	}
 
 	// 执行搜索
-	items, total, err := h.itemService.SearchItems(c.Request.Context(), query, filters)
+	hits, total, err := h.itemService.SearchItems(c.Request.Context(), query, filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "搜索物品失败: " + err.Error(),
 		})
 		return
 	}
 
-	// 转换为响应格式
-	var responses []dto.ItemResponse
-	for _, item := range items {
-		responses = append(responses, dto.ToItemResponse(&item))
+	// 转换为响应格式，搜索结果始终附加位置路径和高亮片段
+	items := make([]models.Item, 0, len(hits))
+	for _, hit := range hits {
+		items = append(items, hit.Item)
+	}
+	responses, err := h.toItemResponses(c.Request.Context(), items, true)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品位置失败: " + err.Error(),
+		})
+		return
+	}
+	for i := range responses {
+		responses[i].Snippet = hits[i].Snippet
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -354,7 +452,7 @@ func (h *ItemHandler) GetItemsByRoom(c *gin.Context) {
 	
 	items, err := h.itemService.GetItemsByRoom(c.Request.Context(), roomID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取房间物品失败: " + err.Error(),
 		})
 		return
@@ -371,7 +469,40 @@ func (h *ItemHandler) GetItemsByRoom(c *gin.Context) {
 	})
 }
 
-// MoveItem 移动物品到容器
+// GetItemsByCategory 获取分类下物品，include_descendants=true 时包含全部后代分类
+func (h *ItemHandler) GetItemsByCategory(c *gin.Context) {
+	categoryID := c.Param("categoryId")
+
+	if !isValidUUID(categoryID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "分类ID格式不正确",
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeCategory(c.Request.Context(), categoryID, services.ActionView)) {
+		return
+	}
+
+	items, err := h.itemService.GetItemsByCategory(c.Request.Context(), categoryID, c.Query("include_descendants") == "true")
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取分类物品失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ItemResponse, 0, len(items))
+	for i := range items {
+		responses = append(responses, dto.ToItemResponse(&items[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+	})
+}
+
+// MoveItem 移动物品到容器或房间
 func (h *ItemHandler) MoveItem(c *gin.Context) {
 	itemID := c.Param("itemId")
 	
@@ -398,8 +529,24 @@ func (h *ItemHandler) MoveItem(c *gin.Context) {
 		return
 	}
 
-	if err := h.itemService.MoveItemToContainer(c.Request.Context(), itemID, req.ContainerID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+	if (req.ContainerID == nil) == (req.RoomID == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "必须且只能指定container_id或room_id之一",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !authorized(c, h.permissionService.AuthorizeItem(ctx, itemID, services.ActionEdit)) {
+		return
+	}
+	if !authorized(c, authorizeLocation(ctx, h.permissionService, req.RoomID, req.ContainerID)) {
+		return
+	}
+
+	target := services.MoveTarget{ContainerID: req.ContainerID, RoomID: req.RoomID}
+	if err := h.itemService.MoveItem(ctx, itemID, target); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "移动物品失败: " + err.Error(),
 		})
 		return
@@ -416,7 +563,7 @@ func (h *ItemHandler) GetContainerItems(c *gin.Context) {
 	
 	items, err := h.itemService.GetContainerItems(c.Request.Context(), containerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取容器物品失败: " + err.Error(),
 		})
 		return
@@ -433,6 +580,43 @@ func (h *ItemHandler) GetContainerItems(c *gin.Context) {
 	})
 }
 
+// GetItemTree 获取容器的完整子树及汇总信息
+func (h *ItemHandler) GetItemTree(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	if !isValidUUID(itemID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品ID格式不正确",
+		})
+		return
+	}
+
+	// depth为0或未指定时展开完整子树
+	depth := 0
+	if value := c.Query("depth"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "depth必须为非负整数",
+			})
+			return
+		}
+		depth = parsed
+	}
+
+	tree, err := h.itemService.GetItemTree(c.Request.Context(), itemID, depth)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品树失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToItemTreeResponse(tree),
+	})
+}
+
 // CreateReminder 创建提醒
 func (h *ItemHandler) CreateReminder(c *gin.Context) {
 	itemID := c.Param("itemId")
@@ -460,16 +644,21 @@ func (h *ItemHandler) CreateReminder(c *gin.Context) {
 		return
 	}
 
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionEdit)) {
+		return
+	}
+
 	reminder := &models.Reminder{
 		ItemID:         itemID,
 		ReminderType:   req.ReminderType,
 		TriggerTime:    req.TriggerTime,
 		Message:        req.Message,
 		NotifyChannels: req.NotifyChannels,
+		Recurrence:     req.Recurrence,
 	}
 
 	if err := h.itemService.CreateReminder(c.Request.Context(), reminder); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "创建提醒失败: " + err.Error(),
 		})
 		return
@@ -492,7 +681,7 @@ func (h *ItemHandler) GetUpcomingReminders(c *gin.Context) {
 
 	reminders, err := h.itemService.GetUpcomingReminders(c.Request.Context(), days)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取提醒列表失败: " + err.Error(),
 		})
 		return
@@ -520,9 +709,15 @@ func (h *ItemHandler) GetItemStatistics(c *gin.Context) {
 		return
 	}
 
-	stats, err := h.itemService.GetItemStatistics(c.Request.Context(), userID.(string))
+	var filters services.ItemFilters
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		filters.CategoryID = &categoryID
+	}
+	filters.IncludeDescendants = c.Query("include_descendants") == "true"
+
+	stats, err := h.itemService.GetItemStatistics(c.Request.Context(), userID.(string), filters)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
 			"error": "获取统计数据失败: " + err.Error(),
 		})
 		return
@@ -531,4 +726,204 @@ func (h *ItemHandler) GetItemStatistics(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"data": stats,
 	})
-}
\ No newline at end of file
+}
+
+// versionParam 读取路径中的版本号，格式不正确时直接返回400
+func versionParam(c *gin.Context) (int, bool) {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "版本号格式不正确",
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+// ListItemVersions 获取物品的版本列表，最新的在前
+func (h *ItemHandler) ListItemVersions(c *gin.Context) {
+	itemID := c.Param("itemId")
+	if !isValidUUID(itemID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品ID格式不正确",
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	page, pageSize := pagination(c)
+	versions, total, err := h.itemService.ListItemVersions(c.Request.Context(), itemID, page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ItemVersionResponse, 0, len(versions))
+	for i := range versions {
+		responses = append(responses, dto.ToItemVersionResponse(&versions[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetItemVersion 获取物品的指定版本
+func (h *ItemHandler) GetItemVersion(c *gin.Context) {
+	itemID := c.Param("itemId")
+	version, ok := versionParam(c)
+	if !ok {
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	itemVersion, err := h.itemService.GetItemVersion(c.Request.Context(), itemID, version)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToItemVersionResponse(itemVersion),
+	})
+}
+
+// GetItemAsOf 获取物品在指定时间点（at，RFC3339格式）的数据
+func (h *ItemHandler) GetItemAsOf(c *gin.Context) {
+	itemID := c.Param("itemId")
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "时间格式不正确，应为RFC3339格式，如 2024-01-02T15:04:05+08:00",
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	itemVersion, err := h.itemService.GetItemAsOf(c.Request.Context(), itemID, at)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品历史数据失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToItemVersionResponse(itemVersion),
+	})
+}
+
+// DiffItemVersions 比较物品的两个版本，to 为空时与最新版本比较
+func (h *ItemHandler) DiffItemVersions(c *gin.Context) {
+	itemID := c.Param("itemId")
+	from, ok := versionParam(c)
+	if !ok {
+		return
+	}
+
+	to := 0
+	if value := c.Query("to"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "版本号格式不正确",
+			})
+			return
+		}
+		to = parsed
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	diff, err := h.itemService.DiffItemVersions(c.Request.Context(), itemID, from, to)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "比较物品版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": diff,
+	})
+}
+
+// RestoreItemVersion 将物品恢复为指定版本，位置发生变化时需要目标位置的放置权限
+func (h *ItemHandler) RestoreItemVersion(c *gin.Context) {
+	itemID := c.Param("itemId")
+	version, ok := versionParam(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	if !authorized(c, h.permissionService.AuthorizeItem(ctx, itemID, services.ActionEdit)) {
+		return
+	}
+
+	current, err := h.itemService.GetItemByID(ctx, itemID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "物品不存在",
+		})
+		return
+	}
+	itemVersion, err := h.itemService.GetItemVersion(ctx, itemID, version)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品版本失败: " + err.Error(),
+		})
+		return
+	}
+	target, err := services.ItemFromVersion(itemVersion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// 容器内物品的房间由容器决定，只比较实际生效的位置
+	roomID := target.RoomID
+	if target.ContainerID != nil {
+		roomID = nil
+	}
+	if !services.SameID(current.ContainerID, target.ContainerID) || (target.ContainerID == nil && !services.SameID(current.RoomID, roomID)) {
+		if !authorized(c, authorizeLocation(ctx, h.permissionService, roomID, target.ContainerID)) {
+			return
+		}
+	}
+
+	item, err := h.itemService.RestoreItemVersion(ctx, itemID, version)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "恢复物品版本失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "物品已恢复",
+		"data":    dto.ToItemResponse(item),
+	})
+}
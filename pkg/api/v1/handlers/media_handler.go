@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// multipartOverhead 请求体中除文件内容外的表单开销上限
+const multipartOverhead = 1 << 20
+
+// MediaHandler 物品媒体文件处理器
+type MediaHandler struct {
+	mediaService      services.MediaService
+	permissionService services.PermissionService
+}
+
+// NewMediaHandler 创建媒体文件处理器实例
+func NewMediaHandler(mediaService services.MediaService, permissionService services.PermissionService) *MediaHandler {
+	return &MediaHandler{
+		mediaService:      mediaService,
+		permissionService: permissionService,
+	}
+}
+
+// UploadMedia 上传物品媒体文件（multipart表单字段 file，可选 alt_text）
+func (h *MediaHandler) UploadMedia(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionEdit)) {
+		return
+	}
+
+	// 限制请求体大小，避免超大文件先被完整读入临时文件
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.mediaService.MaxSize()+multipartOverhead)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "上传文件失败: " + services.ErrMediaTooLarge.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+	if fileHeader.Size > h.mediaService.MaxSize() {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error": "上传文件失败: " + services.ErrMediaTooLarge.Error(),
+		})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "读取上传文件失败: " + err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	var altText *string
+	if value := c.PostForm("alt_text"); value != "" {
+		altText = &value
+	}
+
+	media, err := h.mediaService.UploadMedia(c.Request.Context(), itemID, file, altText)
+	if err != nil {
+		c.JSON(mediaErrorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "上传文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "文件上传成功",
+		"data":    dto.ToMediaFileResponse(media),
+	})
+}
+
+// ListMedia 获取物品的媒体文件列表
+func (h *MediaHandler) ListMedia(c *gin.Context) {
+	media, err := h.mediaService.ListMedia(c.Request.Context(), c.Param("itemId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取媒体文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.MediaFileResponse, 0, len(media))
+	for i := range media {
+		responses = append(responses, dto.ToMediaFileResponse(&media[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+	})
+}
+
+// GetMediaContent 返回媒体文件内容，支持Range分段请求
+func (h *MediaHandler) GetMediaContent(c *gin.Context) {
+	content, err := h.mediaService.OpenMedia(c.Request.Context(), c.Param("itemId"), c.Param("mediaId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取媒体文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	contentType := ""
+	if content.Media.MimeType != nil {
+		contentType = *content.Media.MimeType
+	}
+	serveMediaContent(c, content, contentType)
+}
+
+// GetThumbnail 返回媒体文件的缩略图
+func (h *MediaHandler) GetThumbnail(c *gin.Context) {
+	content, err := h.mediaService.OpenThumbnail(c.Request.Context(), c.Param("itemId"), c.Param("mediaId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取缩略图失败: " + err.Error(),
+		})
+		return
+	}
+
+	serveMediaContent(c, content, "image/jpeg")
+}
+
+// RegenerateThumbnail 重新生成媒体文件的缩略图
+func (h *MediaHandler) RegenerateThumbnail(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionEdit)) {
+		return
+	}
+
+	media, err := h.mediaService.RegenerateThumbnail(c.Request.Context(), itemID, c.Param("mediaId"))
+	if err != nil {
+		c.JSON(mediaErrorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "生成缩略图失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "缩略图已重新生成",
+		"data":    dto.ToMediaFileResponse(media),
+	})
+}
+
+// DeleteMedia 删除物品媒体文件
+func (h *MediaHandler) DeleteMedia(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionEdit)) {
+		return
+	}
+
+	if err := h.mediaService.DeleteMedia(c.Request.Context(), itemID, c.Param("mediaId")); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "删除媒体文件失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "媒体文件删除成功",
+	})
+}
+
+// ReorderMedia 调整物品媒体文件的排列顺序
+func (h *MediaHandler) ReorderMedia(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	var req dto.ReorderMediaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionEdit)) {
+		return
+	}
+
+	if err := h.mediaService.ReorderMedia(c.Request.Context(), itemID, req.MediaIDs); err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "调整媒体文件顺序失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "媒体文件顺序已更新",
+	})
+}
+
+// serveMediaContent 返回媒体内容：存储后端提供预签名地址时重定向，否则由API返回并支持Range
+func serveMediaContent(c *gin.Context, content *services.MediaContent, contentType string) {
+	if content.RedirectURL != "" {
+		c.Header("Cache-Control", "private, no-store")
+		c.Redirect(http.StatusFound, content.RedirectURL)
+		return
+	}
+	defer content.Body.Close()
+
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	// 内容按哈希寻址，同一地址的内容不会变化
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	c.Header("ETag", `"`+path.Base(content.Key)+`"`)
+
+	http.ServeContent(c.Writer, c.Request, path.Base(content.Key), content.Media.CreatedAt, content.Body)
+}
+
+// mediaErrorStatus 媒体文件错误对应的HTTP状态码
+func mediaErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, services.ErrMediaTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrMediaTypeNotAllowed):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, services.ErrMediaEmpty), errors.Is(err, services.ErrNotImage):
+		return http.StatusBadRequest
+	}
+	return errorStatus(err, fallback)
+}
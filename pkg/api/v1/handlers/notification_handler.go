@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// NotificationHandler 站内通知处理器
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+// NewNotificationHandler 创建站内通知处理器实例
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// ListNotifications 获取当前用户的站内通知（unread=true 时仅返回未读）
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	notifications, total, err := h.notificationService.ListNotifications(c.Request.Context(), c.Query("unread") == "true", page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取通知列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	unread, err := h.notificationService.CountUnread(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取未读通知数失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		responses = append(responses, dto.ToNotificationResponse(&notifications[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+		"unread_count": unread,
+	})
+}
+
+// MarkNotificationRead 标记通知为已读
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	notificationID := c.Param("notificationId")
+	if !isValidUUID(notificationID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "通知ID格式不正确",
+		})
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), notificationID); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "标记通知已读失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "通知已标记为已读",
+	})
+}
+
+// MarkAllNotificationsRead 标记全部通知为已读
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	count, err := h.notificationService.MarkAllRead(c.Request.Context())
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "标记通知已读失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "全部通知已标记为已读",
+		"data": gin.H{
+			"updated": count,
+		},
+	})
+}
+
+// ListChannels 获取提醒可用的通知渠道
+func (h *NotificationHandler) ListChannels(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"data": h.notificationService.Channels(),
+	})
+}
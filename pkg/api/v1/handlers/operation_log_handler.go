@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// operationEntityTypes 家庭动态可过滤的对象类型
+var operationEntityTypes = map[string]bool{
+	services.EntityItem:     true,
+	services.EntityRoom:     true,
+	services.EntityHouse:    true,
+	services.EntityCategory: true,
+	services.EntityReminder: true,
+}
+
+// OperationLogHandler 操作日志处理器
+type OperationLogHandler struct {
+	operationLogService services.OperationLogService
+	permissionService   services.PermissionService
+}
+
+// NewOperationLogHandler 创建操作日志处理器实例
+func NewOperationLogHandler(operationLogService services.OperationLogService, permissionService services.PermissionService) *OperationLogHandler {
+	return &OperationLogHandler{
+		operationLogService: operationLogService,
+		permissionService:   permissionService,
+	}
+}
+
+// ListItemHistory 获取物品及其提醒的操作历史
+func (h *OperationLogHandler) ListItemHistory(c *gin.Context) {
+	itemID := c.Param("itemId")
+	if !isValidUUID(itemID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品ID格式不正确",
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	page, pageSize := pagination(c)
+	logs, total, err := h.operationLogService.ListItemHistory(c.Request.Context(), itemID, page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取物品历史失败: " + err.Error(),
+		})
+		return
+	}
+
+	respondOperationLogs(c, logs, total, page, pageSize)
+}
+
+// ListFamilyActivity 获取家庭动态，支持按 entity_type 和 user_id 过滤
+func (h *OperationLogHandler) ListFamilyActivity(c *gin.Context) {
+	var filters services.ActivityFilters
+
+	if entityType := c.Query("entity_type"); entityType != "" {
+		if !operationEntityTypes[entityType] {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "对象类型不正确: " + entityType,
+			})
+			return
+		}
+		filters.EntityType = &entityType
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		if !isValidUUID(userID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "用户ID格式不正确",
+			})
+			return
+		}
+		filters.UserID = &userID
+	}
+
+	filters.Page, filters.PageSize = pagination(c)
+	logs, total, err := h.operationLogService.ListFamilyActivity(c.Request.Context(), c.Param("familyId"), filters)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取家庭动态失败: " + err.Error(),
+		})
+		return
+	}
+
+	respondOperationLogs(c, logs, total, filters.Page, filters.PageSize)
+}
+
+// pagination 读取分页参数，默认第1页、每页20条
+func pagination(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// respondOperationLogs 输出分页的操作日志列表
+func respondOperationLogs(c *gin.Context, logs []models.OperationLog, total int64, page, pageSize int) {
+	responses := make([]dto.OperationLogResponse, 0, len(logs))
+	for i := range logs {
+		responses = append(responses, dto.ToOperationLogResponse(&logs[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
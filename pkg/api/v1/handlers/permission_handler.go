@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// PermissionHandler 物品授权处理器
+type PermissionHandler struct {
+	permissionService services.PermissionService
+}
+
+// NewPermissionHandler 创建物品授权处理器实例
+func NewPermissionHandler(permissionService services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{
+		permissionService: permissionService,
+	}
+}
+
+// GrantItemPermission 授予用户物品权限
+func (h *PermissionHandler) GrantItemPermission(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	var req dto.GrantItemPermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	permission, err := h.permissionService.GrantItemPermission(c.Request.Context(), itemID, req.UserID, req.PermissionLevel)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "授权失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "授权成功",
+		"data":    dto.ToItemPermissionResponse(permission),
+	})
+}
+
+// ListItemPermissions 获取物品的授权列表
+func (h *PermissionHandler) ListItemPermissions(c *gin.Context) {
+	itemID := c.Param("itemId")
+
+	permissions, err := h.permissionService.ListItemPermissions(c.Request.Context(), itemID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取授权列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ItemPermissionResponse, 0, len(permissions))
+	for i := range permissions {
+		responses = append(responses, dto.ToItemPermissionResponse(&permissions[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+	})
+}
+
+// RevokeItemPermission 撤销用户的物品授权
+func (h *PermissionHandler) RevokeItemPermission(c *gin.Context) {
+	itemID := c.Param("itemId")
+	userID := c.Param("userId")
+
+	if err := h.permissionService.RevokeItemPermission(c.Request.Context(), itemID, userID); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "撤销授权失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "授权已撤销",
+	})
+}
+
+// GetEffectivePermission 查看用户对物品的有效权限
+func (h *PermissionHandler) GetEffectivePermission(c *gin.Context) {
+	itemID := c.Param("itemId")
+	userID := c.Param("userId")
+
+	effective, err := h.permissionService.GetEffectivePermission(c.Request.Context(), itemID, userID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取有效权限失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": effective,
+	})
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// ReminderHandler 提醒处理器
+type ReminderHandler struct {
+	reminderService   services.ReminderService
+	permissionService services.PermissionService
+}
+
+// NewReminderHandler 创建提醒处理器实例
+func NewReminderHandler(reminderService services.ReminderService, permissionService services.PermissionService) *ReminderHandler {
+	return &ReminderHandler{
+		reminderService:   reminderService,
+		permissionService: permissionService,
+	}
+}
+
+// ListReminders 获取提醒列表，支持按状态、类型、物品、房屋和时间范围过滤
+func (h *ReminderHandler) ListReminders(c *gin.Context) {
+	h.listReminders(c, false)
+}
+
+// ListOverdueReminders 获取已到提醒时间但尚未完成或取消的提醒
+func (h *ReminderHandler) ListOverdueReminders(c *gin.Context) {
+	h.listReminders(c, true)
+}
+
+// GetReminder 获取提醒详情
+func (h *ReminderHandler) GetReminder(c *gin.Context) {
+	reminderID, ok := h.authorizeReminder(c, services.ActionView)
+	if !ok {
+		return
+	}
+
+	reminder, err := h.reminderService.GetReminder(c.Request.Context(), reminderID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取提醒失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToReminderResponse(reminder),
+	})
+}
+
+// UpdateReminder 修改提醒时间、内容、通知渠道或重复规则
+func (h *ReminderHandler) UpdateReminder(c *gin.Context) {
+	var req dto.UpdateReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	reminderID, ok := h.authorizeReminder(c, services.ActionEdit)
+	if !ok {
+		return
+	}
+
+	reminder, err := h.reminderService.UpdateReminder(c.Request.Context(), reminderID, services.ReminderUpdate{
+		TriggerTime:    req.TriggerTime,
+		Message:        req.Message,
+		NotifyChannels: req.NotifyChannels,
+		Recurrence:     req.Recurrence,
+	})
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "更新提醒失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "提醒更新成功",
+		"data":    dto.ToReminderResponse(reminder),
+	})
+}
+
+// CancelReminder 取消提醒
+func (h *ReminderHandler) CancelReminder(c *gin.Context) {
+	reminderID, ok := h.authorizeReminder(c, services.ActionEdit)
+	if !ok {
+		return
+	}
+
+	reminder, err := h.reminderService.CancelReminder(c.Request.Context(), reminderID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "取消提醒失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "提醒已取消",
+		"data":    dto.ToReminderResponse(reminder),
+	})
+}
+
+// CompleteReminder 完成提醒，重复提醒自动安排下一次
+func (h *ReminderHandler) CompleteReminder(c *gin.Context) {
+	var req dto.CompleteReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	reminderID, ok := h.authorizeReminder(c, services.ActionEdit)
+	if !ok {
+		return
+	}
+
+	reminder, err := h.reminderService.CompleteReminder(c.Request.Context(), reminderID, req.Note)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "完成提醒失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "提醒已完成",
+		"data":    dto.ToReminderResponse(reminder),
+	})
+}
+
+// SnoozeReminder 稍后提醒
+func (h *ReminderHandler) SnoozeReminder(c *gin.Context) {
+	var req dto.SnoozeReminderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+	if (req.Until == nil) == (req.Minutes == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "必须且只能指定until或minutes之一",
+		})
+		return
+	}
+
+	reminderID, ok := h.authorizeReminder(c, services.ActionEdit)
+	if !ok {
+		return
+	}
+
+	until := time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+	if req.Until != nil {
+		until = *req.Until
+	}
+
+	reminder, err := h.reminderService.SnoozeReminder(c.Request.Context(), reminderID, until)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "稍后提醒失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已推迟提醒",
+		"data":    dto.ToReminderResponse(reminder),
+	})
+}
+
+// ListCompletions 获取物品的提醒完成历史（保养记录）
+func (h *ReminderHandler) ListCompletions(c *gin.Context) {
+	itemID := c.Param("itemId")
+	if !isValidUUID(itemID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品ID格式不正确",
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), itemID, services.ActionView)) {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	completions, total, err := h.reminderService.ListCompletions(c.Request.Context(), itemID, page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取完成记录失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ReminderCompletionResponse, 0, len(completions))
+	for i := range completions {
+		responses = append(responses, dto.ToReminderCompletionResponse(&completions[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// listReminders 解析过滤条件并返回分页的提醒列表，overdue 为true时仅返回逾期提醒
+func (h *ReminderHandler) listReminders(c *gin.Context, overdue bool) {
+	filters := services.ReminderFilters{Overdue: overdue}
+
+	if status := c.Query("status"); status != "" {
+		// 多个状态以逗号分隔
+		for _, s := range strings.Split(status, ",") {
+			s = strings.TrimSpace(s)
+			if !slices.Contains(services.ReminderStatuses, s) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "提醒状态不正确: " + s,
+				})
+				return
+			}
+			filters.Statuses = append(filters.Statuses, s)
+		}
+	}
+
+	if reminderType := c.Query("type"); reminderType != "" {
+		filters.ReminderType = &reminderType
+	}
+
+	for param, target := range map[string]**string{"item_id": &filters.ItemID, "house_id": &filters.HouseID} {
+		if id := c.Query(param); id != "" {
+			if !isValidUUID(id) {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": param + "格式不正确",
+				})
+				return
+			}
+			*target = &id
+		}
+	}
+
+	for param, target := range map[string]**time.Time{"from": &filters.From, "to": &filters.To} {
+		if value := c.Query(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": param + "时间格式不正确，应为RFC3339",
+				})
+				return
+			}
+			*target = &t
+		}
+	}
+
+	filters.Page, _ = strconv.Atoi(c.Query("page"))
+	filters.PageSize, _ = strconv.Atoi(c.Query("page_size"))
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+	if filters.PageSize <= 0 {
+		filters.PageSize = 20
+	}
+
+	reminders, total, err := h.reminderService.ListReminders(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取提醒列表失败: " + err.Error(),
+		})
+		return
+	}
+
+	responses := make([]dto.ReminderResponse, 0, len(reminders))
+	for i := range reminders {
+		responses = append(responses, dto.ToReminderResponse(&reminders[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": responses,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      filters.Page,
+			"page_size": filters.PageSize,
+		},
+	})
+}
+
+// authorizeReminder 校验提醒存在且当前用户对其所属物品有指定权限，失败时写入错误响应
+func (h *ReminderHandler) authorizeReminder(c *gin.Context, action services.Action) (string, bool) {
+	reminderID := c.Param("reminderId")
+	if !isValidUUID(reminderID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "提醒ID格式不正确",
+		})
+		return "", false
+	}
+
+	reminder, err := h.reminderService.GetReminder(c.Request.Context(), reminderID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取提醒失败: " + err.Error(),
+		})
+		return "", false
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeItem(c.Request.Context(), reminder.ItemID, action)) {
+		return "", false
+	}
+	return reminderID, true
+}
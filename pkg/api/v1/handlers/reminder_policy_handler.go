@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// ReminderPolicyHandler 家庭提醒策略处理器
+type ReminderPolicyHandler struct {
+	policyService     services.ReminderPolicyService
+	permissionService services.PermissionService
+}
+
+// NewReminderPolicyHandler 创建提醒策略处理器实例
+func NewReminderPolicyHandler(policyService services.ReminderPolicyService, permissionService services.PermissionService) *ReminderPolicyHandler {
+	return &ReminderPolicyHandler{
+		policyService:     policyService,
+		permissionService: permissionService,
+	}
+}
+
+// ListPolicies 获取家庭提醒策略
+func (h *ReminderPolicyHandler) ListPolicies(c *gin.Context) {
+	policies, err := h.policyService.ListPolicies(c.Request.Context(), c.Param("familyId"))
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取提醒策略失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toReminderPolicyResponses(policies),
+	})
+}
+
+// ReplacePolicies 整体替换家庭提醒策略，并同步家庭内物品的自动提醒
+func (h *ReminderPolicyHandler) ReplacePolicies(c *gin.Context) {
+	familyID := c.Param("familyId")
+
+	var req dto.ReplaceReminderPoliciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	if !authorized(c, h.permissionService.AuthorizeFamily(c.Request.Context(), familyID, services.ActionManage)) {
+		return
+	}
+
+	policies := make([]models.ReminderPolicy, 0, len(req.Policies))
+	for _, policy := range req.Policies {
+		policies = append(policies, models.ReminderPolicy{
+			ReminderType:   policy.ReminderType,
+			DaysBefore:     policy.DaysBefore,
+			NotifyChannels: policy.NotifyChannels,
+		})
+	}
+
+	saved, err := h.policyService.ReplacePolicies(c.Request.Context(), familyID, policies)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusBadRequest), gin.H{
+			"error": "更新提醒策略失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "提醒策略已更新",
+		"data":    toReminderPolicyResponses(saved),
+	})
+}
+
+// toReminderPolicyResponses 批量转换提醒策略
+func toReminderPolicyResponses(policies []models.ReminderPolicy) []dto.ReminderPolicyResponse {
+	responses := make([]dto.ReminderPolicyResponse, 0, len(policies))
+	for i := range policies {
+		responses = append(responses, dto.ToReminderPolicyResponse(&policies[i]))
+	}
+	return responses
+}
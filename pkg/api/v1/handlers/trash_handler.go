@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// TrashHandler 回收站处理器
+type TrashHandler struct {
+	trashService services.TrashService
+}
+
+// NewTrashHandler 创建回收站处理器实例，恢复权限由回收站服务校验
+func NewTrashHandler(trashService services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// ListTrash 获取家庭回收站，可通过 family_id 指定家庭
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	familyID := c.Query("family_id")
+	if familyID != "" && !isValidUUID(familyID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "家庭ID格式不正确",
+		})
+		return
+	}
+
+	page, pageSize := pagination(c)
+	entries, total, err := h.trashService.ListTrash(c.Request.Context(), familyID, page, pageSize)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "获取回收站失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": entries,
+		"pagination": gin.H{
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// RestoreItem 从回收站恢复物品
+func (h *TrashHandler) RestoreItem(c *gin.Context) {
+	itemID := c.Param("itemId")
+	if !isValidUUID(itemID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "物品ID格式不正确",
+		})
+		return
+	}
+
+	item, err := h.trashService.RestoreItem(c.Request.Context(), itemID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "恢复物品失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "物品恢复成功",
+		"data":    dto.ToItemResponse(item),
+	})
+}
+
+// RestoreRoom 从回收站恢复房间
+func (h *TrashHandler) RestoreRoom(c *gin.Context) {
+	roomID := c.Param("roomId")
+	if !isValidUUID(roomID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "房间ID格式不正确",
+		})
+		return
+	}
+
+	room, err := h.trashService.RestoreRoom(c.Request.Context(), roomID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "恢复房间失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "房间恢复成功",
+		"data":    dto.ToHouseRoomResponse(room),
+	})
+}
+
+// RestoreHouse 从回收站恢复房屋
+func (h *TrashHandler) RestoreHouse(c *gin.Context) {
+	houseID := c.Param("houseId")
+	if !isValidUUID(houseID) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "房屋ID格式不正确",
+		})
+		return
+	}
+
+	house, err := h.trashService.RestoreHouse(c.Request.Context(), houseID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{
+			"error": "恢复房屋失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "房屋恢复成功",
+		"data":    dto.ToHouseResponse(house),
+	})
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+// UserHandler 用户处理器
+type UserHandler struct {
+	userService services.UserService
+}
+
+// NewUserHandler 创建用户处理器实例
+func NewUserHandler(userService services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// Register 用户注册
+func (h *UserHandler) Register(c *gin.Context) {
+	var req dto.RegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	user := &models.User{
+		Username: req.Username,
+		Email:    req.Email,
+		Nickname: req.Nickname,
+		Phone:    req.Phone,
+	}
+
+	if err := h.userService.Register(c.Request.Context(), user, req.Password); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrUserExists) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, gin.H{
+			"error": "注册失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "注册成功",
+		"data":    dto.ToUserResponse(user),
+	})
+}
+
+// Login 用户登录
+func (h *UserHandler) Login(c *gin.Context) {
+	var req dto.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	pair, user, err := h.userService.Login(c.Request.Context(), req.Account, req.Password, clientMeta(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidCredentials) || errors.Is(err, services.ErrUserDisabled) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"error": "登录失败: " + err.Error(),
+		})
+		return
+	}
+
+	userResp := dto.ToUserResponse(user)
+	resp := toTokenResponse(pair)
+	resp.User = &userResp
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "登录成功",
+		"data":    resp,
+	})
+}
+
+// RefreshToken 刷新访问令牌
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	pair, err := h.userService.RefreshToken(c.Request.Context(), req.RefreshToken, clientMeta(c))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrUserDisabled) {
+			status = http.StatusUnauthorized
+		}
+		c.JSON(status, gin.H{
+			"error": "刷新令牌失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": toTokenResponse(pair),
+	})
+}
+
+// Logout 退出登录（吊销刷新令牌）
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "请求参数验证失败: " + err.Error(),
+		})
+		return
+	}
+
+	if err := h.userService.Logout(c.Request.Context(), req.RefreshToken); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "退出登录失败: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已退出登录",
+	})
+}
+
+// GetProfile 获取当前用户信息
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "用户不存在: " + err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": dto.ToUserResponse(user),
+	})
+}
+
+// clientMeta 提取客户端信息
+func clientMeta(c *gin.Context) services.ClientMeta {
+	return services.ClientMeta{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+// toTokenResponse 转换令牌为响应格式
+func toTokenResponse(pair *services.TokenPair) dto.TokenResponse {
+	return dto.TokenResponse{
+		AccessToken:      pair.AccessToken,
+		AccessExpiresAt:  pair.AccessExpiresAt,
+		RefreshToken:     pair.RefreshToken,
+		RefreshExpiresAt: pair.RefreshExpiresAt,
+		TokenType:        pair.TokenType,
+	}
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/auth"
+	"nookverse/internal/models"
+	"nookverse/internal/routers"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestRefreshTokenRotationIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	userService := services.NewUserService(db, auth.NewTokenManager("test-secret", time.Hour), 24*time.Hour)
+	ctx := context.Background()
+
+	user := &models.User{Username: "refresh_tester", Email: "refresh_tester@nookverse.com"}
+	require.NoError(t, userService.Register(ctx, user, "secret123"))
+	meta := services.ClientMeta{UserAgent: "test-agent"}
+
+	login, _, err := userService.Login(ctx, "refresh_tester", "secret123", meta)
+	require.NoError(t, err)
+
+	activeTokens := func() int64 {
+		var count int64
+		db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).Count(&count)
+		return count
+	}
+
+	t.Run("轮换后旧令牌失效，新令牌可用", func(t *testing.T) {
+		rotated, err := userService.RefreshToken(ctx, login.RefreshToken, meta)
+		require.NoError(t, err)
+		assert.NotEqual(t, login.RefreshToken, rotated.RefreshToken)
+		assert.NotEmpty(t, rotated.AccessToken)
+
+		var old models.RefreshToken
+		require.NoError(t, db.Where("user_id = ? AND replaced_by IS NOT NULL", user.ID).First(&old).Error)
+		assert.NotNil(t, old.RevokedAt)
+		assert.EqualValues(t, 1, activeTokens())
+
+		_, err = userService.RefreshToken(ctx, rotated.RefreshToken, meta)
+		require.NoError(t, err)
+	})
+
+	t.Run("重复使用已轮换的令牌时吊销该用户全部令牌", func(t *testing.T) {
+		// 另一台设备的登录也一并吊销
+		other, _, err := userService.Login(ctx, "refresh_tester", "secret123", meta)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, activeTokens())
+
+		_, err = userService.RefreshToken(ctx, login.RefreshToken, meta)
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+		assert.Zero(t, activeTokens())
+
+		_, err = userService.RefreshToken(ctx, other.RefreshToken, meta)
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+	})
+
+	t.Run("未知或过期的令牌无效", func(t *testing.T) {
+		_, err := userService.RefreshToken(ctx, "unknown-token", meta)
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+
+		fresh, _, err := userService.Login(ctx, "refresh_tester", "secret123", meta)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("expires_at", time.Now().Add(-time.Minute)).Error)
+		_, err = userService.RefreshToken(ctx, fresh.RefreshToken, meta)
+		assert.ErrorIs(t, err, services.ErrInvalidRefreshToken)
+	})
+}
+
+func TestAuthMiddlewareIntegration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := testutils.OpenTestDB(t)
+	userService := services.NewUserService(db, auth.NewTokenManager("test-secret", time.Hour), 24*time.Hour)
+	router := routers.SetupRoutes(routers.Services{User: userService})
+
+	user := &models.User{Username: "middleware_tester", Email: "middleware_tester@nookverse.com"}
+	require.NoError(t, userService.Register(context.Background(), user, "secret123"))
+	pair, _, err := userService.Login(context.Background(), "middleware_tester", "secret123", services.ClientMeta{})
+	require.NoError(t, err)
+
+	profile := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, profile().Code)
+
+	// 用户被禁用后，未过期的访问令牌也不再有效
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("status", 0).Error)
+	w := profile()
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "User disabled")
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/auth"
+	"nookverse/internal/routers"
+	"nookverse/internal/services"
+)
+
+func TestTokenManager(t *testing.T) {
+	t.Run("签发并解析访问令牌", func(t *testing.T) {
+		manager := auth.NewTokenManager("test-secret", time.Hour)
+
+		token, expiresAt, err := manager.GenerateAccessToken("550e8400-e29b-41d4-a716-446655440000", "tester")
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, 5*time.Second)
+
+		claims, err := manager.ParseAccessToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "550e8400-e29b-41d4-a716-446655440000", claims.UserID)
+		assert.Equal(t, "tester", claims.Username)
+	})
+
+	t.Run("密钥不匹配", func(t *testing.T) {
+		token, _, err := auth.NewTokenManager("secret-a", time.Hour).GenerateAccessToken("user-1", "tester")
+		require.NoError(t, err)
+
+		_, err = auth.NewTokenManager("secret-b", time.Hour).ParseAccessToken(token)
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+
+	t.Run("令牌已过期", func(t *testing.T) {
+		manager := auth.NewTokenManager("test-secret", -time.Minute)
+
+		token, _, err := manager.GenerateAccessToken("user-1", "tester")
+		require.NoError(t, err)
+
+		_, err = manager.ParseAccessToken(token)
+		assert.ErrorIs(t, err, auth.ErrExpiredToken)
+	})
+
+	t.Run("格式错误的令牌", func(t *testing.T) {
+		_, err := auth.NewTokenManager("test-secret", time.Hour).ParseAccessToken("not-a-jwt")
+		assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	})
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	manager := auth.NewTokenManager("test-secret", time.Hour)
+	// 令牌在查询数据库前即被拒绝，不需要数据库
+	router := routers.SetupRoutes(routers.Services{User: services.NewUserService(nil, manager, time.Hour)})
+
+	expired, _, err := auth.NewTokenManager("test-secret", -time.Minute).GenerateAccessToken("user-1", "tester")
+	require.NoError(t, err)
+	forged, _, err := auth.NewTokenManager("other-secret", time.Hour).GenerateAccessToken("user-1", "tester")
+	require.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		authorization string
+		message       string
+	}{
+		{"缺少令牌", "", "Authorization header required"},
+		{"不是Bearer令牌", "Basic dGVzdGVyOnNlY3JldA==", "Authorization header format must be Bearer {token}"},
+		{"令牌已过期", "Bearer " + expired, "Token expired"},
+		{"签名不匹配", "Bearer " + forged, "Invalid token"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/profile", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			var response map[string]string
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tc.message, response["error"])
+		})
+	}
+}
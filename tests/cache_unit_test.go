@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/cache"
+	"nookverse/tests/testutils"
+)
+
+type cachedStats struct {
+	Total  int64            `json:"total"`
+	ByType map[string]int64 `json:"by_type"`
+}
+
+// countingLoader 记录读取数据源次数的加载函数
+func countingLoader(calls *atomic.Int64, delay time.Duration) func() (*cachedStats, error) {
+	return func() (*cachedStats, error) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		return &cachedStats{Total: n, ByType: map[string]int64{"kitchen": n}}, nil
+	}
+}
+
+func TestCacheWithoutRedis(t *testing.T) {
+	c := cache.New(nil, time.Minute)
+	assert.False(t, c.Enabled())
+
+	var calls atomic.Int64
+	for i := 0; i < 3; i++ {
+		stats, err := cache.Fetch(context.Background(), c, "stats", []string{"family:a"}, countingLoader(&calls, 0))
+		require.NoError(t, err)
+		assert.EqualValues(t, i+1, stats.Total)
+	}
+	c.Invalidate(context.Background(), "family:a")
+
+	// ttl 为0时即使有 Redis 客户端也不使用缓存
+	assert.False(t, cache.New(redis.NewClient(&redis.Options{}), 0).Enabled())
+}
+
+func TestCacheRedisDown(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 50 * time.Millisecond, MaxRetries: -1})
+	defer client.Close()
+	c := cache.New(client, time.Minute)
+	require.True(t, c.Enabled())
+
+	var calls atomic.Int64
+	stats, err := cache.Fetch(context.Background(), c, "stats", []string{"family:a"}, countingLoader(&calls, 0))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stats.Total)
+
+	// 出错后暂停使用缓存，后续读取不再等待 Redis
+	assert.False(t, c.Enabled())
+	start := time.Now()
+	_, err = cache.Fetch(context.Background(), c, "stats", []string{"family:a"}, countingLoader(&calls, 0))
+	require.NoError(t, err)
+	c.Invalidate(context.Background(), "family:a")
+	assert.Less(t, time.Since(start), 10*time.Millisecond)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+func TestCacheInvalidateByTag(t *testing.T) {
+	client := testutils.OpenTestRedis(t)
+	c := cache.New(client, time.Minute)
+	ctx := context.Background()
+	suffix := strconv.FormatInt(time.Now().UnixNano(), 10)
+	home, office := "family:home-"+suffix, "family:office-"+suffix
+
+	var calls atomic.Int64
+	fetch := func(key string, tags ...string) *cachedStats {
+		stats, err := cache.Fetch(ctx, c, key, tags, countingLoader(&calls, 0))
+		require.NoError(t, err)
+		return stats
+	}
+
+	assert.EqualValues(t, 1, fetch("stats", home, office).Total)
+	assert.EqualValues(t, 1, fetch("stats", office, home).Total, "标签顺序不影响缓存键")
+	assert.Equal(t, map[string]int64{"kitchen": 1}, fetch("stats", home, office).ByType)
+	assert.EqualValues(t, 2, fetch("rooms", home).Total, "不同的键分别缓存")
+
+	// 任一标签失效后重新读取，不依赖该标签的缓存不受影响
+	c.Invalidate(ctx, office)
+	assert.EqualValues(t, 3, fetch("stats", home, office).Total)
+	assert.EqualValues(t, 2, fetch("rooms", home).Total)
+}
+
+func TestCacheStampede(t *testing.T) {
+	client := testutils.OpenTestRedis(t)
+	// 两个实例共用同一 Redis
+	instances := []*cache.Cache{cache.New(client, time.Minute), cache.New(client, time.Minute)}
+	tag := "family:stampede-" + strconv.FormatInt(time.Now().UnixNano(), 10)
+
+	// 缓存未命中时各实例的并发读取只访问一次数据源，每个调用方得到各自的结果副本
+	var calls atomic.Int64
+	results := make([]*cachedStats, 20)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stats, err := cache.Fetch(context.Background(), instances[i%2], "stats", []string{tag}, countingLoader(&calls, 50*time.Millisecond))
+			assert.NoError(t, err)
+			results[i] = stats
+		}(i)
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 1, calls.Load())
+	for _, stats := range results[1:] {
+		require.NotNil(t, stats)
+		assert.Equal(t, results[0].Total, stats.Total)
+		assert.NotSame(t, results[0], stats)
+	}
+}
@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestRenderFeedSkipsExpiredWarrantiesIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "calendar_owner")
+
+	// 大量早已过保的物品不能占满事件上限，挤掉即将到期的保修
+	oldPurchase := time.Now().AddDate(-10, 0, 0)
+	oneYear := 12
+	items := make([]models.Item, 0, 2001)
+	for i := 0; i < 2000; i++ {
+		items = append(items, models.Item{
+			Name:           fmt.Sprintf("old warranty %d", i),
+			RoomID:         &fixture.RoomID,
+			PurchaseDate:   &oldPurchase,
+			WarrantyPeriod: &oneYear,
+		})
+	}
+	recentPurchase := time.Now().AddDate(0, -6, 0)
+	items = append(items, models.Item{Name: "new laptop", RoomID: &fixture.RoomID, PurchaseDate: &recentPurchase, WarrantyPeriod: &oneYear})
+	require.NoError(t, db.CreateInBatches(items, 500).Error)
+	upcoming := items[len(items)-1]
+
+	calendarService := services.NewCalendarService(db)
+	token, _, err := calendarService.ResetFeedToken(fixture.Ctx)
+	require.NoError(t, err)
+
+	calendar, err := calendarService.RenderFeed(fixture.Ctx, token)
+	require.NoError(t, err)
+
+	uids := make([]string, 0, len(calendar.Events))
+	for _, event := range calendar.Events {
+		uids = append(uids, event.UID)
+	}
+	assert.Equal(t, []string{fmt.Sprintf("item-%s-warranty@nookverse", upcoming.ID)}, uids)
+}
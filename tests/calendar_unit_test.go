@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/ical"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+func renderCalendar(t *testing.T, calendar *ical.Calendar) string {
+	var buf bytes.Buffer
+	require.NoError(t, calendar.Write(&buf))
+	return buf.String()
+}
+
+func TestCalendarWrite(t *testing.T) {
+	modified := time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("内容行以CRLF结尾并转义特殊字符", func(t *testing.T) {
+		out := renderCalendar(t, &ical.Calendar{
+			ProdID: "-//Test//EN",
+			Events: []ical.Event{{
+				UID:          "a@test",
+				Summary:      "牛奶, 鸡蛋; 面包",
+				Description:  "第一行\n第二行",
+				Start:        time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+				Duration:     15 * time.Minute,
+				LastModified: modified,
+			}},
+		})
+
+		assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+		assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+		assert.NotContains(t, strings.ReplaceAll(out, "\r\n", ""), "\n")
+		assert.Contains(t, out, `SUMMARY:牛奶\, 鸡蛋\; 面包`+"\r\n")
+		assert.Contains(t, out, `DESCRIPTION:第一行\n第二行`+"\r\n")
+		assert.Contains(t, out, "DTSTART:20260501T090000Z\r\n")
+		assert.Contains(t, out, "DURATION:PT15M\r\n")
+		assert.Contains(t, out, "DTSTAMP:20260401T080000Z\r\n")
+	})
+
+	t.Run("长行按75字节折行且不拆分汉字", func(t *testing.T) {
+		out := renderCalendar(t, &ical.Calendar{
+			ProdID: "-//Test//EN",
+			Events: []ical.Event{{UID: "b@test", Summary: strings.Repeat("保修到期", 20), LastModified: modified}},
+		})
+
+		for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), 75)
+			assert.True(t, strings.ToValidUTF8(line, "?") == line, line)
+		}
+		unfolded := strings.ReplaceAll(out, "\r\n ", "")
+		assert.Contains(t, unfolded, "SUMMARY:"+strings.Repeat("保修到期", 20)+"\r\n")
+	})
+
+	t.Run("全天事件使用日期值", func(t *testing.T) {
+		out := renderCalendar(t, &ical.Calendar{
+			ProdID: "-//Test//EN",
+			Events: []ical.Event{{UID: "c@test", Summary: "牛奶过期", Start: time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), AllDay: true, LastModified: modified}},
+		})
+
+		assert.Contains(t, out, "DTSTART;VALUE=DATE:20261231\r\n")
+		assert.Contains(t, out, "DTEND;VALUE=DATE:20270101\r\n")
+	})
+}
+
+func TestBuildCalendar(t *testing.T) {
+	user := &models.User{Username: "alice"}
+	since := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	expire := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	purchase := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	expired := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	warranty := 24
+	start := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	rule := "FREQ=MONTHLY;INTERVAL=3"
+
+	reminders := []models.Reminder{
+		{ID: "r1", ItemID: "i1", ReminderType: services.ReminderTypeExpire, TriggerTime: time.Date(2026, 5, 7, 9, 0, 0, 0, time.UTC), Message: "牛奶将于2026-05-10过期", Item: &models.Item{Name: "牛奶"}},
+		{ID: "r2", ItemID: "i2", ReminderType: services.ReminderTypeMaintenance, TriggerTime: time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC), Recurrence: &rule, RecurrenceStart: &start, Item: &models.Item{Name: "净水器"}},
+	}
+	items := []models.Item{
+		{ID: "i1", Name: "牛奶", ExpireDate: &expire},
+		{ID: "i3", Name: "洗碗机", PurchaseDate: &purchase, WarrantyPeriod: &warranty},
+		{ID: "i4", Name: "过期酸奶", ExpireDate: &expired},
+	}
+
+	calendar := services.BuildCalendar(user, reminders, items, since)
+
+	uids := make(map[string]ical.Event)
+	for _, event := range calendar.Events {
+		uids[event.UID] = event
+	}
+
+	t.Run("事件UID由提醒和物品ID确定", func(t *testing.T) {
+		assert.Len(t, calendar.Events, 4)
+		assert.Contains(t, uids, "reminder-r1@nookverse")
+		assert.Contains(t, uids, "reminder-r2@nookverse")
+		assert.Contains(t, uids, "item-i1-expire@nookverse")
+		assert.Contains(t, uids, "item-i3-warranty@nookverse")
+		assert.Equal(t, "过期提醒：牛奶", uids["reminder-r1@nookverse"].Summary)
+	})
+
+	t.Run("重复提醒从规则起点开始并附带RRULE", func(t *testing.T) {
+		event := uids["reminder-r2@nookverse"]
+		assert.Equal(t, start, event.Start)
+		assert.Equal(t, rule, event.RRule)
+	})
+
+	t.Run("保修到期为购买日期加保修月数", func(t *testing.T) {
+		event := uids["item-i3-warranty@nookverse"]
+		assert.True(t, event.AllDay)
+		assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), event.Start)
+		assert.Equal(t, "洗碗机保修到期", event.Summary)
+	})
+
+	t.Run("早于保留范围的日期不再列出", func(t *testing.T) {
+		assert.NotContains(t, uids, "item-i4-expire@nookverse")
+	})
+}
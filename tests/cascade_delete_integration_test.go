@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/clause"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestCascadeDeleteHouseIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "cascade_owner")
+
+	itemService := services.NewItemService(db, nil)
+	houseService := services.NewHouseService(db)
+	trashService := services.NewTrashService(db, nil, 0)
+
+	// 待清空的储物间：一个房间，箱子里有一件物品，物品带一条提醒
+	unit := &models.House{Name: "储物间"}
+	require.NoError(t, houseService.CreateHouse(fixture.Ctx, unit, fixture.FamilyID))
+	shelf := &models.Room{HouseID: unit.ID, Name: "货架区", RoomType: "storage"}
+	require.NoError(t, houseService.CreateRoom(fixture.Ctx, shelf))
+	box := &models.Item{Name: "纸箱", RoomID: &shelf.ID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, box))
+	tent := &models.Item{Name: "帐篷", RoomID: &shelf.ID, ContainerID: &box.ID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, tent))
+	require.NoError(t, itemService.CreateReminder(fixture.Ctx, &models.Reminder{
+		ItemID: tent.ID, ReminderType: "custom", TriggerTime: time.Now().Add(24 * time.Hour), Message: "晾晒帐篷",
+	}))
+
+	t.Run("未指定级联时拒绝删除", func(t *testing.T) {
+		err := houseService.DeleteHouse(fixture.Ctx, unit.ID)
+		assert.ErrorIs(t, err, services.ErrNotEmpty)
+	})
+
+	t.Run("预览返回将被删除的内容且不做修改", func(t *testing.T) {
+		summary, err := houseService.DeleteHouseWithOptions(fixture.Ctx, unit.ID, services.DeleteOptions{Cascade: true, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, services.DeleteSummary{DryRun: true, Rooms: 1, Items: 2, Reminders: 1}, *summary)
+
+		_, err = houseService.GetHouseByID(fixture.Ctx, unit.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("目标位置在删除范围内时拒绝移动", func(t *testing.T) {
+		_, err := houseService.DeleteHouseWithOptions(fixture.Ctx, unit.ID, services.DeleteOptions{
+			MoveTo: &services.MoveTarget{ContainerID: &box.ID},
+		})
+		assert.ErrorIs(t, err, services.ErrMoveTargetInScope)
+	})
+
+	t.Run("级联删除并一并恢复", func(t *testing.T) {
+		summary, err := houseService.DeleteHouseWithOptions(fixture.Ctx, unit.ID, services.DeleteOptions{Cascade: true})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, summary.Items)
+
+		_, total, err := itemService.ListItems(fixture.Ctx, services.ItemFilters{})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+
+		// 恢复房屋时同一次删除的房间和物品一并恢复，容器层级重建
+		_, err = trashService.RestoreHouse(fixture.Ctx, unit.ID)
+		require.NoError(t, err)
+		ancestors, err := itemService.GetItemHierarchy(fixture.Ctx, tent.ID)
+		require.NoError(t, err)
+		require.Len(t, ancestors, 1)
+		assert.Equal(t, box.ID, ancestors[0].ID)
+	})
+
+	t.Run("先移走物品再删除", func(t *testing.T) {
+		summary, err := houseService.DeleteHouseWithOptions(fixture.Ctx, unit.ID, services.DeleteOptions{
+			MoveTo: &services.MoveTarget{RoomID: &fixture.RoomID},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, services.DeleteSummary{Rooms: 1, Moved: 1}, *summary)
+
+		// 箱子连同其中的帐篷移到目标房间
+		moved, err := itemService.GetItemByID(fixture.Ctx, tent.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.RoomID, *moved.RoomID)
+		assert.Equal(t, box.ID, *moved.ContainerID)
+	})
+}
+
+func TestCascadeDeleteForbiddenContentsIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "cascade_admin")
+	memberCtx, memberID := testutils.AddMember(t, db, fixture.FamilyID, "cascade_member", services.RoleMember)
+
+	itemService := services.NewItemService(db, nil)
+	houseService := services.NewHouseService(db)
+
+	lamp := &models.Item{Name: "台灯", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, lamp))
+	diary := &models.Item{Name: "日记本", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(memberCtx, diary))
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.ItemPermission{
+		ItemID: diary.ID, UserID: memberID, PermissionLevel: services.PermissionOwner,
+	}).Error)
+
+	// 房间里有看不到的私有物品时整个删除失败，已经可见的物品也不受影响
+	_, err := houseService.DeleteRoomWithOptions(fixture.Ctx, fixture.RoomID, services.DeleteOptions{Cascade: true})
+	assert.ErrorIs(t, err, services.ErrForbidden)
+
+	_, err = itemService.GetItemByID(fixture.Ctx, lamp.ID)
+	assert.NoError(t, err)
+}
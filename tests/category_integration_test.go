@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/clause"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestCategoryScopeIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	familyA := testutils.SeedFamily(t, db, "category_owner_a")
+	familyB := testutils.SeedFamily(t, db, "category_owner_b")
+	outsider := testutils.SeedFamily(t, db, "category_outsider")
+
+	// B 同时是 A 家庭的成员，能看到 A 创建的分类并用于自己家庭的物品
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.FamilyMember{
+		FamilyID: familyA.FamilyID, UserID: familyB.UserID, Role: services.RoleMember, Status: 1,
+	}).Error)
+	viewerCtx, _ := testutils.AddMember(t, db, familyA.FamilyID, "category_viewer", services.RoleViewer)
+
+	categoryService := services.NewCategoryService(db)
+	itemService := services.NewItemService(db, nil)
+	permissionService := services.NewPermissionService(db)
+
+	system := &models.Category{Name: "系统分类", IsSystem: true}
+	require.NoError(t, db.Omit(clause.Associations).Create(system).Error)
+
+	custom := &models.Category{Name: "A的分类"}
+	require.NoError(t, categoryService.CreateCategory(familyA.Ctx, custom))
+
+	t.Run("自定义分类只对同一家庭的成员可见", func(t *testing.T) {
+		for _, tc := range []struct {
+			name    string
+			fixture testutils.Fixture
+			visible bool
+		}{
+			{"创建者", familyA, true},
+			{"同一家庭成员", familyB, true},
+			{"无关家庭", outsider, false},
+		} {
+			categories, err := categoryService.ListCategories(tc.fixture.Ctx)
+			require.NoError(t, err)
+			ids := make([]string, 0, len(categories))
+			for _, category := range categories {
+				ids = append(ids, category.ID)
+			}
+			assert.Contains(t, ids, system.ID, tc.name)
+			if tc.visible {
+				assert.Contains(t, ids, custom.ID, tc.name)
+			} else {
+				assert.NotContains(t, ids, custom.ID, tc.name)
+			}
+		}
+
+		_, err := categoryService.GetCategoryByID(outsider.Ctx, custom.ID)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+		assert.Error(t, itemService.CreateItem(outsider.Ctx, &models.Item{Name: "越权", RoomID: &outsider.RoomID, CategoryID: &custom.ID}))
+	})
+
+	t.Run("系统分类只读", func(t *testing.T) {
+		err := categoryService.ReorderCategories(familyA.Ctx, []services.CategoryOrder{{ID: system.ID, SortOrder: 99}})
+		assert.ErrorIs(t, err, services.ErrSystemCategory)
+
+		parent := custom.ID
+		err = categoryService.UpdateCategory(familyA.Ctx, &models.Category{ID: system.ID, Name: system.Name, ParentID: &parent})
+		assert.ErrorIs(t, err, services.ErrSystemCategory)
+
+		assert.ErrorIs(t, permissionService.AuthorizeCategory(familyA.Ctx, system.ID, services.ActionEdit), services.ErrSystemCategory)
+		assert.NoError(t, permissionService.AuthorizeCategory(familyA.Ctx, system.ID, services.ActionView))
+	})
+
+	t.Run("viewer 不能创建或修改分类", func(t *testing.T) {
+		assert.ErrorIs(t, permissionService.AuthorizeCategory(viewerCtx, "", services.ActionCreate), services.ErrForbidden)
+		assert.NoError(t, permissionService.AuthorizeCategory(viewerCtx, custom.ID, services.ActionView))
+		assert.NoError(t, permissionService.AuthorizeCategory(familyA.Ctx, "", services.ActionCreate))
+		assert.ErrorIs(t, permissionService.AuthorizeCategory(familyB.Ctx, custom.ID, services.ActionEdit), services.ErrForbidden)
+	})
+
+	t.Run("分类被其他家庭的物品使用时不能删除或合并", func(t *testing.T) {
+		own := &models.Item{Name: "A的物品", RoomID: &familyA.RoomID, CategoryID: &custom.ID}
+		require.NoError(t, itemService.CreateItem(familyA.Ctx, own))
+		foreign := &models.Item{Name: "B的物品", RoomID: &familyB.RoomID, CategoryID: &custom.ID}
+		require.NoError(t, itemService.CreateItem(familyB.Ctx, foreign))
+
+		target := &models.Category{Name: "A的目标分类"}
+		require.NoError(t, categoryService.CreateCategory(familyA.Ctx, target))
+
+		assert.ErrorIs(t, categoryService.DeleteCategory(familyA.Ctx, custom.ID), services.ErrCategoryInUse)
+		_, err := categoryService.MergeCategory(familyA.Ctx, custom.ID, target.ID)
+		assert.ErrorIs(t, err, services.ErrCategoryInUse)
+
+		var reloaded models.Item
+		require.NoError(t, db.First(&reloaded, "id = ?", foreign.ID).Error)
+		assert.Equal(t, custom.ID, *reloaded.CategoryID)
+
+		// 其他家庭不再使用后，只修改当前用户可访问的物品
+		require.NoError(t, db.Model(&models.Item{}).Where("id = ?", foreign.ID).Update("category_id", nil).Error)
+		moved, err := categoryService.MergeCategory(familyA.Ctx, custom.ID, target.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), moved)
+
+		require.NoError(t, db.First(&reloaded, "id = ?", own.ID).Error)
+		assert.Equal(t, target.ID, *reloaded.CategoryID)
+	})
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestBuildCategoryTree(t *testing.T) {
+	categories := []models.Category{
+		{ID: "electronics", Name: "电子设备"},
+		{ID: "chargers", Name: "充电器", ParentID: testutils.StringPtr("electronics")},
+		{ID: "usb-c", Name: "USB-C", ParentID: testutils.StringPtr("chargers")},
+		{ID: "books", Name: "书籍"},
+		{ID: "orphan", Name: "父分类已删除", ParentID: testutils.StringPtr("missing")},
+	}
+
+	t.Run("组装嵌套分类", func(t *testing.T) {
+		tree := services.BuildCategoryTree(categories)
+
+		assert.Len(t, tree, 3)
+		assert.Equal(t, "electronics", tree[0].ID)
+		assert.Equal(t, "usb-c", tree[0].Children[0].Children[0].ID)
+	})
+
+	t.Run("父分类不存在时作为根节点", func(t *testing.T) {
+		tree := services.BuildCategoryTree(categories)
+		assert.Equal(t, "orphan", tree[2].ID)
+	})
+}
+
+func TestListCategoriesScope(t *testing.T) {
+	db, recorder := testutils.DryRunDB()
+	categoryService := services.NewCategoryService(db)
+
+	t.Run("未登录", func(t *testing.T) {
+		_, err := categoryService.ListCategories(context.Background())
+		assert.ErrorIs(t, err, services.ErrUnauthenticated)
+	})
+
+	t.Run("只返回系统分类和同一家庭成员创建的分类", func(t *testing.T) {
+		_, err := categoryService.ListCategories(services.WithUserID(context.Background(), "user-1"))
+		assert.NoError(t, err)
+		assert.NotEmpty(t, recorder.Find(`FROM "categories"`, "categories.is_system = TRUE", "categories.created_by = 'user-1'", "fm.user_id = 'user-1'"))
+	})
+}
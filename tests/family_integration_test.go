@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/handlers"
+	"nookverse/tests/testutils"
+)
+
+// memberOf 用户在家庭中的成员记录
+func memberOf(t *testing.T, db *gorm.DB, familyID, userID string) models.FamilyMember {
+	t.Helper()
+
+	var member models.FamilyMember
+	require.NoError(t, db.Where("family_id = ? AND user_id = ?", familyID, userID).First(&member).Error)
+	return member
+}
+
+func TestFamilyJoinIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "join_owner")
+	familyService := services.NewFamilyService(db)
+
+	family, err := familyService.RotateInviteCode(fixture.Ctx, fixture.FamilyID, services.RoleViewer, time.Hour)
+	require.NoError(t, err)
+	code := *family.InviteCode
+
+	other := testutils.SeedFamily(t, db, "join_applicant")
+	applicant := other.Ctx
+
+	t.Run("申请加入后待审核，审核前无法访问家庭", func(t *testing.T) {
+		member, err := familyService.JoinFamily(applicant, code)
+		require.NoError(t, err)
+		assert.Equal(t, services.MemberStatusPending, member.Status)
+		assert.Equal(t, services.RoleViewer, member.Role)
+
+		_, err = familyService.GetFamilyByID(applicant, fixture.FamilyID)
+		assert.ErrorIs(t, err, services.ErrFamilyNotFound)
+
+		_, err = familyService.JoinFamily(applicant, code)
+		assert.ErrorIs(t, err, services.ErrAlreadyMember)
+	})
+
+	t.Run("拒绝后可以重新申请", func(t *testing.T) {
+		require.NoError(t, familyService.RejectMember(fixture.Ctx, fixture.FamilyID, other.UserID))
+
+		var count int64
+		db.Model(&models.FamilyMember{}).Where("family_id = ? AND user_id = ?", fixture.FamilyID, other.UserID).Count(&count)
+		assert.Zero(t, count)
+
+		_, err := familyService.JoinFamily(applicant, code)
+		require.NoError(t, err)
+	})
+
+	t.Run("通过后以邀请码的角色成为正常成员", func(t *testing.T) {
+		member, err := familyService.ApproveMember(fixture.Ctx, fixture.FamilyID, other.UserID)
+		require.NoError(t, err)
+		assert.Equal(t, services.MemberStatusActive, member.Status)
+
+		stored := memberOf(t, db, fixture.FamilyID, other.UserID)
+		assert.Equal(t, services.MemberStatusActive, stored.Status)
+		assert.Equal(t, services.RoleViewer, stored.Role)
+
+		_, err = familyService.GetFamilyByID(applicant, fixture.FamilyID)
+		assert.NoError(t, err)
+
+		// 已通过的成员不能再次审核
+		_, err = familyService.ApproveMember(fixture.Ctx, fixture.FamilyID, other.UserID)
+		assert.ErrorIs(t, err, services.ErrMemberNotFound)
+	})
+
+	t.Run("轮换后旧邀请码失效，过期的邀请码无效", func(t *testing.T) {
+		_, err := familyService.RotateInviteCode(fixture.Ctx, fixture.FamilyID, services.RoleMember, time.Hour)
+		require.NoError(t, err)
+		latecomerCtx, _ := testutils.AddMember(t, db, other.FamilyID, "join_latecomer", services.RoleMember)
+		_, err = familyService.JoinFamily(latecomerCtx, code)
+		assert.ErrorIs(t, err, services.ErrInvalidInviteCode)
+
+		require.NoError(t, db.Model(&models.Family{}).Where("id = ?", fixture.FamilyID).
+			Update("invite_expires_at", time.Now().Add(-time.Minute)).Error)
+		var expired models.Family
+		require.NoError(t, db.First(&expired, "id = ?", fixture.FamilyID).Error)
+		_, err = familyService.JoinFamily(latecomerCtx, *expired.InviteCode)
+		assert.ErrorIs(t, err, services.ErrInvalidInviteCode)
+	})
+}
+
+func TestFamilyTransferOwnershipIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "transfer_owner")
+	adminCtx, adminID := testutils.AddMember(t, db, fixture.FamilyID, "transfer_admin", services.RoleAdmin)
+	_, pendingID := testutils.AddMember(t, db, fixture.FamilyID, "transfer_pending", services.RoleMember)
+	require.NoError(t, db.Model(&models.FamilyMember{}).Where("family_id = ? AND user_id = ?", fixture.FamilyID, pendingID).
+		Update("status", services.MemberStatusPending).Error)
+	familyService := services.NewFamilyService(db)
+
+	t.Run("只有所有者可以转让", func(t *testing.T) {
+		_, err := familyService.TransferOwnership(adminCtx, fixture.FamilyID, adminID)
+		assert.ErrorIs(t, err, services.ErrForbidden)
+	})
+
+	t.Run("不能转让给待审核的成员，失败时不做任何修改", func(t *testing.T) {
+		_, err := familyService.TransferOwnership(fixture.Ctx, fixture.FamilyID, pendingID)
+		assert.ErrorIs(t, err, services.ErrMemberNotFound)
+
+		var family models.Family
+		require.NoError(t, db.First(&family, "id = ?", fixture.FamilyID).Error)
+		assert.Equal(t, fixture.UserID, family.OwnerID)
+		assert.Equal(t, services.RoleOwner, memberOf(t, db, fixture.FamilyID, fixture.UserID).Role)
+		assert.Equal(t, services.RoleMember, memberOf(t, db, fixture.FamilyID, pendingID).Role)
+	})
+
+	t.Run("转让后新旧所有者的角色一并更新", func(t *testing.T) {
+		family, err := familyService.TransferOwnership(fixture.Ctx, fixture.FamilyID, adminID)
+		require.NoError(t, err)
+		assert.Equal(t, adminID, family.OwnerID)
+
+		var stored models.Family
+		require.NoError(t, db.First(&stored, "id = ?", fixture.FamilyID).Error)
+		assert.Equal(t, adminID, stored.OwnerID)
+		assert.Equal(t, services.RoleOwner, memberOf(t, db, fixture.FamilyID, adminID).Role)
+		assert.Equal(t, services.RoleAdmin, memberOf(t, db, fixture.FamilyID, fixture.UserID).Role)
+
+		// 原所有者不能再次转让
+		_, err = familyService.TransferOwnership(fixture.Ctx, fixture.FamilyID, fixture.UserID)
+		assert.ErrorIs(t, err, services.ErrForbidden)
+	})
+}
+
+func TestFamilyInviteCodeVisibilityIntegration(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "invite_owner")
+	_, viewerID := testutils.AddMember(t, db, fixture.FamilyID, "invite_viewer", services.RoleViewer)
+	familyService := services.NewFamilyService(db)
+
+	_, err := familyService.RotateInviteCode(fixture.Ctx, fixture.FamilyID, services.RoleAdmin, time.Hour)
+	require.NoError(t, err)
+
+	handler := handlers.NewFamilyHandler(familyService, services.NewPermissionService(db))
+	router := gin.New()
+	// 以请求头中的用户身份访问
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(services.WithUserID(c.Request.Context(), c.GetHeader("X-User-ID")))
+	})
+	router.GET("/families", handler.ListFamilies)
+	router.GET("/families/:familyId", handler.GetFamily)
+
+	get := func(path, userID string) map[string]any {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-User-ID", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if list, ok := response["data"].([]any); ok {
+			require.Len(t, list, 1)
+			return list[0].(map[string]any)
+		}
+		return response["data"].(map[string]any)
+	}
+
+	for _, path := range []string{"/families/" + fixture.FamilyID, "/families"} {
+		assert.NotEmpty(t, get(path, fixture.UserID)["invite_code"], path)
+
+		viewer := get(path, viewerID)
+		assert.NotContains(t, viewer, "invite_code", path)
+		assert.NotContains(t, viewer, "invite_role", path)
+	}
+}
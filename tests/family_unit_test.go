@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/dto"
+)
+
+func TestFamilyServiceValidation(t *testing.T) {
+	familyService := services.NewFamilyService(nil)
+	ctx := services.WithUserID(context.Background(), "user-1")
+
+	t.Run("未登录不能创建家庭", func(t *testing.T) {
+		err := familyService.CreateFamily(context.Background(), &models.Family{Name: "测试家庭"})
+		assert.ErrorIs(t, err, services.ErrUnauthenticated)
+	})
+
+	t.Run("家庭名称不能为空", func(t *testing.T) {
+		err := familyService.CreateFamily(ctx, &models.Family{Name: "  "})
+		assert.EqualError(t, err, "家庭名称不能为空")
+	})
+
+	t.Run("邀请码不能授予所有者角色", func(t *testing.T) {
+		_, err := familyService.RotateInviteCode(ctx, "family-1", services.RoleOwner, time.Hour)
+		assert.Error(t, err)
+	})
+
+	t.Run("空邀请码无效", func(t *testing.T) {
+		_, err := familyService.JoinFamily(ctx, " ")
+		assert.ErrorIs(t, err, services.ErrInvalidInviteCode)
+	})
+}
+
+func TestToFamilyResponseInviteCode(t *testing.T) {
+	code := "ABCD2345"
+	expires := time.Now().Add(time.Hour)
+	family := &models.Family{ID: "family-1", Name: "测试家庭", InviteCode: &code, InviteRole: services.RoleMember, InviteExpiresAt: &expires}
+
+	t.Run("非管理者看不到邀请码", func(t *testing.T) {
+		response := dto.ToFamilyResponse(family, false)
+		assert.Nil(t, response.InviteCode)
+		assert.Empty(t, response.InviteRole)
+		assert.Nil(t, response.InviteExpiresAt)
+	})
+
+	t.Run("管理者看到有效的邀请码", func(t *testing.T) {
+		response := dto.ToFamilyResponse(family, true)
+		assert.Equal(t, &code, response.InviteCode)
+		assert.Equal(t, services.RoleMember, response.InviteRole)
+	})
+
+	t.Run("过期的邀请码不返回", func(t *testing.T) {
+		expired := time.Now().Add(-time.Minute)
+		stale := *family
+		stale.InviteExpiresAt = &expired
+		assert.Nil(t, dto.ToFamilyResponse(&stale, true).InviteCode)
+	})
+}
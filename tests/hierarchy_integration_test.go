@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+// closureRow 闭包表记录，以物品名称表示便于断言
+type closureRow struct {
+	Ancestor   string
+	Descendant string
+	Depth      int
+}
+
+// closureRows 读取指定物品相关的全部闭包记录
+func closureRows(t *testing.T, db *gorm.DB, names map[string]string) []closureRow {
+	t.Helper()
+
+	ids := make([]string, 0, len(names))
+	byID := make(map[string]string, len(names))
+	for name, id := range names {
+		ids = append(ids, id)
+		byID[id] = name
+	}
+
+	var hierarchy []models.ItemHierarchy
+	err := db.Select("ancestor_id", "descendant_id", "depth").
+		Where("ancestor_id IN ? OR descendant_id IN ?", ids, ids).
+		Find(&hierarchy).Error
+	require.NoError(t, err)
+
+	rows := make([]closureRow, 0, len(hierarchy))
+	for _, h := range hierarchy {
+		rows = append(rows, closureRow{Ancestor: byID[h.AncestorID], Descendant: byID[h.DescendantID], Depth: h.Depth})
+	}
+	return rows
+}
+
+func TestItemHierarchyIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "hierarchy_owner")
+	itemService := services.NewItemService(db, nil)
+
+	// 衣柜 ⊃ 收纳箱 ⊃ 证件袋，另有独立的行李箱
+	create := func(name string, containerID *string) string {
+		item := &models.Item{Name: name, RoomID: &fixture.RoomID, ContainerID: containerID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, item))
+		return item.ID
+	}
+	wardrobe := create("衣柜", nil)
+	box := create("收纳箱", &wardrobe)
+	pouch := create("证件袋", &box)
+	suitcase := create("行李箱", nil)
+
+	names := map[string]string{"衣柜": wardrobe, "收纳箱": box, "证件袋": pouch, "行李箱": suitcase}
+
+	t.Run("创建时写入全部祖先记录", func(t *testing.T) {
+		assert.ElementsMatch(t, []closureRow{
+			{"衣柜", "衣柜", 0}, {"收纳箱", "收纳箱", 0}, {"证件袋", "证件袋", 0}, {"行李箱", "行李箱", 0},
+			{"衣柜", "收纳箱", 1}, {"衣柜", "证件袋", 2}, {"收纳箱", "证件袋", 1},
+		}, closureRows(t, db, names))
+	})
+
+	t.Run("移动子树到新容器", func(t *testing.T) {
+		require.NoError(t, itemService.MoveItem(fixture.Ctx, box, services.MoveTarget{ContainerID: &suitcase}))
+
+		// 子树内部记录保留，与原祖先的记录断开，与新容器建立记录
+		assert.ElementsMatch(t, []closureRow{
+			{"衣柜", "衣柜", 0}, {"收纳箱", "收纳箱", 0}, {"证件袋", "证件袋", 0}, {"行李箱", "行李箱", 0},
+			{"行李箱", "收纳箱", 1}, {"行李箱", "证件袋", 2}, {"收纳箱", "证件袋", 1},
+		}, closureRows(t, db, names))
+	})
+
+	t.Run("不能移动到自己的后代中", func(t *testing.T) {
+		err := itemService.MoveItem(fixture.Ctx, suitcase, services.MoveTarget{ContainerID: &pouch})
+		assert.ErrorIs(t, err, services.ErrHierarchyCycle)
+	})
+
+	t.Run("移出容器", func(t *testing.T) {
+		require.NoError(t, itemService.MoveItem(fixture.Ctx, box, services.MoveTarget{RoomID: &fixture.RoomID}))
+
+		assert.ElementsMatch(t, []closureRow{
+			{"衣柜", "衣柜", 0}, {"收纳箱", "收纳箱", 0}, {"证件袋", "证件袋", 0}, {"行李箱", "行李箱", 0},
+			{"收纳箱", "证件袋", 1},
+		}, closureRows(t, db, names))
+	})
+
+	t.Run("删除子树", func(t *testing.T) {
+		require.NoError(t, itemService.MoveItem(fixture.Ctx, box, services.MoveTarget{ContainerID: &wardrobe}))
+
+		// 包含物品的容器不能直接删除，自下而上删除整个子树
+		assert.Error(t, itemService.DeleteItem(fixture.Ctx, box))
+		require.NoError(t, itemService.DeleteItem(fixture.Ctx, pouch))
+		require.NoError(t, itemService.DeleteItem(fixture.Ctx, box))
+
+		assert.ElementsMatch(t, []closureRow{
+			{"衣柜", "衣柜", 0}, {"行李箱", "行李箱", 0},
+		}, closureRows(t, db, names))
+	})
+
+	t.Run("重建结果与增量维护一致", func(t *testing.T) {
+		before := closureRows(t, db, names)
+		_, err := services.RebuildItemHierarchy(fixture.Ctx, db)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, before, closureRows(t, db, names))
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"nookverse/internal/auth"
 	"nookverse/internal/database"
 	"nookverse/internal/models"
 	"nookverse/internal/routers"
@@ -44,6 +45,11 @@ func TestHouseIntegration(t *testing.T) {
 		&models.Item{},
 		&models.MediaFile{},
 		&models.Reminder{},
+		&models.User{},
+		&models.RefreshToken{},
+		&models.Family{},
+		&models.FamilyMember{},
+		&models.ItemPermission{},
 	)
 	require.NoError(t, err, "数据库迁移失败")
 
@@ -52,9 +58,20 @@ func TestHouseIntegration(t *testing.T) {
 
 	// 初始化服务
 	houseService := services.NewHouseService(db)
-
-	// 设置路由
-	router := routers.SetupRoutes(nil, houseService)
+	userService := services.NewUserService(db, auth.NewTokenManager("test-secret", time.Hour), 24*time.Hour)
+	permissionService := services.NewPermissionService(db)
+	familyService := services.NewFamilyService(db)
+	categoryService := services.NewCategoryService(db)
+
+	// 设置路由（所有请求携带测试用户的访问令牌）
+	engine := routers.SetupRoutes(routers.Services{
+		House:      houseService,
+		User:       userService,
+		Permission: permissionService,
+		Family:     familyService,
+		Category:   categoryService,
+	})
+	router := withBearerToken(engine, loginTestUser(t, engine))
 
 	t.Run("创建房屋", func(t *testing.T) {
 		houseReq := dto.CreateHouseRequest{
@@ -279,12 +296,55 @@ func TestHouseIntegration(t *testing.T) {
 // 辅助函数
 func cleanupTestData(db *gorm.DB) {
 	// 按正确的依赖顺序删除数据
+	db.Exec("DELETE FROM family_houses")
 	db.Exec("DELETE FROM reminders")
 	db.Exec("DELETE FROM media_files")
 	db.Exec("DELETE FROM items")
 	db.Exec("DELETE FROM rooms")
 	db.Exec("DELETE FROM houses")
 	db.Exec("DELETE FROM categories WHERE is_system = false")
+	db.Exec("DELETE FROM refresh_tokens")
+	db.Exec("DELETE FROM family_members WHERE user_id IN (SELECT id FROM users WHERE username = 'integration_tester')")
+	db.Exec("DELETE FROM families WHERE owner_id IN (SELECT id FROM users WHERE username = 'integration_tester')")
+	db.Exec("DELETE FROM users WHERE username = 'integration_tester'")
+}
+
+// loginTestUser 注册并登录测试用户，返回访问令牌
+func loginTestUser(t *testing.T, router http.Handler) string {
+	registerReq, _ := json.Marshal(dto.RegisterRequest{
+		Username: "integration_tester",
+		Email:    "integration_tester@nookverse.com",
+		Password: "secret123",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/users/register", bytes.NewBuffer(registerReq))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, "注册测试用户失败")
+
+	loginReq, _ := json.Marshal(dto.LoginRequest{
+		Account:  "integration_tester",
+		Password: "secret123",
+	})
+	req, _ = http.NewRequest("POST", "/api/v1/users/login", bytes.NewBuffer(loginReq))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, "登录测试用户失败")
+
+	var response struct {
+		Data dto.TokenResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Data.AccessToken
+}
+
+// withBearerToken 为每个请求附加Authorization头
+func withBearerToken(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set("Authorization", "Bearer "+token)
+		next.ServeHTTP(w, r)
+	})
 }
 
 func timePtr(t time.Time) *time.Time {
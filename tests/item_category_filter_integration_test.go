@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestItemCategoryFilterIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "category_filter_owner")
+	categoryService := services.NewCategoryService(db)
+	itemService := services.NewItemService(db, nil)
+
+	// 电子产品 → 电脑 → 笔记本，每个分类下各一件物品，另有一件未分类物品
+	createCategory := func(name string, parentID *string) string {
+		category := &models.Category{Name: name, ParentID: parentID}
+		require.NoError(t, categoryService.CreateCategory(fixture.Ctx, category))
+		return category.ID
+	}
+	parent := createCategory("电子产品", nil)
+	child := createCategory("电脑", &parent)
+	grandchild := createCategory("笔记本", &child)
+
+	createItem := func(name string, categoryID *string) string {
+		item := &models.Item{Name: name, RoomID: &fixture.RoomID, CategoryID: categoryID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, item))
+		return item.ID
+	}
+	parentItem := createItem("widget router", &parent)
+	childItem := createItem("widget desktop", &child)
+	grandchildItem := createItem("widget laptop", &grandchild)
+	createItem("widget unsorted", nil)
+
+	ids := func(items []models.Item) []string {
+		result := make([]string, 0, len(items))
+		for _, item := range items {
+			result = append(result, item.ID)
+		}
+		return result
+	}
+
+	filters := func(includeDescendants bool) services.ItemFilters {
+		return services.ItemFilters{CategoryID: &parent, IncludeDescendants: includeDescendants}
+	}
+
+	t.Run("物品列表", func(t *testing.T) {
+		items, total, err := itemService.ListItems(fixture.Ctx, filters(true))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.ElementsMatch(t, []string{parentItem, childItem, grandchildItem}, ids(items))
+
+		items, total, err = itemService.ListItems(fixture.Ctx, filters(false))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, []string{parentItem}, ids(items))
+	})
+
+	t.Run("搜索", func(t *testing.T) {
+		hitIDs := func(hits []services.ItemSearchHit) []string {
+			result := make([]string, 0, len(hits))
+			for _, hit := range hits {
+				result = append(result, hit.Item.ID)
+			}
+			return result
+		}
+
+		hits, total, err := itemService.SearchItems(fixture.Ctx, "widget", filters(true))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+		assert.ElementsMatch(t, []string{parentItem, childItem, grandchildItem}, hitIDs(hits))
+
+		hits, _, err = itemService.SearchItems(fixture.Ctx, "widget", filters(false))
+		require.NoError(t, err)
+		assert.Equal(t, []string{parentItem}, hitIDs(hits))
+	})
+
+	t.Run("统计", func(t *testing.T) {
+		stats, err := itemService.GetItemStatistics(fixture.Ctx, fixture.UserID, filters(true))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), stats.TotalItems)
+
+		stats, err = itemService.GetItemStatistics(fixture.Ctx, fixture.UserID, filters(false))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.TotalItems)
+	})
+
+	t.Run("分类下物品", func(t *testing.T) {
+		items, err := itemService.GetItemsByCategory(fixture.Ctx, child, true)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{childItem, grandchildItem}, ids(items))
+
+		items, err = itemService.GetItemsByCategory(fixture.Ctx, child, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{childItem}, ids(items))
+	})
+}
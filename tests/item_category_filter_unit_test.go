@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestItemCategoryFilterSQL(t *testing.T) {
+	const categoryID = "category-parent"
+	ctx := services.WithUserID(context.Background(), "user-1")
+
+	queries := map[string]func(itemService services.ItemService, includeDescendants bool){
+		"物品列表": func(itemService services.ItemService, includeDescendants bool) {
+			itemService.ListItems(ctx, services.ItemFilters{CategoryID: testutils.StringPtr(categoryID), IncludeDescendants: includeDescendants})
+		},
+		"搜索": func(itemService services.ItemService, includeDescendants bool) {
+			itemService.SearchItems(ctx, "widget", services.ItemFilters{CategoryID: testutils.StringPtr(categoryID), IncludeDescendants: includeDescendants})
+		},
+		"统计": func(itemService services.ItemService, includeDescendants bool) {
+			itemService.GetItemStatistics(ctx, "user-1", services.ItemFilters{CategoryID: testutils.StringPtr(categoryID), IncludeDescendants: includeDescendants})
+		},
+		"分类下物品": func(itemService services.ItemService, includeDescendants bool) {
+			itemService.GetItemsByCategory(ctx, categoryID, includeDescendants)
+		},
+	}
+
+	for name, query := range queries {
+		t.Run(name+"包含后代分类", func(t *testing.T) {
+			db, recorder := testutils.DryRunDB()
+			query(services.NewItemService(db, nil), true)
+			assert.NotEmpty(t, recorder.Find("items.category_id IN (WITH RECURSIVE category_tree", "WHERE id = '"+categoryID+"'"))
+		})
+
+		t.Run(name+"只匹配分类本身", func(t *testing.T) {
+			db, recorder := testutils.DryRunDB()
+			query(services.NewItemService(db, nil), false)
+			assert.NotEmpty(t, recorder.Find("items.category_id = '"+categoryID+"'"))
+			assert.Empty(t, recorder.Find("WITH RECURSIVE category_tree"))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestMoveItemTarget(t *testing.T) {
+	itemService := services.NewItemService(nil, nil)
+	ctx := services.WithUserID(context.Background(), "user-1")
+
+	t.Run("未指定目标", func(t *testing.T) {
+		err := itemService.MoveItem(ctx, "item-1", services.MoveTarget{})
+		assert.Error(t, err)
+	})
+
+	t.Run("同时指定容器和房间", func(t *testing.T) {
+		err := itemService.MoveItem(ctx, "item-1", services.MoveTarget{
+			ContainerID: testutils.StringPtr("box-1"),
+			RoomID:      testutils.StringPtr("room-1"),
+		})
+		assert.Error(t, err)
+	})
+}
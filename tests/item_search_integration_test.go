@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestSearchItemsIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "search_owner")
+	itemService := services.NewItemService(db, nil)
+
+	// 测试库由 AutoMigrate 建表，没有触发器，索引由服务在写入物品时同步
+	drill := &models.Item{
+		Name:           "充电式电钻",
+		Description:    "带两块电池",
+		RoomID:         &fixture.RoomID,
+		Brand:          testutils.StringPtr("Makita"),
+		Model:          testutils.StringPtr("DF333D"),
+		CustomPosition: testutils.StringPtr("garage shelf"),
+		Attributes:     map[string]any{"voltage": "12V"},
+	}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, drill))
+	battery := &models.Item{Name: "<b>Makita</b> battery", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, battery))
+
+	search := func(query string) []services.ItemSearchHit {
+		hits, total, err := itemService.SearchItems(fixture.Ctx, query, services.ItemFilters{})
+		require.NoError(t, err)
+		assert.EqualValues(t, len(hits), total)
+		return hits
+	}
+
+	t.Run("名称匹配排在品牌匹配之前", func(t *testing.T) {
+		hits := search("makita")
+		require.Len(t, hits, 2)
+		assert.Equal(t, battery.ID, hits[0].Item.ID)
+		assert.Equal(t, drill.ID, hits[1].Item.ID)
+		assert.Greater(t, hits[0].Rank, hits[1].Rank)
+
+		// 片段中的物品文本已转义，匹配词以 <mark> 标出
+		assert.Contains(t, hits[1].Snippet, "<mark>Makita</mark>")
+		assert.NotContains(t, hits[0].Snippet, "<b>")
+	})
+
+	t.Run("型号、自定义位置和扩展属性都可搜索", func(t *testing.T) {
+		for _, query := range []string{"DF333D", "garage", "12V"} {
+			hits := search(query)
+			require.Len(t, hits, 1, query)
+			assert.Equal(t, drill.ID, hits[0].Item.ID, query)
+		}
+	})
+
+	t.Run("支持排除语法", func(t *testing.T) {
+		hits := search("makita -battery")
+		require.Len(t, hits, 1)
+		assert.Equal(t, drill.ID, hits[0].Item.ID)
+	})
+
+	t.Run("连续的中文按名称子串匹配", func(t *testing.T) {
+		hits := search("电钻")
+		require.Len(t, hits, 1)
+		assert.Equal(t, drill.ID, hits[0].Item.ID)
+	})
+
+	t.Run("修改物品后索引同步更新", func(t *testing.T) {
+		drill.Brand = testutils.StringPtr("Bosch")
+		require.NoError(t, itemService.UpdateItem(fixture.Ctx, drill))
+
+		assert.Len(t, search("bosch"), 1)
+		hits := search("makita")
+		require.Len(t, hits, 1)
+		assert.Equal(t, battery.ID, hits[0].Item.ID)
+	})
+
+	t.Run("回收站中的物品不出现在搜索结果中", func(t *testing.T) {
+		require.NoError(t, itemService.DeleteItem(fixture.Ctx, battery.ID))
+		assert.Empty(t, search("makita"))
+	})
+}
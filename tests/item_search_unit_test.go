@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestSearchItemsSQL(t *testing.T) {
+	ctx := services.WithUserID(context.Background(), "user-1")
+	db, recorder := testutils.DryRunDB()
+
+	services.NewItemService(db, nil).SearchItems(ctx, `drill -"cordless"`, services.ItemFilters{})
+
+	// 在全文检索索引中匹配，尚未建立索引的物品按名称子串匹配
+	match := `(search_index.searchable_content @@ websearch_to_tsquery('simple', 'drill -"cordless"') OR items.name ILIKE '%drill -"cordless"%')`
+	assert.NotEmpty(t, recorder.Find("SELECT count(*) FROM", "LEFT JOIN search_index ON search_index.item_id = items.id", match))
+
+	// 当前页按相关度排序并生成高亮片段
+	assert.NotEmpty(t, recorder.Find(
+		"ts_rank(search_index.searchable_content, websearch_to_tsquery('simple', 'drill -\"cordless\"'))",
+		"ts_headline('simple', concat_ws(' ', items.name, items.brand, items.model",
+		match,
+		"ORDER BY rank DESC, items.name",
+	))
+}
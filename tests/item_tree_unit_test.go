@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestBuildItemTree(t *testing.T) {
+	early := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cabinet := models.Item{ID: "cabinet", Name: "车库柜子", Quantity: 1}
+	descendants := []models.Item{
+		{ID: "box", Name: "工具箱", ContainerID: testutils.StringPtr("cabinet"), Quantity: 1, Price: testutils.Float64Ptr(100)},
+		{ID: "pouch", Name: "收纳袋", ContainerID: testutils.StringPtr("box"), Quantity: 2, ExpireDate: &late},
+		{ID: "glue", Name: "胶水", ContainerID: testutils.StringPtr("pouch"), Quantity: 3, Price: testutils.Float64Ptr(5), ExpireDate: &early},
+		{ID: "orphan", Name: "父节点不可见", ContainerID: testutils.StringPtr("hidden"), Quantity: 9},
+	}
+
+	t.Run("汇总完整子树", func(t *testing.T) {
+		tree := services.BuildItemTree(cabinet, descendants, 0)
+
+		assert.Equal(t, 3, tree.DescendantCount)
+		assert.Equal(t, 7, tree.TotalQuantity)
+		assert.InDelta(t, 115.0, tree.TotalValue, 0.001)
+		assert.Equal(t, early, *tree.EarliestExpireDate)
+		assert.Equal(t, "glue", tree.Children[0].Children[0].Children[0].Item.ID)
+	})
+
+	t.Run("深度限制不影响汇总值", func(t *testing.T) {
+		tree := services.BuildItemTree(cabinet, descendants, 1)
+
+		box := tree.Children[0]
+		assert.Equal(t, 1, box.Depth)
+		assert.True(t, box.Truncated)
+		assert.Empty(t, box.Children)
+		assert.Equal(t, 6, box.TotalQuantity)
+		assert.Equal(t, 7, tree.TotalQuantity)
+	})
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/pkg/api/v1/handlers"
+	"nookverse/tests/testutils"
+)
+
+// stubItemService 只实现更新物品用到的方法，其余方法调用时panic
+type stubItemService struct {
+	services.ItemService
+	item    *models.Item
+	updated bool
+}
+
+func (s *stubItemService) GetItemByID(_ context.Context, _ string) (*models.Item, error) {
+	item := *s.item
+	return &item, nil
+}
+
+func (s *stubItemService) UpdateItem(_ context.Context, _ *models.Item) error {
+	s.updated = true
+	return nil
+}
+
+// allowAllPermissions 允许全部操作的权限服务
+type allowAllPermissions struct {
+	services.PermissionService
+}
+
+func (allowAllPermissions) AuthorizeItem(context.Context, string, services.Action) error { return nil }
+func (allowAllPermissions) AuthorizeRoom(context.Context, string, services.Action) error { return nil }
+
+func TestUpdateItemLocationConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	const itemID = "11111111-1111-1111-1111-111111111111"
+
+	update := func(item *models.Item, body string) (int, bool) {
+		itemService := &stubItemService{item: item}
+		router := gin.New()
+		router.PUT("/items/:itemId", handlers.NewItemHandler(itemService, allowAllPermissions{}).UpdateItem)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/items/"+itemID, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w.Code, itemService.updated
+	}
+
+	inContainer := &models.Item{ID: itemID, Name: "证件袋", RoomID: testutils.StringPtr("room-1"), ContainerID: testutils.StringPtr("box-1")}
+	inRoom := &models.Item{ID: itemID, Name: "行李箱", RoomID: testutils.StringPtr("room-1")}
+
+	t.Run("容器内物品指定其他房间", func(t *testing.T) {
+		code, updated := update(inContainer, `{"room_id": "room-2"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.False(t, updated)
+	})
+
+	t.Run("同时指定房间和容器", func(t *testing.T) {
+		code, updated := update(inRoom, `{"room_id": "room-2", "container_id": "box-2"}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.False(t, updated)
+	})
+
+	t.Run("容器内物品指定所在房间", func(t *testing.T) {
+		code, updated := update(inContainer, `{"room_id": "room-1", "name": "护照袋"}`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, updated)
+	})
+
+	t.Run("房间内物品更换房间", func(t *testing.T) {
+		code, updated := update(inRoom, `{"room_id": "room-2"}`)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, updated)
+	})
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestItemVersionIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "version_owner")
+	itemService := services.NewItemService(db, nil)
+
+	box := &models.Item{Name: "收纳箱", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, box))
+
+	camera := &models.Item{
+		Name:        "相机",
+		Description: "出游前记得充电",
+		RoomID:      &fixture.RoomID,
+		Attributes:  map[string]any{"lens": "23mm"},
+		Labels:      []string{"摄影"},
+	}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, camera))
+
+	// 版本1之后的时间点
+	afterCreate := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	// 版本2：描述被误删
+	wiped := *camera
+	wiped.Description = ""
+	wiped.Attributes = nil
+	require.NoError(t, itemService.UpdateItem(fixture.Ctx, &wiped))
+
+	// 版本3：放进收纳箱
+	require.NoError(t, itemService.MoveItem(fixture.Ctx, camera.ID, services.MoveTarget{ContainerID: &box.ID}))
+
+	t.Run("每次修改记录一个版本", func(t *testing.T) {
+		versions, total, err := itemService.ListItemVersions(fixture.Ctx, camera.ID, 1, 20)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), total)
+
+		operations := make([]string, 0, len(versions))
+		for _, v := range versions {
+			operations = append(operations, v.Operation)
+		}
+		assert.Equal(t, []string{services.OperationMove, services.OperationUpdate, services.OperationCreate}, operations)
+	})
+
+	t.Run("查看时间点数据", func(t *testing.T) {
+		version, err := itemService.GetItemAsOf(fixture.Ctx, camera.ID, afterCreate)
+		require.NoError(t, err)
+		assert.Equal(t, 1, version.Version)
+
+		item, err := services.ItemFromVersion(version)
+		require.NoError(t, err)
+		assert.Equal(t, "出游前记得充电", item.Description)
+		assert.Equal(t, map[string]any{"lens": "23mm"}, item.Attributes)
+
+		_, err = itemService.GetItemAsOf(fixture.Ctx, camera.ID, afterCreate.Add(-time.Hour))
+		assert.ErrorIs(t, err, services.ErrItemVersionNotFound)
+	})
+
+	t.Run("比较版本", func(t *testing.T) {
+		diff, err := itemService.DiffItemVersions(fixture.Ctx, camera.ID, 1, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 3, diff.To)
+		assert.Equal(t, "出游前记得充电", diff.OldValue["description"])
+		assert.Equal(t, "", diff.NewValue["description"])
+		assert.Contains(t, diff.NewValue, "container_id")
+		assert.NotContains(t, diff.NewValue, "name")
+	})
+
+	t.Run("恢复版本", func(t *testing.T) {
+		restored, err := itemService.RestoreItemVersion(fixture.Ctx, camera.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "出游前记得充电", restored.Description)
+		assert.Nil(t, restored.ContainerID)
+
+		current, err := itemService.GetItemByID(fixture.Ctx, camera.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "出游前记得充电", current.Description)
+		assert.Equal(t, map[string]any{"lens": "23mm"}, current.Attributes)
+		assert.Nil(t, current.ContainerID)
+
+		latest, err := itemService.GetItemVersion(fixture.Ctx, camera.ID, 4)
+		require.NoError(t, err)
+		assert.Equal(t, services.OperationRestore, latest.Operation)
+
+		// 恢复后物品已移出容器，闭包表同步更新
+		var count int64
+		db.Model(&models.ItemHierarchy{}).Where("ancestor_id = ? AND descendant_id = ?", box.ID, camera.ID).Count(&count)
+		assert.Zero(t, count)
+	})
+
+	t.Run("快照中的容器已删除时拒绝恢复", func(t *testing.T) {
+		require.NoError(t, itemService.DeleteItem(fixture.Ctx, box.ID))
+
+		_, err := itemService.RestoreItemVersion(fixture.Ctx, camera.ID, 3)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+}
+
+func TestItemVersionCoverageIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "version_coverage")
+	itemService := services.NewItemService(db, nil)
+	categoryService := services.NewCategoryService(db)
+
+	latestVersion := func(itemID string) *models.ItemVersion {
+		versions, _, err := itemService.ListItemVersions(fixture.Ctx, itemID, 1, 1)
+		require.NoError(t, err)
+		require.NotEmpty(t, versions)
+		return &versions[0]
+	}
+
+	t.Run("没有版本记录的物品首次修改前补记原始数据", func(t *testing.T) {
+		legacy := &models.Item{Name: "旧收音机", Description: "短波可用", RoomID: &fixture.RoomID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, legacy))
+		// 模拟版本记录上线前创建的物品
+		require.NoError(t, db.Where("item_id = ?", legacy.ID).Delete(&models.ItemVersion{}).Error)
+		var stored models.Item
+		require.NoError(t, db.First(&stored, "id = ?", legacy.ID).Error)
+
+		wiped := stored
+		wiped.Description = ""
+		require.NoError(t, itemService.UpdateItem(fixture.Ctx, &wiped))
+
+		versions, total, err := itemService.ListItemVersions(fixture.Ctx, legacy.ID, 1, 20)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, total)
+		assert.Equal(t, services.OperationUpdate, versions[0].Operation)
+		assert.Equal(t, services.ItemVersionBaseline, versions[1].Operation)
+		assert.Nil(t, versions[1].UserID)
+
+		// 基线版本的时间为物品修改前的最后修改时间
+		baseline, err := itemService.GetItemAsOf(fixture.Ctx, legacy.ID, stored.UpdatedAt)
+		require.NoError(t, err)
+		item, err := services.ItemFromVersion(baseline)
+		require.NoError(t, err)
+		assert.Equal(t, "短波可用", item.Description)
+	})
+
+	t.Run("容器移动到其他房间时为其中的物品记录版本", func(t *testing.T) {
+		study := &models.Room{HouseID: fixture.HouseID, Name: "书房", RoomType: "study"}
+		require.NoError(t, db.Create(study).Error)
+
+		box := &models.Item{Name: "工具箱", RoomID: &fixture.RoomID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, box))
+		wrench := &models.Item{Name: "扳手", RoomID: &fixture.RoomID, ContainerID: &box.ID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, wrench))
+
+		require.NoError(t, itemService.MoveItem(fixture.Ctx, box.ID, services.MoveTarget{RoomID: &study.ID}))
+
+		version := latestVersion(wrench.ID)
+		assert.Equal(t, 2, version.Version)
+		assert.Equal(t, services.OperationMove, version.Operation)
+		item, err := services.ItemFromVersion(version)
+		require.NoError(t, err)
+		require.NotNil(t, item.RoomID)
+		assert.Equal(t, study.ID, *item.RoomID)
+	})
+
+	t.Run("合并分类时为转移的物品记录版本", func(t *testing.T) {
+		source := &models.Category{Name: "五金"}
+		require.NoError(t, categoryService.CreateCategory(fixture.Ctx, source))
+		target := &models.Category{Name: "工具"}
+		require.NoError(t, categoryService.CreateCategory(fixture.Ctx, target))
+
+		hammer := &models.Item{Name: "锤子", RoomID: &fixture.RoomID, CategoryID: &source.ID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, hammer))
+
+		moved, err := categoryService.MergeCategory(fixture.Ctx, source.ID, target.ID)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, moved)
+
+		version := latestVersion(hammer.ID)
+		assert.Equal(t, services.OperationUpdate, version.Operation)
+		item, err := services.ItemFromVersion(version)
+		require.NoError(t, err)
+		require.NotNil(t, item.CategoryID)
+		assert.Equal(t, target.ID, *item.CategoryID)
+
+		diff, err := itemService.DiffItemVersions(fixture.Ctx, hammer.ID, 1, 0)
+		require.NoError(t, err)
+		assert.Equal(t, &target.ID, diff.NewValue["category_id"])
+	})
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+func TestItemFromVersion(t *testing.T) {
+	// 从 jsonb 读出的快照：数组为 []any，数字为 float64，日期为字符串
+	version := &models.ItemVersion{
+		ItemID:  "item-1",
+		Version: 3,
+		Snapshot: map[string]any{
+			"name":            "相机",
+			"description":     "",
+			"room_id":         "room-1",
+			"container_id":    nil,
+			"quantity":        float64(2),
+			"price":           float64(3999.5),
+			"expire_date":     "2027-01-02T00:00:00Z",
+			"brand":           "Fujifilm",
+			"position":        map[string]any{"shelf": float64(2)},
+			"custom_position": "第二层左侧",
+			"attributes":      map[string]any{"lens": "23mm"},
+			"labels":          []any{"摄影", "贵重"},
+		},
+	}
+
+	item, err := services.ItemFromVersion(version)
+	require.NoError(t, err)
+
+	expire := time.Date(2027, 1, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "item-1", item.ID)
+	assert.Equal(t, "相机", item.Name)
+	assert.Equal(t, "room-1", *item.RoomID)
+	assert.Nil(t, item.ContainerID)
+	assert.Equal(t, 2, item.Quantity)
+	assert.Equal(t, 3999.5, *item.Price)
+	assert.True(t, expire.Equal(*item.ExpireDate))
+	assert.Equal(t, "Fujifilm", *item.Brand)
+	assert.Equal(t, map[string]any{"shelf": float64(2)}, item.Position)
+	assert.Equal(t, "第二层左侧", *item.CustomPosition)
+	assert.Equal(t, map[string]any{"lens": "23mm"}, item.Attributes)
+	assert.Equal(t, []string{"摄影", "贵重"}, item.Labels)
+}
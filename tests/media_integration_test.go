@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/internal/storage"
+	"nookverse/tests/testutils"
+)
+
+func TestMediaDedupIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "media_owner")
+
+	backend := storage.NewLocal(t.TempDir())
+	mediaService := services.NewMediaService(db, config.UploadConfig{
+		MaxSize:      1 << 20,
+		AllowedTypes: []string{"application/pdf"},
+	}, backend)
+
+	item := &models.Item{Name: "说明书", RoomID: &fixture.RoomID}
+	require.NoError(t, services.NewItemService(db, nil).CreateItem(fixture.Ctx, item))
+
+	const content = "%PDF-1.4\n% nookverse media test\n"
+	first, err := mediaService.UploadMedia(fixture.Ctx, item.ID, strings.NewReader(content), nil)
+	require.NoError(t, err)
+	second, err := mediaService.UploadMedia(fixture.Ctx, item.ID, strings.NewReader(content), nil)
+	require.NoError(t, err)
+
+	// 相同内容共用一个存储对象
+	require.Equal(t, first.StorageKey, second.StorageKey)
+	assert.Equal(t, 1, second.SortOrder)
+
+	exists := func() bool {
+		ok, err := backend.Exists(fixture.Ctx, first.StorageKey)
+		require.NoError(t, err)
+		return ok
+	}
+	assert.True(t, exists())
+
+	// 仍被引用时保留对象，最后一个引用删除后清理
+	require.NoError(t, mediaService.DeleteMedia(fixture.Ctx, item.ID, first.ID))
+	assert.True(t, exists())
+	require.NoError(t, mediaService.DeleteMedia(fixture.Ctx, item.ID, second.ID))
+	assert.False(t, exists())
+
+	// 对象被清理后再次上传相同内容会重新写入
+	third, err := mediaService.UploadMedia(fixture.Ctx, item.ID, strings.NewReader(content), nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.StorageKey, third.StorageKey)
+	assert.True(t, exists())
+}
+
+func TestMigrateMediaSkipsMissingIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "migrate_owner")
+
+	item := &models.Item{Name: "旧照片", RoomID: &fixture.RoomID}
+	require.NoError(t, services.NewItemService(db, nil).CreateItem(fixture.Ctx, item))
+
+	from := storage.NewLocal(t.TempDir())
+	to := storage.NewLocal(t.TempDir())
+	require.NoError(t, from.Put(fixture.Ctx, "uploads/present.pdf", strings.NewReader("%PDF-1.4\n"), 9, "application/pdf"))
+
+	present := &models.MediaFile{ItemID: item.ID, FileType: services.MediaTypeDocument, FileURL: "/uploads/present.pdf"}
+	missing := &models.MediaFile{ItemID: item.ID, FileType: services.MediaTypeDocument, FileURL: "/uploads/missing.pdf"}
+	require.NoError(t, db.Create(present).Error)
+	require.NoError(t, db.Create(missing).Error)
+
+	result, err := services.MigrateMedia(fixture.Ctx, db, from, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Copied)
+	assert.Equal(t, int64(1), result.Missing)
+
+	var migrated, untouched models.MediaFile
+	require.NoError(t, db.First(&migrated, "id = ?", present.ID).Error)
+	require.NoError(t, db.First(&untouched, "id = ?", missing.ID).Error)
+
+	assert.Equal(t, "uploads/present.pdf", migrated.StorageKey)
+	assert.Equal(t, "/api/v1/items/"+item.ID+"/media/"+present.ID, migrated.FileURL)
+
+	// 源对象缺失的记录保留原地址，不改写为无法访问的API地址
+	assert.Empty(t, untouched.StorageKey)
+	assert.Equal(t, "/uploads/missing.pdf", untouched.FileURL)
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+)
+
+func TestDetectMediaType(t *testing.T) {
+	allowed := []string{"image/jpeg", "image/png", "video/mp4"}
+
+	t.Run("按文件头识别PNG", func(t *testing.T) {
+		head := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+		mimeType, err := services.DetectMediaType(head, allowed)
+		assert.NoError(t, err)
+		assert.Equal(t, "image/png", mimeType)
+	})
+
+	t.Run("忽略扩展名伪装的文本文件", func(t *testing.T) {
+		_, err := services.DetectMediaType([]byte("<html><script>alert(1)</script>"), allowed)
+		assert.ErrorIs(t, err, services.ErrMediaTypeNotAllowed)
+	})
+
+	t.Run("不在允许列表中的类型", func(t *testing.T) {
+		_, err := services.DetectMediaType([]byte("GIF89a\x01\x00\x01\x00"), allowed)
+		assert.ErrorIs(t, err, services.ErrMediaTypeNotAllowed)
+	})
+}
+
+func TestMediaStorageKey(t *testing.T) {
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+	t.Run("按哈希前缀分目录并带扩展名", func(t *testing.T) {
+		assert.Equal(t, "9f/"+hash+".jpg", services.MediaStorageKey(hash, "image/jpeg"))
+		assert.Equal(t, "9f/"+hash+".mp4", services.MediaStorageKey(hash, "video/mp4"))
+	})
+
+	t.Run("媒体文件归类", func(t *testing.T) {
+		assert.Equal(t, services.MediaTypeImage, services.MediaFileType("image/png"))
+		assert.Equal(t, services.MediaTypeVideo, services.MediaFileType("video/mp4"))
+		assert.Equal(t, services.MediaTypeDocument, services.MediaFileType("application/pdf"))
+	})
+}
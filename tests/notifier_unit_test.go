@@ -0,0 +1,277 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/config"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+// smtpMessage SMTP测试服务器收到的邮件
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+// smtpSink 本地SMTP测试服务器，记录收到的邮件，拒绝 reject 中的收件人
+type smtpSink struct {
+	listener net.Listener
+	reject   map[string]bool
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newSMTPSink(t *testing.T, reject ...string) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	sink := &smtpSink{listener: listener, reject: map[string]bool{}}
+	for _, address := range reject {
+		sink.reject[address] = true
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sink.serve(conn)
+		}
+	}()
+	return sink
+}
+
+func (s *smtpSink) config() config.SMTPConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return config.SMTPConfig{Host: host, Port: portNum, From: "Nookverse <noreply@nookverse.test>"}
+}
+
+func (s *smtpSink) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *smtpSink) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var current smtpMessage
+	reply("220 sink ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		command := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(command)
+
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 sink")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			current = smtpMessage{From: strings.Trim(command[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			address := strings.Trim(command[len("RCPT TO:"):], "<> ")
+			if s.reject[address] {
+				reply("550 no such user")
+				continue
+			}
+			current.To = append(current.To, address)
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 end with .")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			current.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, current)
+			s.mu.Unlock()
+			reply("250 queued")
+		case upper == "RSET":
+			current = smtpMessage{}
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func testReminder() *models.Reminder {
+	return &models.Reminder{
+		ID:           "7d4c5c38-6a9e-4c4e-9f51-1f0b8a0b5f01",
+		ItemID:       "0b6f3b9e-1d2a-4a53-8d6b-5c7e2f9a4b10",
+		ReminderType: "expire",
+		TriggerTime:  time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC),
+		Message:      "牛奶明天过期",
+		Item:         &models.Item{Name: "牛奶"},
+	}
+}
+
+func staticRecipients(users ...models.User) services.RecipientResolver {
+	return func(ctx context.Context, reminder *models.Reminder) ([]models.User, error) {
+		return users, nil
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	t.Run("向每个接收人单独发送邮件", func(t *testing.T) {
+		sink := newSMTPSink(t)
+		notifier, err := services.NewEmailNotifier(sink.config(), staticRecipients(
+			models.User{ID: "u1", Email: "alice@nookverse.test"},
+			models.User{ID: "u2", Email: "bob@nookverse.test"},
+		))
+		require.NoError(t, err)
+
+		require.NoError(t, notifier.Notify(context.Background(), testReminder()))
+
+		messages := sink.received()
+		require.Len(t, messages, 2)
+		assert.Equal(t, "noreply@nookverse.test", messages[0].From)
+		assert.Equal(t, []string{"alice@nookverse.test"}, messages[0].To)
+		assert.Equal(t, []string{"bob@nookverse.test"}, messages[1].To)
+		assert.Contains(t, messages[0].Data, "Subject: =?UTF-8?b?")
+		assert.Contains(t, messages[0].Data, "Content-Type: text/plain; charset=UTF-8")
+		assert.NotContains(t, messages[0].Data, "bob@nookverse.test")
+	})
+
+	t.Run("部分收件人被拒绝时继续投递并返回错误", func(t *testing.T) {
+		sink := newSMTPSink(t, "gone@nookverse.test")
+		notifier, err := services.NewEmailNotifier(sink.config(), staticRecipients(
+			models.User{ID: "u1", Email: "gone@nookverse.test"},
+			models.User{ID: "u2", Email: "bob@nookverse.test"},
+		))
+		require.NoError(t, err)
+
+		err = notifier.Notify(context.Background(), testReminder())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "gone@nookverse.test")
+
+		messages := sink.received()
+		require.Len(t, messages, 1)
+		assert.Equal(t, []string{"bob@nookverse.test"}, messages[0].To)
+	})
+
+	t.Run("发件地址无效", func(t *testing.T) {
+		_, err := services.NewEmailNotifier(config.SMTPConfig{Host: "localhost", From: "not an address"}, staticRecipients())
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	secret := "webhook-secret"
+
+	t.Run("请求体携带可校验的签名", func(t *testing.T) {
+		phone := "13800000000"
+		var payload services.WebhookPayload
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			timestamp, err := strconv.ParseInt(r.Header.Get(services.WebhookTimestampHeader), 10, 64)
+			if err != nil || services.VerifyWebhook([]byte(secret), timestamp, body, r.Header.Get(services.WebhookSignatureHeader)) != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, testReminder().ID, r.Header.Get(services.WebhookDeliveryHeader))
+			json.Unmarshal(body, &payload)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		notifier, err := services.NewWebhookNotifier("sms", config.WebhookConfig{URL: server.URL, Secret: secret},
+			staticRecipients(models.User{ID: "u1", Username: "alice", Phone: &phone}))
+		require.NoError(t, err)
+
+		require.NoError(t, notifier.Notify(context.Background(), testReminder()))
+		assert.Equal(t, "sms", payload.Channel)
+		assert.Equal(t, "牛奶", payload.Reminder.ItemName)
+		assert.Equal(t, "过期提醒：牛奶", payload.Reminder.Title)
+		require.Len(t, payload.Recipients, 1)
+		assert.Equal(t, &phone, payload.Recipients[0].Phone)
+	})
+
+	t.Run("签名密钥不一致时校验失败", func(t *testing.T) {
+		body := []byte(`{"channel":"webhook"}`)
+		signature := services.SignWebhook([]byte(secret), 1700000000, body)
+
+		assert.NoError(t, services.VerifyWebhook([]byte(secret), 1700000000, body, signature))
+		assert.Error(t, services.VerifyWebhook([]byte("other"), 1700000000, body, signature))
+		assert.Error(t, services.VerifyWebhook([]byte(secret), 1700000001, body, signature))
+	})
+
+	t.Run("非2xx响应视为发送失败", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "gateway down", http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		notifier, err := services.NewWebhookNotifier("webhook", config.WebhookConfig{URL: server.URL, Secret: secret}, staticRecipients())
+		require.NoError(t, err)
+
+		err = notifier.Notify(context.Background(), testReminder())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "502")
+	})
+
+	t.Run("必须配置签名密钥", func(t *testing.T) {
+		_, err := services.NewWebhookNotifier("webhook", config.WebhookConfig{URL: "https://example.com/hook"}, staticRecipients())
+		assert.Error(t, err)
+	})
+}
+
+func TestNotifierRegistry(t *testing.T) {
+	registry := &services.NotifierRegistry{}
+	registry.Register("app", services.NotifierFunc(func(ctx context.Context, reminder *models.Reminder) error { return nil }))
+	registry.Register("sms", services.NotifierFunc(func(ctx context.Context, reminder *models.Reminder) error { return nil }))
+
+	t.Run("已注册渠道通过校验", func(t *testing.T) {
+		assert.NoError(t, registry.Validate([]string{"app", "sms"}))
+		assert.Equal(t, []string{"app", "sms"}, registry.Channels())
+	})
+
+	t.Run("拒绝未注册的渠道", func(t *testing.T) {
+		err := registry.Validate([]string{"app", "pager"})
+		assert.True(t, errors.Is(err, services.ErrUnsupportedNotifyChannel))
+		assert.Contains(t, err.Error(), "pager")
+	})
+
+	t.Run("webhook渠道不能与内置渠道重名", func(t *testing.T) {
+		_, err := services.NewNotifierRegistry(nil, config.NotifyConfig{
+			Webhooks: map[string]config.WebhookConfig{"app": {URL: "https://example.com/hook", Secret: "s"}},
+		})
+		assert.Error(t, err)
+	})
+}
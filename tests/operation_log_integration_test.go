@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/clause"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestFamilyActivityIsolationIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	familyA := testutils.SeedFamily(t, db, "activity_a")
+	memberCtx, _ := testutils.AddMember(t, db, familyA.FamilyID, "activity_member", services.RoleMember)
+
+	// familyA 的 owner 同时是另一个家庭的成员
+	familyB := testutils.SeedFamily(t, db, "activity_b")
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.FamilyMember{
+		FamilyID: familyB.FamilyID, UserID: familyA.UserID, Role: services.RoleMember, Status: 1,
+	}).Error)
+
+	itemService := services.NewItemService(db, nil)
+	houseService := services.NewHouseService(db)
+	logService := services.NewOperationLogService(db)
+
+	// 私有物品删除后，日志的 item_id 被置空
+	diary := &models.Item{Name: "日记本", RoomID: &familyA.RoomID}
+	require.NoError(t, itemService.CreateItem(familyA.Ctx, diary))
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.ItemPermission{
+		ItemID: diary.ID, UserID: familyA.UserID, PermissionLevel: services.PermissionOwner,
+	}).Error)
+	require.NoError(t, itemService.DeleteItem(familyA.Ctx, diary.ID))
+
+	// 房屋删除后，家庭与房屋的关联随之删除
+	cabin := &models.House{Name: "小木屋"}
+	require.NoError(t, houseService.CreateHouse(familyA.Ctx, cabin, familyA.FamilyID))
+	require.NoError(t, houseService.DeleteHouse(familyA.Ctx, cabin.ID))
+
+	deletions := func(ctx context.Context, familyID string) []string {
+		logs, _, err := logService.ListFamilyActivity(ctx, familyID, services.ActivityFilters{PageSize: 100})
+		require.NoError(t, err)
+
+		var entityIDs []string
+		for _, log := range logs {
+			if log.OperationType == services.OperationDelete {
+				entityIDs = append(entityIDs, *log.EntityID)
+			}
+		}
+		return entityIDs
+	}
+
+	t.Run("所有者能看到已删除私有物品的日志", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{diary.ID, cabin.ID}, deletions(familyA.Ctx, familyA.FamilyID))
+	})
+
+	t.Run("其他成员看不到已删除私有物品的日志", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{cabin.ID}, deletions(memberCtx, familyA.FamilyID))
+	})
+
+	t.Run("操作人所在的其他家庭看不到", func(t *testing.T) {
+		assert.Empty(t, deletions(familyB.Ctx, familyB.FamilyID))
+		assert.Empty(t, deletions(familyA.Ctx, familyB.FamilyID))
+	})
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+func TestAuditDiff(t *testing.T) {
+	roomA, roomB := "room-a", "room-b"
+	before := &models.Item{ID: "item-1", Name: "牛奶", Quantity: 1, RoomID: &roomA, UpdatedAt: time.Now().Add(-time.Hour)}
+
+	t.Run("只记录发生变化的列", func(t *testing.T) {
+		after := *before
+		after.Quantity = 2
+		after.RoomID = &roomB
+		after.UpdatedAt = time.Now()
+
+		oldValue, newValue := services.AuditDiff(before, &after)
+		assert.Equal(t, map[string]any{"quantity": 1, "room_id": &roomA}, oldValue)
+		assert.Equal(t, map[string]any{"quantity": 2, "room_id": &roomB}, newValue)
+	})
+
+	t.Run("没有变化时返回nil", func(t *testing.T) {
+		after := *before
+		after.UpdatedAt = time.Now()
+		after.Room = &models.Room{Name: "厨房"}
+
+		oldValue, newValue := services.AuditDiff(before, &after)
+		assert.Nil(t, oldValue)
+		assert.Nil(t, newValue)
+	})
+
+	t.Run("清空字段记录为null", func(t *testing.T) {
+		after := *before
+		after.RoomID = nil
+
+		oldValue, newValue := services.AuditDiff(before, &after)
+		assert.Equal(t, &roomA, oldValue["room_id"])
+		assert.Contains(t, newValue, "room_id")
+		assert.Nil(t, newValue["room_id"])
+	})
+}
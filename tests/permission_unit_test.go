@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+func TestPermissionServiceRequiresUser(t *testing.T) {
+	permissionService := services.NewPermissionService(nil)
+	ctx := context.Background()
+
+	t.Run("未登录时拒绝所有校验", func(t *testing.T) {
+		assert.ErrorIs(t, permissionService.AuthorizeFamily(ctx, "", services.ActionCreate), services.ErrUnauthenticated)
+		assert.ErrorIs(t, permissionService.AuthorizeHouse(ctx, "house-1", services.ActionEdit), services.ErrUnauthenticated)
+		assert.ErrorIs(t, permissionService.AuthorizeRoom(ctx, "room-1", services.ActionDelete), services.ErrUnauthenticated)
+		assert.ErrorIs(t, permissionService.AuthorizeItem(ctx, "item-1", services.ActionView), services.ErrUnauthenticated)
+	})
+
+	t.Run("无效的权限级别", func(t *testing.T) {
+		_, err := permissionService.GrantItemPermission(services.WithUserID(ctx, "user-1"), "item-1", "user-2", "admin")
+		assert.EqualError(t, err, "无效的权限级别")
+	})
+}
+
+func TestResolveEffectivePermission(t *testing.T) {
+	const itemID, userID, ownerID = "item-1", "user-1", "user-owner"
+
+	viewActions := map[services.Action]bool{services.ActionView: true}
+	editActions := map[services.Action]bool{
+		services.ActionView: true, services.ActionCreate: true, services.ActionEdit: true, services.ActionDelete: true,
+	}
+	ownerActions := map[services.Action]bool{
+		services.ActionView: true, services.ActionCreate: true, services.ActionEdit: true, services.ActionDelete: true, services.ActionManage: true,
+	}
+
+	// 私有物品：另一位成员持有owner级授权
+	privateGrant := models.ItemPermission{ItemID: itemID, UserID: ownerID, PermissionLevel: services.PermissionOwner}
+	editGrant := models.ItemPermission{ItemID: itemID, UserID: userID, PermissionLevel: services.PermissionEdit}
+	viewGrant := models.ItemPermission{ItemID: itemID, UserID: userID, PermissionLevel: services.PermissionView}
+
+	tests := []struct {
+		name    string
+		role    string
+		grants  []models.ItemPermission
+		level   string
+		actions map[services.Action]bool
+	}{
+		{"owner 无授权", services.RoleOwner, nil, services.PermissionOwner, ownerActions},
+		{"admin 无授权", services.RoleAdmin, nil, services.PermissionOwner, ownerActions},
+		{"member 无授权", services.RoleMember, nil, services.PermissionEdit, editActions},
+		{"viewer 无授权", services.RoleViewer, nil, services.PermissionView, viewActions},
+
+		{"owner 单独授权优先于角色", services.RoleOwner, []models.ItemPermission{viewGrant}, services.PermissionView, viewActions},
+		{"admin 单独授权优先于角色", services.RoleAdmin, []models.ItemPermission{viewGrant}, services.PermissionView, viewActions},
+		{"member 单独授权", services.RoleMember, []models.ItemPermission{viewGrant}, services.PermissionView, viewActions},
+		{"viewer 单独授权提升为edit", services.RoleViewer, []models.ItemPermission{editGrant}, services.PermissionEdit, editActions},
+
+		{"owner 私有物品无授权不可见", services.RoleOwner, []models.ItemPermission{privateGrant}, "", map[services.Action]bool{}},
+		{"admin 私有物品无授权不可见", services.RoleAdmin, []models.ItemPermission{privateGrant}, "", map[services.Action]bool{}},
+		{"member 私有物品无授权不可见", services.RoleMember, []models.ItemPermission{privateGrant}, "", map[services.Action]bool{}},
+		{"viewer 私有物品无授权不可见", services.RoleViewer, []models.ItemPermission{privateGrant}, "", map[services.Action]bool{}},
+
+		{"owner 私有物品有授权", services.RoleOwner, []models.ItemPermission{privateGrant, viewGrant}, services.PermissionView, viewActions},
+		{"admin 私有物品有授权", services.RoleAdmin, []models.ItemPermission{privateGrant, editGrant}, services.PermissionEdit, editActions},
+		{"member 私有物品有授权", services.RoleMember, []models.ItemPermission{privateGrant, editGrant}, services.PermissionEdit, editActions},
+		{"viewer 私有物品有授权", services.RoleViewer, []models.ItemPermission{privateGrant, viewGrant}, services.PermissionView, viewActions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effective := services.ResolveEffectivePermission(itemID, userID, tt.role, tt.grants)
+			assert.Equal(t, tt.role, effective.Role)
+			assert.Equal(t, tt.level, effective.Level)
+			assert.Equal(t, tt.actions, effective.Actions)
+
+			private := false
+			for _, grant := range tt.grants {
+				private = private || grant.PermissionLevel == services.PermissionOwner
+			}
+			assert.Equal(t, private, effective.Private)
+		})
+	}
+
+	t.Run("私有物品的所有者", func(t *testing.T) {
+		effective := services.ResolveEffectivePermission(itemID, ownerID, services.RoleMember, []models.ItemPermission{privateGrant})
+		assert.True(t, effective.Private)
+		assert.Equal(t, services.PermissionOwner, effective.Level)
+		assert.Equal(t, services.PermissionOwner, *effective.GrantLevel)
+		assert.Equal(t, ownerActions, effective.Actions)
+	})
+}
@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/models"
+	"nookverse/internal/recurrence"
+	"nookverse/internal/services"
+)
+
+func utcDate(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestRecurrenceParse(t *testing.T) {
+	t.Run("解析并规范化规则", func(t *testing.T) {
+		rule, err := recurrence.Parse("RRULE:freq=weekly;interval=2;byday=MO,FR")
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", rule.String())
+	})
+
+	t.Run("拒绝无效规则", func(t *testing.T) {
+		for _, text := range []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=HOURLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=MONTHLY;BYDAY=1MO",
+			"FREQ=MONTHLY;BYMONTHDAY=0",
+			"FREQ=DAILY;COUNT=3;UNTIL=20260101",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=YEARLY;BYDAY=MO",
+		} {
+			_, err := recurrence.Parse(text)
+			assert.True(t, errors.Is(err, recurrence.ErrInvalidRule), text)
+		}
+	})
+}
+
+func TestRecurrenceAfter(t *testing.T) {
+	next := func(text string, start, after time.Time) (time.Time, bool) {
+		rule, err := recurrence.Parse(text)
+		require.NoError(t, err)
+		return rule.After(start, after)
+	}
+
+	t.Run("每3个月", func(t *testing.T) {
+		start := utcDate(2026, 1, 15, 9)
+		occurrence, ok := next("FREQ=MONTHLY;INTERVAL=3", start, start)
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 4, 15, 9), occurrence)
+	})
+
+	t.Run("跳过不存在的日期", func(t *testing.T) {
+		start := utcDate(2026, 1, 31, 9)
+		occurrence, ok := next("FREQ=MONTHLY", start, start)
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 3, 31, 9), occurrence)
+	})
+
+	t.Run("每月最后一天", func(t *testing.T) {
+		occurrence, ok := next("FREQ=MONTHLY;BYMONTHDAY=-1", utcDate(2026, 1, 31, 9), utcDate(2026, 2, 1, 0))
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 2, 28, 9), occurrence)
+	})
+
+	t.Run("每周指定的几天", func(t *testing.T) {
+		// 2026-03-02 为周一
+		start := utcDate(2026, 3, 2, 8)
+		occurrence, ok := next("FREQ=WEEKLY;BYDAY=MO,TH", start, start)
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 3, 5, 8), occurrence)
+
+		occurrence, ok = next("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", start, utcDate(2026, 3, 5, 8))
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 3, 16, 8), occurrence)
+	})
+
+	t.Run("COUNT和UNTIL结束规则", func(t *testing.T) {
+		start := utcDate(2026, 1, 1, 9)
+		_, ok := next("FREQ=DAILY;COUNT=3", start, utcDate(2026, 1, 3, 9))
+		assert.False(t, ok)
+
+		occurrence, ok := next("FREQ=DAILY;UNTIL=20260103", start, utcDate(2026, 1, 2, 9))
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 1, 3, 9), occurrence)
+		_, ok = next("FREQ=DAILY;UNTIL=20260103", start, utcDate(2026, 1, 3, 9))
+		assert.False(t, ok)
+	})
+
+	t.Run("永远不会发生的规则", func(t *testing.T) {
+		_, ok := next("FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30", utcDate(2026, 1, 1, 9), utcDate(2026, 1, 1, 9))
+		assert.False(t, ok)
+	})
+}
+
+func TestNextOccurrence(t *testing.T) {
+	rule := "FREQ=MONTHLY;INTERVAL=3"
+	start := utcDate(2026, 1, 10, 9)
+	snoozed := utcDate(2026, 4, 12, 9)
+	occurrence := utcDate(2026, 4, 10, 9)
+	reminder := &models.Reminder{
+		ReminderType:    services.ReminderTypeMaintenance,
+		TriggerTime:     snoozed,
+		Recurrence:      &rule,
+		RecurrenceStart: &start,
+		OccurrenceAt:    &occurrence,
+	}
+
+	t.Run("按首次时间推算，不受稍后提醒影响", func(t *testing.T) {
+		next, ok, err := services.NextOccurrence(reminder, occurrence)
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 7, 10, 9), next)
+	})
+
+	t.Run("跳过已错过的时间", func(t *testing.T) {
+		next, ok, err := services.NextOccurrence(reminder, utcDate(2026, 8, 1, 0))
+		require.NoError(t, err)
+		require.True(t, ok)
+		assert.Equal(t, utcDate(2026, 10, 10, 9), next)
+	})
+
+	t.Run("非重复提醒没有下一次", func(t *testing.T) {
+		_, ok, err := services.NextOccurrence(&models.Reminder{TriggerTime: start}, start)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,192 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+// policyReminders 物品由策略生成的提醒，按创建顺序
+func policyReminders(t *testing.T, db *gorm.DB, itemID string) []models.Reminder {
+	t.Helper()
+
+	var reminders []models.Reminder
+	require.NoError(t, db.Where("item_id = ? AND policy_id IS NOT NULL", itemID).Order("created_at").Find(&reminders).Error)
+	return reminders
+}
+
+// pendingPolicyReminder 物品唯一一条待发送的策略提醒
+func pendingPolicyReminder(t *testing.T, db *gorm.DB, itemID string) models.Reminder {
+	t.Helper()
+
+	var pending []models.Reminder
+	for _, reminder := range policyReminders(t, db, itemID) {
+		if reminder.Status == services.ReminderStatusPending {
+			pending = append(pending, reminder)
+		}
+	}
+	require.Len(t, pending, 1)
+	return pending[0]
+}
+
+// policyTrigger 物品过期日前若干天9点，按数据库中保存的过期日计算
+func policyTrigger(t *testing.T, db *gorm.DB, itemID string, daysBefore int) time.Time {
+	t.Helper()
+
+	var item models.Item
+	require.NoError(t, db.First(&item, "id = ?", itemID).Error)
+	require.NotNil(t, item.ExpireDate)
+	due := *item.ExpireDate
+	return time.Date(due.Year(), due.Month(), due.Day()-daysBefore, 9, 0, 0, 0, due.Location())
+}
+
+func TestPolicyReminderSyncIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "policy_owner")
+
+	registry := &services.NotifierRegistry{}
+	registry.Register(services.NotifyChannelApp, services.NotifierFunc(func(ctx context.Context, reminder *models.Reminder) error { return nil }))
+	registry.Register("sms", services.NotifierFunc(func(ctx context.Context, reminder *models.Reminder) error { return nil }))
+
+	itemService := services.NewItemService(db, nil)
+	reminderService := services.NewReminderService(db, registry)
+	policyService := services.NewReminderPolicyService(db, registry)
+
+	expire := time.Now().AddDate(0, 2, 0).Truncate(24 * time.Hour).UTC()
+	milk := &models.Item{Name: "奶粉", RoomID: &fixture.RoomID, ExpireDate: &expire}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, milk))
+	assert.Empty(t, policyReminders(t, db, milk.ID), "没有策略时不生成提醒")
+
+	reload := func(item *models.Item) models.Item {
+		var stored models.Item
+		require.NoError(t, db.First(&stored, "id = ?", item.ID).Error)
+		return stored
+	}
+
+	t.Run("设置策略后为家庭内已有物品生成提醒", func(t *testing.T) {
+		_, err := policyService.ReplacePolicies(fixture.Ctx, fixture.FamilyID, []models.ReminderPolicy{
+			{ReminderType: services.ReminderTypeExpire, DaysBefore: 7, NotifyChannels: []string{"app"}},
+		})
+		require.NoError(t, err)
+
+		reminder := pendingPolicyReminder(t, db, milk.ID)
+		assert.True(t, policyTrigger(t, db, milk.ID, 7).Equal(reminder.TriggerTime))
+		assert.Equal(t, []string{"app"}, reminder.NotifyChannels)
+	})
+
+	t.Run("新建物品按策略生成提醒", func(t *testing.T) {
+		bread := &models.Item{Name: "面包", RoomID: &fixture.RoomID, ExpireDate: &expire}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, bread))
+		pendingPolicyReminder(t, db, bread.ID)
+	})
+
+	t.Run("修改日期后调整同一条提醒", func(t *testing.T) {
+		before := pendingPolicyReminder(t, db, milk.ID)
+
+		updated := reload(milk)
+		later := expire.AddDate(0, 0, 10)
+		updated.ExpireDate = &later
+		require.NoError(t, itemService.UpdateItem(fixture.Ctx, &updated))
+
+		after := pendingPolicyReminder(t, db, milk.ID)
+		assert.Equal(t, before.ID, after.ID)
+		assert.True(t, policyTrigger(t, db, milk.ID, 7).Equal(after.TriggerTime))
+		require.NotNil(t, after.OccurrenceAt)
+		assert.True(t, policyTrigger(t, db, milk.ID, 7).Equal(*after.OccurrenceAt))
+	})
+
+	t.Run("稍后提醒的时间不被同步覆盖", func(t *testing.T) {
+		reminder := pendingPolicyReminder(t, db, milk.ID)
+		until := time.Now().Add(48 * time.Hour).Truncate(time.Second)
+		_, err := reminderService.SnoozeReminder(fixture.Ctx, reminder.ID, until)
+		require.NoError(t, err)
+
+		updated := reload(milk)
+		updated.Description = "开封后一个月内吃完"
+		require.NoError(t, itemService.UpdateItem(fixture.Ctx, &updated))
+
+		snoozed := pendingPolicyReminder(t, db, milk.ID)
+		assert.Equal(t, reminder.ID, snoozed.ID)
+		assert.True(t, until.Equal(snoozed.TriggerTime))
+	})
+
+	t.Run("修改策略的渠道后调整已有提醒，删除策略后取消其提醒", func(t *testing.T) {
+		reminder := pendingPolicyReminder(t, db, milk.ID)
+
+		_, err := policyService.ReplacePolicies(fixture.Ctx, fixture.FamilyID, []models.ReminderPolicy{
+			{ReminderType: services.ReminderTypeExpire, DaysBefore: 7, NotifyChannels: []string{"app", "sms"}},
+		})
+		require.NoError(t, err)
+		adjusted := pendingPolicyReminder(t, db, milk.ID)
+		assert.Equal(t, reminder.ID, adjusted.ID)
+		assert.Equal(t, []string{"app", "sms"}, adjusted.NotifyChannels)
+
+		// 领取中的提醒被取消时同时清空租约，调度器不会再覆盖为已发送
+		lease := time.Now().Add(time.Minute)
+		require.NoError(t, db.Model(&models.Reminder{}).Where("id = ?", reminder.ID).Update("next_attempt_at", lease).Error)
+
+		_, err = policyService.ReplacePolicies(fixture.Ctx, fixture.FamilyID, []models.ReminderPolicy{
+			{ReminderType: services.ReminderTypeExpire, DaysBefore: 3},
+		})
+		require.NoError(t, err)
+
+		var cancelled models.Reminder
+		require.NoError(t, db.First(&cancelled, "id = ?", reminder.ID).Error)
+		assert.Equal(t, services.ReminderStatusCancelled, cancelled.Status)
+		assert.Nil(t, cancelled.NextAttemptAt)
+
+		replacement := pendingPolicyReminder(t, db, milk.ID)
+		assert.NotEqual(t, reminder.ID, replacement.ID)
+		assert.True(t, policyTrigger(t, db, milk.ID, 3).Equal(replacement.TriggerTime))
+	})
+
+	t.Run("丢弃物品后取消提醒", func(t *testing.T) {
+		bread := &models.Item{Name: "吐司", RoomID: &fixture.RoomID, ExpireDate: &expire}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, bread))
+		reminder := pendingPolicyReminder(t, db, bread.ID)
+
+		updated := reload(bread)
+		updated.Status = "discarded"
+		require.NoError(t, itemService.UpdateItem(fixture.Ctx, &updated))
+
+		reminders := policyReminders(t, db, bread.ID)
+		require.Len(t, reminders, 1)
+		assert.Equal(t, reminder.ID, reminders[0].ID)
+		assert.Equal(t, services.ReminderStatusCancelled, reminders[0].Status)
+	})
+
+	t.Run("容器移到其他家庭的房屋后按新家庭的策略重建提醒", func(t *testing.T) {
+		other := testutils.SeedFamily(t, db, "policy_other")
+		require.NoError(t, db.Omit(clause.Associations).Create(&models.FamilyMember{
+			FamilyID: other.FamilyID, UserID: fixture.UserID, Role: services.RoleMember, Status: 1,
+		}).Error)
+		_, err := policyService.ReplacePolicies(other.Ctx, other.FamilyID, []models.ReminderPolicy{
+			{ReminderType: services.ReminderTypeExpire, DaysBefore: 14},
+		})
+		require.NoError(t, err)
+
+		box := &models.Item{Name: "食品箱", RoomID: &fixture.RoomID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, box))
+		cereal := &models.Item{Name: "麦片", RoomID: &fixture.RoomID, ContainerID: &box.ID, ExpireDate: &expire}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, cereal))
+		original := pendingPolicyReminder(t, db, cereal.ID)
+
+		require.NoError(t, itemService.MoveItem(fixture.Ctx, box.ID, services.MoveTarget{RoomID: &other.RoomID}))
+
+		var cancelled models.Reminder
+		require.NoError(t, db.First(&cancelled, "id = ?", original.ID).Error)
+		assert.Equal(t, services.ReminderStatusCancelled, cancelled.Status)
+
+		moved := pendingPolicyReminder(t, db, cereal.ID)
+		assert.True(t, policyTrigger(t, db, cereal.ID, 14).Equal(moved.TriggerTime))
+	})
+}
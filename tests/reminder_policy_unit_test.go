@@ -0,0 +1,49 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+func TestPolicyReminder(t *testing.T) {
+	expire := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	purchase := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	warranty := 12
+	item := &models.Item{ID: "item-1", Name: "牛奶", Status: "active", ExpireDate: &expire, PurchaseDate: &purchase, WarrantyPeriod: &warranty}
+
+	t.Run("过期前若干天9点提醒", func(t *testing.T) {
+		policy := models.ReminderPolicy{ID: "policy-1", ReminderType: services.ReminderTypeExpire, DaysBefore: 7, NotifyChannels: []string{"app"}}
+
+		reminder := services.PolicyReminder(item, policy)
+		require.NotNil(t, reminder)
+		assert.Equal(t, time.Date(2026, 3, 3, 9, 0, 0, 0, time.UTC), reminder.TriggerTime)
+		assert.Equal(t, services.ReminderTypeExpire, reminder.ReminderType)
+		assert.Equal(t, services.ReminderStatusPending, reminder.Status)
+		assert.Equal(t, "policy-1", *reminder.PolicyID)
+		assert.Equal(t, []string{"app"}, reminder.NotifyChannels)
+		assert.Contains(t, reminder.Message, "2026-03-10")
+	})
+
+	t.Run("保修到期日按购买日期加保修月数计算", func(t *testing.T) {
+		policy := models.ReminderPolicy{ID: "policy-2", ReminderType: services.ReminderTypeWarranty, DaysBefore: 30}
+
+		reminder := services.PolicyReminder(item, policy)
+		require.NotNil(t, reminder)
+		assert.Equal(t, time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), reminder.TriggerTime)
+		assert.Contains(t, reminder.Message, "2026-01-31")
+	})
+
+	t.Run("缺少日期或物品已丢弃时不生成提醒", func(t *testing.T) {
+		policy := models.ReminderPolicy{ID: "policy-3", ReminderType: services.ReminderTypeWarranty, DaysBefore: 30}
+		assert.Nil(t, services.PolicyReminder(&models.Item{ID: "item-2", PurchaseDate: &purchase}, policy))
+
+		discarded := *item
+		discarded.Status = "discarded"
+		assert.Nil(t, services.PolicyReminder(&discarded, models.ReminderPolicy{ReminderType: services.ReminderTypeExpire}))
+	})
+}
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/internal/storage"
+	"nookverse/tests/testutils"
+)
+
+// countingNotifier 记录调用次数的通知渠道，fail 不为空时返回其结果
+type countingNotifier struct {
+	calls int
+	fail  func() error
+}
+
+func (n *countingNotifier) Notify(ctx context.Context, reminder *models.Reminder) error {
+	n.calls++
+	if n.fail != nil {
+		return n.fail()
+	}
+	return nil
+}
+
+// dueReminder 创建已到期的待发送提醒
+func dueReminder(t *testing.T, db *gorm.DB, itemID string, channels ...string) *models.Reminder {
+	t.Helper()
+
+	reminder := &models.Reminder{
+		ItemID:         itemID,
+		ReminderType:   services.ReminderTypeCustom,
+		TriggerTime:    time.Now().Add(-time.Minute),
+		Message:        "到期提醒",
+		Status:         services.ReminderStatusPending,
+		NotifyChannels: channels,
+	}
+	require.NoError(t, db.Omit(clause.Associations).Create(reminder).Error)
+	return reminder
+}
+
+// reloadReminder 重新读取提醒
+func reloadReminder(t *testing.T, db *gorm.DB, id string) models.Reminder {
+	t.Helper()
+
+	var reminder models.Reminder
+	require.NoError(t, db.First(&reminder, "id = ?", id).Error)
+	return reminder
+}
+
+// expireLease 使提醒的领取租约或重试等待到期
+func expireLease(t *testing.T, db *gorm.DB, id string) {
+	t.Helper()
+	require.NoError(t, db.Model(&models.Reminder{}).Where("id = ?", id).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+}
+
+func TestReminderSchedulerIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "scheduler_owner")
+	itemService := services.NewItemService(db, nil)
+
+	item := &models.Item{Name: "灭火器", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, item))
+
+	app, sms := &countingNotifier{}, &countingNotifier{}
+	registry := &services.NotifierRegistry{}
+	registry.Register(services.NotifyChannelApp, app)
+	registry.Register("sms", sms)
+	scheduler := services.NewReminderScheduler(db, registry)
+
+	t.Run("领取后租约期内不会重复领取，租约到期后重新发送", func(t *testing.T) {
+		reminder := dueReminder(t, db, item.ID, "app")
+
+		// 发送中途服务关闭，提醒保留租约且不计入失败次数
+		ctx, cancel := context.WithCancel(context.Background())
+		app.fail = func() error { cancel(); return context.Canceled }
+		_, err := scheduler.RunOnce(ctx)
+		assert.ErrorIs(t, err, context.Canceled)
+		app.fail = nil
+
+		claimed := reloadReminder(t, db, reminder.ID)
+		assert.Equal(t, services.ReminderStatusPending, claimed.Status)
+		assert.Zero(t, claimed.Attempts)
+		require.NotNil(t, claimed.NextAttemptAt)
+		assert.True(t, claimed.NextAttemptAt.After(time.Now()))
+
+		processed, err := scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, processed)
+
+		expireLease(t, db, reminder.ID)
+		processed, err = scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+
+		sent := reloadReminder(t, db, reminder.ID)
+		assert.Equal(t, services.ReminderStatusSent, sent.Status)
+		assert.NotNil(t, sent.SentAt)
+		assert.Nil(t, sent.NextAttemptAt)
+	})
+
+	t.Run("重试时跳过已发送成功的渠道", func(t *testing.T) {
+		app.calls, sms.calls = 0, 0
+		reminder := dueReminder(t, db, item.ID, "app", "sms")
+
+		sms.fail = func() error { return errors.New("gateway timeout") }
+		_, err := scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+
+		retrying := reloadReminder(t, db, reminder.ID)
+		assert.Equal(t, services.ReminderStatusPending, retrying.Status)
+		assert.Equal(t, 1, retrying.Attempts)
+		require.NotNil(t, retrying.LastError)
+		assert.Contains(t, *retrying.LastError, "gateway timeout")
+		require.NotNil(t, retrying.NextAttemptAt)
+		assert.WithinDuration(t, time.Now().Add(services.ReminderRetryDelay(1)), *retrying.NextAttemptAt, 5*time.Second)
+
+		sms.fail = nil
+		expireLease(t, db, reminder.ID)
+		_, err = scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, app.calls)
+		assert.Equal(t, 2, sms.calls)
+		assert.Equal(t, services.ReminderStatusSent, reloadReminder(t, db, reminder.ID).Status)
+
+		var deliveries int64
+		db.Model(&models.ReminderDelivery{}).Where("reminder_id = ?", reminder.ID).Count(&deliveries)
+		assert.EqualValues(t, 2, deliveries)
+	})
+
+	t.Run("重试次数用尽后标记为失败", func(t *testing.T) {
+		reminder := dueReminder(t, db, item.ID, "sms")
+		sms.fail = func() error { return errors.New("gateway down") }
+		defer func() { sms.fail = nil }()
+
+		// 最多尝试5次
+		for i := 0; i < 5; i++ {
+			expireLease(t, db, reminder.ID)
+			_, err := scheduler.RunOnce(context.Background())
+			require.NoError(t, err)
+		}
+
+		failed := reloadReminder(t, db, reminder.ID)
+		assert.Equal(t, services.ReminderStatusFailed, failed.Status)
+		assert.Equal(t, 5, failed.Attempts)
+		assert.Nil(t, failed.NextAttemptAt)
+
+		processed, err := scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, processed)
+	})
+
+	t.Run("发送期间被取消的提醒不会被标记为已发送", func(t *testing.T) {
+		reminder := dueReminder(t, db, item.ID, "app")
+
+		// 与策略同步取消提醒相同，只修改状态
+		app.fail = func() error {
+			return db.Model(&models.Reminder{}).Where("id = ?", reminder.ID).
+				Update("status", services.ReminderStatusCancelled).Error
+		}
+		defer func() { app.fail = nil }()
+
+		_, err := scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, services.ReminderStatusCancelled, reloadReminder(t, db, reminder.ID).Status)
+	})
+
+	t.Run("回收站中物品的提醒暂停发送，恢复后继续", func(t *testing.T) {
+		trashed := &models.Item{Name: "旧灭火器", RoomID: &fixture.RoomID}
+		require.NoError(t, itemService.CreateItem(fixture.Ctx, trashed))
+		reminder := dueReminder(t, db, trashed.ID, "app")
+		require.NoError(t, itemService.DeleteItem(fixture.Ctx, trashed.ID))
+
+		processed, err := scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, processed)
+		assert.Equal(t, services.ReminderStatusPending, reloadReminder(t, db, reminder.ID).Status)
+
+		_, err = services.NewTrashService(db, storage.NewLocal(t.TempDir()), 0).RestoreItem(fixture.Ctx, trashed.ID)
+		require.NoError(t, err)
+		processed, err = scheduler.RunOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, processed)
+		assert.Equal(t, services.ReminderStatusSent, reloadReminder(t, db, reminder.ID).Status)
+	})
+}
+
+func TestReminderSchedulerSkipLockedIntegration(t *testing.T) {
+	// 多个副本并发领取需要已提交的数据，不使用回滚的测试事务
+	db := testutils.OpenTestConn(t)
+
+	item := &models.Item{Name: "scheduler-skip-locked"}
+	require.NoError(t, db.Omit(clause.Associations).Create(item).Error)
+	t.Cleanup(func() { db.Unscoped().Delete(&models.Item{}, "id = ?", item.ID) })
+	reminder := dueReminder(t, db, item.ID, "app")
+
+	app := &countingNotifier{}
+	registry := &services.NotifierRegistry{}
+	registry.Register(services.NotifyChannelApp, app)
+	scheduler := services.NewReminderScheduler(db, registry)
+
+	// 另一副本正在处理该提醒时跳过而不等待
+	other := db.Begin()
+	require.NoError(t, other.Clauses(clause.Locking{Strength: "UPDATE"}).First(&models.Reminder{}, "id = ?", reminder.ID).Error)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := scheduler.RunOnce(ctx)
+	require.NoError(t, err)
+	skipped := reloadReminder(t, db, reminder.ID)
+	assert.Equal(t, services.ReminderStatusPending, skipped.Status)
+	assert.Nil(t, skipped.NextAttemptAt)
+	assert.Zero(t, app.calls)
+
+	require.NoError(t, other.Rollback().Error)
+	_, err = scheduler.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, services.ReminderStatusSent, reloadReminder(t, db, reminder.ID).Status)
+	assert.Equal(t, 1, app.calls)
+}
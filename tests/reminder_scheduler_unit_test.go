@@ -0,0 +1,22 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+)
+
+func TestReminderRetryDelay(t *testing.T) {
+	t.Run("指数退避", func(t *testing.T) {
+		assert.Equal(t, time.Minute, services.ReminderRetryDelay(1))
+		assert.Equal(t, 2*time.Minute, services.ReminderRetryDelay(2))
+		assert.Equal(t, 8*time.Minute, services.ReminderRetryDelay(4))
+	})
+
+	t.Run("不超过上限", func(t *testing.T) {
+		assert.Equal(t, time.Hour, services.ReminderRetryDelay(10))
+		assert.Equal(t, time.Hour, services.ReminderRetryDelay(100))
+	})
+}
@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+)
+
+func TestCanTransitionReminder(t *testing.T) {
+	t.Run("未结束的提醒可以重新安排、完成或取消", func(t *testing.T) {
+		for _, from := range []string{services.ReminderStatusPending, services.ReminderStatusSent, services.ReminderStatusFailed} {
+			assert.True(t, services.CanTransitionReminder(from, services.ReminderStatusPending), from)
+			assert.True(t, services.CanTransitionReminder(from, services.ReminderStatusCompleted), from)
+			assert.True(t, services.CanTransitionReminder(from, services.ReminderStatusCancelled), from)
+		}
+	})
+
+	t.Run("只有待发送的提醒会被发送", func(t *testing.T) {
+		assert.True(t, services.CanTransitionReminder(services.ReminderStatusPending, services.ReminderStatusSent))
+		assert.False(t, services.CanTransitionReminder(services.ReminderStatusSent, services.ReminderStatusSent))
+		assert.False(t, services.CanTransitionReminder(services.ReminderStatusFailed, services.ReminderStatusSent))
+	})
+
+	t.Run("已完成和已取消为终态", func(t *testing.T) {
+		for _, from := range []string{services.ReminderStatusCompleted, services.ReminderStatusCancelled} {
+			for _, to := range services.ReminderStatuses {
+				assert.False(t, services.CanTransitionReminder(from, to), from+" → "+to)
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"nookverse/internal/services"
+	"nookverse/tests/testutils"
+)
+
+func TestUserIDContext(t *testing.T) {
+	t.Run("写入并读取用户ID", func(t *testing.T) {
+		ctx := services.WithUserID(context.Background(), "user-1")
+		userID, ok := services.UserIDFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, "user-1", userID)
+	})
+
+	t.Run("未写入用户ID", func(t *testing.T) {
+		_, ok := services.UserIDFromContext(context.Background())
+		assert.False(t, ok)
+	})
+
+	t.Run("空用户ID视为未登录", func(t *testing.T) {
+		_, ok := services.UserIDFromContext(services.WithUserID(context.Background(), ""))
+		assert.False(t, ok)
+	})
+}
+
+func TestNotFoundErrors(t *testing.T) {
+	t.Run("资源不存在错误可统一识别", func(t *testing.T) {
+		assert.ErrorIs(t, services.ErrHouseNotFound, services.ErrNotFound)
+		assert.ErrorIs(t, services.ErrRoomNotFound, services.ErrNotFound)
+		assert.ErrorIs(t, services.ErrItemNotFound, services.ErrNotFound)
+		assert.ErrorIs(t, services.ErrFamilyNotFound, services.ErrNotFound)
+	})
+
+	t.Run("保留原有错误信息", func(t *testing.T) {
+		assert.Equal(t, "房屋不存在", services.ErrHouseNotFound.Error())
+		assert.NotErrorIs(t, services.ErrHouseNotFound, services.ErrRoomNotFound)
+	})
+}
+
+func TestListFilterUserNarrowsScope(t *testing.T) {
+	other := "user-other"
+	ctx := services.WithUserID(context.Background(), "user-me")
+
+	t.Run("物品列表始终限定为当前用户可见的物品", func(t *testing.T) {
+		db, recorder := testutils.DryRunDB()
+		_, _, err := services.NewItemService(db, nil).ListItems(ctx, services.ItemFilters{UserID: &other})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, recorder.Find(`FROM "items"`, "fm.user_id = 'user-me'", "fm.user_id = 'user-other'"))
+	})
+
+	t.Run("房屋列表始终限定为当前用户可访问的房屋", func(t *testing.T) {
+		db, recorder := testutils.DryRunDB()
+		_, _, err := services.NewHouseService(db).ListHouses(ctx, services.HouseFilters{UserID: &other})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, recorder.Find(`FROM "houses"`, "fm.user_id = 'user-me'", "fm.user_id = 'user-other'"))
+	})
+}
+
+func TestTrashedRowsExcluded(t *testing.T) {
+	ctx := services.WithUserID(context.Background(), "user-me")
+
+	t.Run("物品统计不含回收站中的物品及其房间、房屋", func(t *testing.T) {
+		db, recorder := testutils.DryRunDB()
+		_, err := services.NewItemService(db, nil).GetItemStatistics(ctx, "user-me", services.ItemFilters{})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, recorder.Find(`"items"."deleted_at" IS NULL`, "i.deleted_at IS NULL", "r.deleted_at IS NULL", "h.deleted_at IS NULL"))
+	})
+
+	t.Run("房屋统计不含回收站中的房屋和房间", func(t *testing.T) {
+		db, recorder := testutils.DryRunDB()
+		_, err := services.NewHouseService(db).GetHouseStatistics(ctx)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, recorder.Find(`FROM "rooms"`, `"rooms"."deleted_at" IS NULL`, "h.deleted_at IS NULL"))
+	})
+
+	t.Run("上级位置在回收站中的错误可统一识别", func(t *testing.T) {
+		assert.ErrorIs(t, services.ErrItemRoomInTrash, services.ErrParentInTrash)
+		assert.ErrorIs(t, services.ErrItemContainerInTrash, services.ErrParentInTrash)
+		assert.ErrorIs(t, services.ErrRoomHouseInTrash, services.ErrParentInTrash)
+		assert.NotErrorIs(t, services.ErrItemRoomInTrash, services.ErrNotFound)
+	})
+}
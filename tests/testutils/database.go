@@ -0,0 +1,104 @@
+package testutils
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"nookverse/internal/database"
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+)
+
+// TestDSNEnv 集成测试数据库连接串的环境变量，未设置时跳过需要数据库的测试
+const TestDSNEnv = "NOOKVERSE_TEST_DSN"
+
+var migrateOnce sync.Once
+
+// OpenTestDB 连接集成测试数据库并返回一个事务，测试结束时回滚，不留下测试数据
+func OpenTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	tx := OpenTestConn(t).Begin()
+	require.NoError(t, tx.Error)
+	t.Cleanup(func() { tx.Rollback() })
+
+	return tx
+}
+
+// OpenTestConn 连接集成测试数据库，不开启事务。用于需要多个连接并发操作已提交数据的测试，
+// 调用方自行清理写入的数据
+func OpenTestConn(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(TestDSNEnv)
+	if dsn == "" {
+		t.Skip("未设置 " + TestDSNEnv + "，跳过需要数据库的集成测试")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err, "数据库连接失败")
+
+	var migrateErr error
+	migrateOnce.Do(func() { migrateErr = database.AutoMigrate(db) })
+	require.NoError(t, migrateErr, "数据库迁移失败")
+
+	return db
+}
+
+// Fixture 集成测试的基础数据：一个家庭的owner及其房屋和房间
+type Fixture struct {
+	Ctx      context.Context // 以owner身份发起请求的上下文
+	UserID   string
+	FamilyID string
+	HouseID  string
+	RoomID   string
+}
+
+// SeedFamily 创建用户、家庭、房屋和房间，用户为家庭owner
+func SeedFamily(t *testing.T, db *gorm.DB, name string) Fixture {
+	t.Helper()
+
+	user := &models.User{Username: name, Email: name + "@example.com", PasswordHash: "-"}
+	require.NoError(t, db.Omit(clause.Associations).Create(user).Error)
+
+	family := &models.Family{Name: name, OwnerID: user.ID}
+	require.NoError(t, db.Omit(clause.Associations).Create(family).Error)
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.FamilyMember{
+		FamilyID: family.ID, UserID: user.ID, Role: services.RoleOwner, Status: 1,
+	}).Error)
+
+	house := &models.House{Name: name + "的房屋"}
+	require.NoError(t, db.Omit(clause.Associations).Create(house).Error)
+	require.NoError(t, db.Exec(`INSERT INTO family_houses (family_id, house_id) VALUES (?, ?)`, family.ID, house.ID).Error)
+
+	room := &models.Room{HouseID: house.ID, Name: "储藏室", RoomType: "storage"}
+	require.NoError(t, db.Omit(clause.Associations).Create(room).Error)
+
+	return Fixture{
+		Ctx:      services.WithUserID(context.Background(), user.ID),
+		UserID:   user.ID,
+		FamilyID: family.ID,
+		HouseID:  house.ID,
+		RoomID:   room.ID,
+	}
+}
+
+// AddMember 创建用户并以指定角色加入家庭，返回以该用户身份发起请求的上下文和用户ID
+func AddMember(t *testing.T, db *gorm.DB, familyID, name, role string) (context.Context, string) {
+	t.Helper()
+
+	user := &models.User{Username: name, Email: name + "@example.com", PasswordHash: "-"}
+	require.NoError(t, db.Omit(clause.Associations).Create(user).Error)
+	require.NoError(t, db.Omit(clause.Associations).Create(&models.FamilyMember{
+		FamilyID: familyID, UserID: user.ID, Role: role, Status: 1,
+	}).Error)
+
+	return services.WithUserID(context.Background(), user.ID), user.ID
+}
@@ -0,0 +1,65 @@
+package testutils
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// SQLRecorder 记录 DryRun 模式下生成的SQL，用于在没有数据库时校验查询条件
+type SQLRecorder struct {
+	logger.Interface
+	mu         sync.Mutex
+	statements []string
+}
+
+// Trace 记录一条已展开参数的SQL
+func (r *SQLRecorder) Trace(_ context.Context, _ time.Time, fc func() (string, int64), _ error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, sql)
+}
+
+// Statements 已记录的全部SQL
+func (r *SQLRecorder) Statements() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.statements...)
+}
+
+// Find 返回第一条包含全部片段的SQL，没有时返回空字符串
+func (r *SQLRecorder) Find(fragments ...string) string {
+	for _, statement := range r.Statements() {
+		matched := true
+		for _, fragment := range fragments {
+			if !strings.Contains(statement, fragment) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return statement
+		}
+	}
+	return ""
+}
+
+// DryRunDB 创建不连接数据库的 PostgreSQL 方言 DryRun 实例，生成的SQL记录到返回的 SQLRecorder
+func DryRunDB() (*gorm.DB, *SQLRecorder) {
+	recorder := &SQLRecorder{Interface: logger.Discard}
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1 port=1"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               recorder,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return db, recorder
+}
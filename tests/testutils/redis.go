@@ -0,0 +1,29 @@
+package testutils
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisEnv 测试 Redis 地址的环境变量，未设置时跳过需要 Redis 的测试
+const TestRedisEnv = "NOOKVERSE_TEST_REDIS"
+
+// OpenTestRedis 连接测试 Redis，测试结束时关闭连接
+func OpenTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+
+	addr := os.Getenv(TestRedisEnv)
+	if addr == "" {
+		t.Skip("未设置 " + TestRedisEnv + "，跳过需要 Redis 的测试")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	require.NoError(t, client.Ping(context.Background()).Err(), "Redis 连接失败")
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
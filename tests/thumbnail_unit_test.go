@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nookverse/internal/services"
+)
+
+func TestGenerateThumbnail(t *testing.T) {
+	t.Run("等比缩放并输出JPEG", func(t *testing.T) {
+		src := image.NewRGBA(image.Rect(0, 0, 1000, 500))
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, src))
+
+		thumbnail, err := services.GenerateThumbnail(&buf, services.ThumbnailSize)
+		require.NoError(t, err)
+
+		img, format, err := image.Decode(bytes.NewReader(thumbnail))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, 320, img.Bounds().Dx())
+		assert.Equal(t, 160, img.Bounds().Dy())
+	})
+
+	t.Run("小图不放大，透明区域填充白色", func(t *testing.T) {
+		src := image.NewNRGBA(image.Rect(0, 0, 40, 30))
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, src))
+
+		thumbnail, err := services.GenerateThumbnail(&buf, services.ThumbnailSize)
+		require.NoError(t, err)
+
+		img, _, err := image.Decode(bytes.NewReader(thumbnail))
+		require.NoError(t, err)
+		assert.Equal(t, 40, img.Bounds().Dx())
+		r, g, b, _ := img.At(20, 15).RGBA()
+		assert.Greater(t, r, uint32(0xf000))
+		assert.Greater(t, g, uint32(0xf000))
+		assert.Greater(t, b, uint32(0xf000))
+	})
+
+	t.Run("非图片内容", func(t *testing.T) {
+		_, err := services.GenerateThumbnail(bytes.NewReader([]byte("not an image")), services.ThumbnailSize)
+		assert.ErrorIs(t, err, services.ErrNotImage)
+	})
+}
+
+func TestStripJPEGGPS(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	src.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var encoded bytes.Buffer
+	require.NoError(t, jpeg.Encode(&encoded, src, nil))
+
+	// 构造仅包含GPS纬度的EXIF：IFD0(8) → GPS IFD(26) → 纬度数据(44)
+	tiff := make([]byte, 68)
+	le := binary.LittleEndian
+	copy(tiff, "II*\x00")
+	le.PutUint32(tiff[4:], 8)
+	le.PutUint16(tiff[8:], 1)
+	le.PutUint16(tiff[10:], 0x8825)
+	le.PutUint16(tiff[12:], 4)
+	le.PutUint32(tiff[14:], 1)
+	le.PutUint32(tiff[18:], 26)
+	le.PutUint16(tiff[26:], 1)
+	le.PutUint16(tiff[28:], 2)
+	le.PutUint16(tiff[30:], 5)
+	le.PutUint32(tiff[32:], 3)
+	le.PutUint32(tiff[36:], 44)
+	for i := 44; i < 68; i++ {
+		tiff[i] = 0x7f
+	}
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	original := encoded.Bytes()
+	data := append(append(append([]byte{}, original[:2]...), app1...), original[2:]...)
+	size := len(data)
+
+	stripped := services.StripJPEGGPS(data)
+
+	t.Run("清除GPS数据且长度不变", func(t *testing.T) {
+		assert.Equal(t, size, len(stripped))
+		gps := stripped[2+4+6+26:]
+		assert.Equal(t, uint16(0), le.Uint16(gps))
+		assert.False(t, bytes.Contains(stripped, bytes.Repeat([]byte{0x7f}, 24)))
+	})
+
+	t.Run("处理后仍是有效图片", func(t *testing.T) {
+		_, err := jpeg.Decode(bytes.NewReader(stripped))
+		assert.NoError(t, err)
+	})
+
+	t.Run("非JPEG原样返回", func(t *testing.T) {
+		input := []byte("plain text")
+		assert.Equal(t, input, services.StripJPEGGPS(input))
+	})
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"nookverse/internal/models"
+	"nookverse/internal/services"
+	"nookverse/internal/storage"
+	"nookverse/tests/testutils"
+)
+
+func TestTrashRestoreIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "trash_owner")
+	viewerCtx, _ := testutils.AddMember(t, db, fixture.FamilyID, "trash_viewer", services.RoleViewer)
+
+	itemService := services.NewItemService(db, nil)
+	houseService := services.NewHouseService(db)
+	trashService := services.NewTrashService(db, storage.NewLocal(t.TempDir()), 0)
+
+	box := &models.Item{Name: "收纳箱", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, box))
+	charger := &models.Item{Name: "充电器", RoomID: &fixture.RoomID, ContainerID: &box.ID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, charger))
+
+	// 先删除箱内物品，再删除箱子
+	require.NoError(t, itemService.DeleteItem(fixture.Ctx, charger.ID))
+	require.NoError(t, itemService.DeleteItem(fixture.Ctx, box.ID))
+
+	t.Run("回收站中的物品不出现在列表和统计中", func(t *testing.T) {
+		items, total, err := itemService.ListItems(fixture.Ctx, services.ItemFilters{})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+		assert.Empty(t, items)
+
+		stats, err := itemService.GetItemStatistics(fixture.Ctx, fixture.UserID, services.ItemFilters{})
+		require.NoError(t, err)
+		assert.Zero(t, stats.TotalItems)
+
+		_, err = itemService.GetItemByID(fixture.Ctx, box.ID)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+
+	t.Run("回收站按删除时间倒序列出", func(t *testing.T) {
+		entries, total, err := trashService.ListTrash(fixture.Ctx, "", 1, 20)
+		require.NoError(t, err)
+		require.EqualValues(t, 2, total)
+		assert.Equal(t, box.ID, entries[0].ID)
+		assert.Equal(t, services.EntityItem, entries[0].EntityType)
+		assert.Equal(t, fixture.HouseID, entries[0].HouseID)
+		assert.WithinDuration(t, entries[0].DeletedAt.Add(30*24*time.Hour), entries[0].PurgeAt, time.Second)
+	})
+
+	t.Run("容器在回收站中时不能恢复其内物品", func(t *testing.T) {
+		_, err := trashService.RestoreItem(fixture.Ctx, charger.ID)
+		assert.ErrorIs(t, err, services.ErrParentInTrash)
+	})
+
+	t.Run("没有删除权限的成员不能恢复", func(t *testing.T) {
+		_, err := trashService.RestoreItem(viewerCtx, box.ID)
+		assert.ErrorIs(t, err, services.ErrForbidden)
+	})
+
+	t.Run("先恢复容器再恢复物品", func(t *testing.T) {
+		_, err := trashService.RestoreItem(fixture.Ctx, box.ID)
+		require.NoError(t, err)
+		restored, err := trashService.RestoreItem(fixture.Ctx, charger.ID)
+		require.NoError(t, err)
+		assert.Equal(t, fixture.RoomID, *restored.RoomID)
+
+		// 恢复后重新加入容器层级
+		ancestors, err := itemService.GetItemHierarchy(fixture.Ctx, charger.ID)
+		require.NoError(t, err)
+		require.Len(t, ancestors, 1)
+		assert.Equal(t, box.ID, ancestors[0].ID)
+
+		_, total, err := trashService.ListTrash(fixture.Ctx, fixture.FamilyID, 1, 20)
+		require.NoError(t, err)
+		assert.Zero(t, total)
+	})
+
+	t.Run("房屋在回收站中时不能恢复房间", func(t *testing.T) {
+		house := &models.House{Name: "老房子"}
+		require.NoError(t, houseService.CreateHouse(fixture.Ctx, house, fixture.FamilyID))
+		attic := &models.Room{HouseID: house.ID, Name: "阁楼", RoomType: "storage"}
+		require.NoError(t, houseService.CreateRoom(fixture.Ctx, attic))
+
+		require.NoError(t, houseService.DeleteRoom(fixture.Ctx, attic.ID))
+		require.NoError(t, houseService.DeleteHouse(fixture.Ctx, house.ID))
+
+		_, err := trashService.RestoreRoom(fixture.Ctx, attic.ID)
+		assert.ErrorIs(t, err, services.ErrParentInTrash)
+
+		_, err = trashService.RestoreHouse(fixture.Ctx, house.ID)
+		require.NoError(t, err)
+		_, err = trashService.RestoreRoom(fixture.Ctx, attic.ID)
+		require.NoError(t, err)
+	})
+}
+
+func TestTrashPurgeIntegration(t *testing.T) {
+	db := testutils.OpenTestDB(t)
+	fixture := testutils.SeedFamily(t, db, "trash_purge")
+
+	itemService := services.NewItemService(db, nil)
+	houseService := services.NewHouseService(db)
+
+	old := &models.Item{Name: "旧报纸", RoomID: &fixture.RoomID}
+	require.NoError(t, itemService.CreateItem(fixture.Ctx, old))
+
+	// 物品的媒体、提醒及其发送记录、完成记录和通知随物品一并删除，操作日志保留
+	reminder := &models.Reminder{ItemID: old.ID, ReminderType: "custom", TriggerTime: time.Now(), Message: "回收"}
+	require.NoError(t, db.Create(reminder).Error)
+	require.NoError(t, db.Create(&models.ReminderDelivery{ReminderID: reminder.ID, Channel: "app", SentAt: time.Now()}).Error)
+	require.NoError(t, db.Create(&models.ReminderCompletion{
+		ReminderID: &reminder.ID, ItemID: old.ID, UserID: fixture.UserID, ReminderType: "custom",
+		OccurrenceAt: time.Now(), CompletedAt: time.Now(),
+	}).Error)
+	require.NoError(t, db.Create(&models.Notification{
+		UserID: fixture.UserID, ReminderID: &reminder.ID, ItemID: &old.ID, Title: "回收", Message: "回收",
+	}).Error)
+	require.NoError(t, db.Create(&models.MediaFile{ItemID: old.ID, FileURL: "/media/a.jpg", FileType: "image"}).Error)
+
+	require.NoError(t, itemService.DeleteItem(fixture.Ctx, old.ID))
+	require.NoError(t, houseService.DeleteRoom(fixture.Ctx, fixture.RoomID))
+	require.NoError(t, houseService.DeleteHouse(fixture.Ctx, fixture.HouseID))
+
+	// 保留期内不清理
+	result, err := services.NewTrashService(db, storage.NewLocal(t.TempDir()), time.Hour).PurgeExpired(fixture.Ctx)
+	require.NoError(t, err)
+	assert.Zero(t, result.Items)
+
+	// 超过保留期后物品、已经清空的房间和房屋被彻底删除
+	result, err = services.NewTrashService(db, storage.NewLocal(t.TempDir()), time.Nanosecond).PurgeExpired(fixture.Ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, result.Items)
+	assert.EqualValues(t, 1, result.Rooms)
+	assert.EqualValues(t, 1, result.Houses)
+
+	count := func(model any, query string, args ...any) int64 {
+		var n int64
+		require.NoError(t, db.Unscoped().Model(model).Where(query, args...).Count(&n).Error)
+		return n
+	}
+	assert.Zero(t, count(&models.Item{}, "id = ?", old.ID))
+	assert.Zero(t, count(&models.MediaFile{}, "item_id = ?", old.ID))
+	assert.Zero(t, count(&models.Reminder{}, "item_id = ?", old.ID))
+	assert.Zero(t, count(&models.ReminderDelivery{}, "reminder_id = ?", reminder.ID))
+	assert.Zero(t, count(&models.ReminderCompletion{}, "item_id = ?", old.ID))
+	assert.Zero(t, count(&models.Notification{}, "item_id = ?", old.ID))
+	assert.Zero(t, count(&models.ItemVersion{}, "item_id = ?", old.ID))
+	assert.Zero(t, count(&models.House{}, "id = ?", fixture.HouseID))
+	assert.NotZero(t, count(&models.OperationLog{}, "entity_id = ? AND item_id IS NULL", old.ID))
+}